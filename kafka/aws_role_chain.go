@@ -0,0 +1,140 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/credentials/endpointcreds"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+const awsSessionName = "terraform-kafka-provider"
+
+// AWSAssumeRole is a role of SASLAWSRoleArnChain
+type AWSAssumeRole struct {
+	RoleArn     string
+	ExternalId  string
+	SessionName string
+}
+
+// awsRoleChainCredentials assumes each role of SASLAWSRoleArnChain in turn,
+// each with the credentials of the role before it, and returns the
+// credentials of the last one. The first role is assumed with
+// sasl_aws_role_arn if set, or else with the credentials the provider is
+// configured with.
+func (c *Config) awsRoleChainCredentials(ctx context.Context, region string) (aws.CredentialsProvider, error) {
+	creds, err := c.awsSourceCredentials(ctx, region)
+	if err != nil {
+		return nil, err
+	}
+
+	chain := c.SASLAWSRoleArnChain
+	if c.SASLAWSRoleArn != "" && c.SASLAWSWebIdentityTokenFile == "" {
+		chain = append([]AWSAssumeRole{{RoleArn: c.SASLAWSRoleArn, ExternalId: c.SASLAWSExternalId}}, chain...)
+	}
+
+	for i, role := range chain {
+		sessionName := role.SessionName
+		if sessionName == "" {
+			sessionName = awsSessionName
+		}
+
+		log.Printf("[INFO] Assuming role '%s' with session name '%s' (%d of %d)", role.RoleArn, sessionName, i+1, len(chain))
+		assumed, err := stscreds.NewAssumeRoleProvider(
+			sts.New(sts.Options{Region: region, Credentials: creds}),
+			role.RoleArn,
+			func(o *stscreds.AssumeRoleOptions) {
+				o.RoleSessionName = sessionName
+				if role.ExternalId != "" {
+					o.ExternalID = aws.String(role.ExternalId)
+				}
+			},
+		).Retrieve(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error assuming role %s (%d of %d): %w", role.RoleArn, i+1, len(chain), err)
+		}
+		log.Printf("[DEBUG] Assumed role '%s', its credentials expire at %s", role.RoleArn, assumed.Expires)
+
+		creds = credentials.StaticCredentialsProvider{Value: assumed}
+	}
+
+	return creds, nil
+}
+
+// awsSourceCredentials returns the credentials the first role of a chain is
+// assumed with
+func (c *Config) awsSourceCredentials(ctx context.Context, region string) (aws.CredentialsProvider, error) {
+	if c.SASLAWSContainerAuthorizationTokenFile != "" && c.SASLAWSContainerCredentialsFullUri != "" {
+		log.Printf("[INFO] Assuming the role chain using container credentials")
+		return c.awsContainerCredentials()
+	}
+	if c.SASLAWSWebIdentityTokenFile != "" && c.SASLAWSRoleArn != "" {
+		log.Printf("[INFO] Assuming the role chain using web identity token file '%s' with a role '%s'", c.SASLAWSWebIdentityTokenFile, c.SASLAWSRoleArn)
+		return c.awsWebIdentityCredentials(region), nil
+	}
+	if c.SASLAWSAccessKey != "" && c.SASLAWSSecretKey != "" && c.SASLAWSProfile == "" {
+		log.Printf("[INFO] Assuming the role chain using static credentials")
+		return credentials.NewStaticCredentialsProvider(c.SASLAWSAccessKey, c.SASLAWSSecretKey, c.SASLAWSToken), nil
+	}
+
+	opts := []func(*awsconfig.LoadOptions) error{awsconfig.WithRegion(region)}
+	if c.SASLAWSProfile != "" {
+		log.Printf("[INFO] Assuming the role chain using profile '%s'", c.SASLAWSProfile)
+		opts = append(opts, awsconfig.WithSharedConfigProfile(c.SASLAWSProfile))
+		if c.SASLAWSSharedConfigFiles != nil && len(*c.SASLAWSSharedConfigFiles) > 0 {
+			opts = append(opts, awsconfig.WithSharedConfigFiles(*c.SASLAWSSharedConfigFiles))
+		}
+	} else {
+		log.Printf("[INFO] Assuming the role chain using the default credentials")
+	}
+	cfg, err := awsconfig.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load AWS config: %w", err)
+	}
+	return cfg.Credentials, nil
+}
+
+func (c *Config) awsContainerCredentials() (aws.CredentialsProvider, error) {
+	containerAuthorizationToken, err := os.ReadFile(c.SASLAWSContainerAuthorizationTokenFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read authorization token file: %w", err)
+	}
+	return endpointcreds.New(c.SASLAWSContainerCredentialsFullUri, func(o *endpointcreds.Options) {
+		o.AuthorizationToken = string(containerAuthorizationToken)
+	}), nil
+}
+
+// awsWebIdentityCredentials assumes sasl_aws_role_arn with a web identity
+// token, e.g. IRSA on EKS, where the role is assumed with the pod's service
+// account token rather than with credentials of its own
+func (c *Config) awsWebIdentityCredentials(region string) aws.CredentialsProvider {
+	return stscreds.NewWebIdentityRoleProvider(
+		sts.New(sts.Options{Region: region}),
+		c.SASLAWSRoleArn,
+		stscreds.IdentityTokenFile(c.SASLAWSWebIdentityTokenFile),
+		func(o *stscreds.WebIdentityRoleOptions) {
+			o.RoleSessionName = awsSessionName
+		},
+	)
+}
+
+// maskedRoleChain returns the role chain with its external IDs masked
+func maskedRoleChain(chain []AWSAssumeRole) []AWSAssumeRole {
+	if chain == nil {
+		return nil
+	}
+	masked := make([]AWSAssumeRole, len(chain))
+	for i, role := range chain {
+		masked[i] = role
+		if role.ExternalId != "" {
+			masked[i].ExternalId = "*****"
+		}
+	}
+	return masked
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"math/rand"
+	"strconv"
 	"sync"
 	"time"
 
@@ -43,8 +44,48 @@ type aclCreationQueue struct {
 	waitChans []chan error
 }
 
+type scramCredentialQueue struct {
+	alterations []scramCredentialAlteration
+	after       time.Duration
+	timer       *time.Timer
+	mutex       sync.Mutex
+	waitChans   []chan error
+}
+
+type topicConfigCache struct {
+	configs map[string]map[string]*string
+	mutex   sync.RWMutex
+}
+
+// reassignmentThrottles counts the throttled reassignments moving replicas
+// on or off each broker, so that a broker's throttle rates are only restored
+// once none of them is still running
+type reassignmentThrottles struct {
+	brokers map[int32]*brokerThrottle
+	mutex   sync.Mutex
+}
+
+// brokerThrottle is the number of reassignments throttling a broker, and the
+// throttle rates it had before the first of them
+type brokerThrottle struct {
+	count int
+	rates map[string]string
+}
+
+type topicConfigQueue struct {
+	topics    []string
+	after     time.Duration
+	timer     *time.Timer
+	mutex     sync.Mutex
+	waitChans []chan error
+}
+
+// describeConfigsBatchSize limits how many topics are described in a single
+// DescribeConfigs request
+const describeConfigsBatchSize = 100
+
 type Client struct {
-	client        sarama.Client
+	client        *saramaClient
 	kafkaConfig   *sarama.Config
 	config        *Config
 	supportedAPIs map[int]int
@@ -53,14 +94,37 @@ type Client struct {
 	aclCache
 	aclDeletionQueue
 	aclCreationQueue
+	topicConfigCache
+	topicConfigQueue
+	scramCredentialQueue
+	reassignmentThrottles
+	rebootstrapMutex sync.Mutex
+	clientMutex      sync.RWMutex
+}
+
+// saramaClient is a sarama client counting the operations still using it,
+// so that a re-bootstrap replacing it only closes it once they are done
+type saramaClient struct {
+	sarama.Client
+	users sync.WaitGroup
+}
+
+func (sc *saramaClient) closeWhenReleased() {
+	sc.users.Wait()
+	if err := sc.Close(); err != nil {
+		log.Printf("[WARN] Error closing previous kafka client %s", err)
+	}
 }
 
+// NewClient connects to the cluster with a single metadata client. The
+// provider only administers the cluster, so no producers or consumers are
+// created; the cluster admins of each call share the client's broker
+// connections.
 func NewClient(config *Config) (*Client, error) {
 	if config == nil {
 		return nil, errors.New("cannot create client without kafka config")
 	}
 
-	log.Printf("[TRACE] configuring bootstrap_servers %v", config.copyWithMaskedSensitiveValues())
 	if config.BootstrapServers == nil {
 		return nil, fmt.Errorf("no bootstrap_servers provided")
 	}
@@ -70,7 +134,7 @@ func NewClient(config *Config) (*Client, error) {
 		return nil, fmt.Errorf("no bootstrap_servers provided")
 	}
 
-	log.Printf("[INFO] configuring kafka client with %v", config.copyWithMaskedSensitiveValues())
+	log.Printf("[DEBUG] configuring kafka client with %v", config.copyWithMaskedSensitiveValues())
 
 	kc, err := config.newKafkaConfig()
 	if err != nil {
@@ -85,7 +149,7 @@ func NewClient(config *Config) (*Client, error) {
 	}
 
 	client := &Client{
-		client:      c,
+		client:      &saramaClient{Client: c},
 		config:      config,
 		kafkaConfig: kc,
 		aclDeletionQueue: aclDeletionQueue{
@@ -94,6 +158,18 @@ func NewClient(config *Config) (*Client, error) {
 		aclCreationQueue: aclCreationQueue{
 			after: time.Millisecond * 500,
 		},
+		topicConfigCache: topicConfigCache{
+			configs: map[string]map[string]*string{},
+		},
+		topicConfigQueue: topicConfigQueue{
+			after: time.Millisecond * 500,
+		},
+		scramCredentialQueue: scramCredentialQueue{
+			after: time.Millisecond * 500,
+		},
+		reassignmentThrottles: reassignmentThrottles{
+			brokers: map[int32]*brokerThrottle{},
+		},
 	}
 
 	err = client.populateAPIVersions()
@@ -101,20 +177,141 @@ func NewClient(config *Config) (*Client, error) {
 		return client, err
 	}
 
+	if config.KafkaVersion == kafkaVersionAuto {
+		client.useDetectedKafkaVersion()
+	}
+
 	err = client.extractTopics()
 
 	return client, err
 }
 
+// SaramaClient returns the current sarama client, which a re-bootstrap may
+// replace and close at any time
 func (c *Client) SaramaClient() sarama.Client {
+	c.clientMutex.RLock()
+	defer c.clientMutex.RUnlock()
 	return c.client
 }
 
+// acquireClient returns the current sarama client along with the func
+// releasing it. A re-bootstrap only closes the client it replaced once every
+// caller that acquired it released it.
+func (c *Client) acquireClient() (*saramaClient, func()) {
+	c.clientMutex.RLock()
+	defer c.clientMutex.RUnlock()
+	sc := c.client
+	sc.users.Add(1)
+	return sc, sc.users.Done
+}
+
+// controller returns the cluster controller along with the func releasing
+// the client it belongs to, which must be called once done with the broker.
+// If none of the known brokers can be reached, the client is re-bootstrapped
+// from bootstrap_servers once before giving up, so that brokers whose
+// addresses changed are picked up.
+func (c *Client) controller() (*sarama.Broker, func(), error) {
+	sc, release := c.acquireClient()
+	broker, err := sc.Controller()
+	if errors.Is(err, sarama.ErrNoTopicsToUpdateMetadata) {
+		// with metadata_full disabled the controller is only known once a
+		// topic was fetched, so fetch everything this one time
+		log.Printf("[DEBUG] No topic metadata yet, refreshing all metadata to find the controller")
+		if err := sc.RefreshMetadata(); err != nil {
+			release()
+			return nil, nil, err
+		}
+		broker, err = sc.Controller()
+	}
+	if err == nil {
+		return broker, release, nil
+	}
+	release()
+	if !errors.Is(err, sarama.ErrOutOfBrokers) {
+		return nil, nil, err
+	}
+
+	log.Printf("[WARN] No brokers reachable, re-bootstrapping kafka client")
+	if rerr := c.rebootstrap(sc); rerr != nil {
+		return nil, nil, fmt.Errorf("%w (re-bootstrap failed: %s)", err, rerr)
+	}
+
+	sc, release = c.acquireClient()
+	broker, err = sc.Controller()
+	if err != nil {
+		release()
+		return nil, nil, err
+	}
+	return broker, release, nil
+}
+
+// RefreshController discovers the controller again from the metadata of any
+// broker. The controller is cached, so once a request fails with
+// NOT_CONTROLLER, e.g. after an election, the requests that follow only go
+// to the new controller once it is refreshed.
+func (c *Client) RefreshController() (*sarama.Broker, error) {
+	sc, release := c.acquireClient()
+	defer release()
+	broker, err := sc.RefreshController()
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[INFO] The controller is broker %d (%s)", broker.ID(), broker.Addr())
+	return broker, nil
+}
+
+// Rebootstrap replaces the underlying sarama client with a new one connected
+// to bootstrap_servers, resolving their addresses again
+func (c *Client) Rebootstrap() error {
+	return c.rebootstrap(nil)
+}
+
+// rebootstrap replaces the sarama client unless failed, the client an
+// operation failed on, was already replaced by another goroutine meanwhile.
+// The replaced client is closed in the background once it was released.
+func (c *Client) rebootstrap(failed *saramaClient) error {
+	c.rebootstrapMutex.Lock()
+	defer c.rebootstrapMutex.Unlock()
+
+	if failed != nil && c.SaramaClient() != sarama.Client(failed) {
+		log.Printf("[DEBUG] Kafka client was already re-bootstrapped")
+		return nil
+	}
+
+	client, err := sarama.NewClient(*c.config.BootstrapServers, c.kafkaConfig)
+	if err != nil {
+		log.Printf("[ERROR] Error re-bootstrapping kafka client %s", err)
+		return err
+	}
+
+	c.clientMutex.Lock()
+	old := c.client
+	c.client = &saramaClient{Client: client}
+	c.clientMutex.Unlock()
+
+	if old != nil {
+		go old.closeWhenReleased()
+	}
+
+	return c.extractTopics()
+}
+
 func (c *Client) populateAPIVersions() error {
+	sc, release := c.acquireClient()
+	defer release()
 	ch := make(chan []sarama.ApiVersionsResponseKey)
 	errCh := make(chan error)
 
-	brokers := c.client.Brokers()
+	brokers := sc.Brokers()
+	bootstrapOnly := false
+	if len(brokers) == 0 && !c.kafkaConfig.Metadata.Full {
+		// no metadata is fetched on connect, so only a bootstrap server is
+		// known to ask
+		if broker := sc.LeastLoadedBroker(); broker != nil {
+			brokers = []*sarama.Broker{broker}
+			bootstrapOnly = true
+		}
+	}
 	kafkaConfig := c.kafkaConfig
 	for _, broker := range brokers {
 		go apiVersionsFromBroker(broker, kafkaConfig, ch, errCh)
@@ -132,6 +329,11 @@ func (c *Client) populateAPIVersions() error {
 	}
 
 	if len(errs) != 0 {
+		if bootstrapOnly {
+			// this was the first connection to the cluster; fail like
+			// sarama does when it cannot bootstrap
+			return fmt.Errorf("%w: %s", sarama.ErrOutOfBrokers, sarama.MultiErrorFormat(errs))
+		}
 		return errors.New(sarama.MultiErrorFormat(errs))
 	}
 
@@ -159,7 +361,7 @@ func apiVersionsFromBroker(broker *sarama.Broker, config *sarama.Config, ch chan
 	if err != nil {
 		errCh <- err
 	} else if sarama.KError(resp.ErrorCode) != sarama.ErrNoError {
-		errCh <- errors.New(sarama.KError(resp.ErrorCode).Error())
+		errCh <- newBrokerError("listing API versions of broker", broker.Addr(), sarama.KError(resp.ErrorCode), nil)
 	} else {
 		ch <- resp.ApiKeys
 	}
@@ -211,7 +413,9 @@ func updateClusterApiVersions(clusterApiVersions *map[int][2]int, brokerApiVersi
 }
 
 func (c *Client) extractTopics() error {
-	topics, err := c.client.Topics()
+	sc, release := c.acquireClient()
+	defer release()
+	topics, err := sc.Topics()
 	if err != nil {
 		log.Printf("[ERROR] Error getting topics %s from Kafka", err)
 		return err
@@ -226,11 +430,20 @@ func (c *Client) extractTopics() error {
 	return nil
 }
 
+func (c *Client) knowsTopic(name string) bool {
+	c.topicsMutex.RLock()
+	defer c.topicsMutex.RUnlock()
+	_, ok := c.topics[name]
+	return ok
+}
+
 func (c *Client) DeleteTopic(t string) error {
-	broker, err := c.client.Controller()
+	c.InvalidateTopicConfigCache(t)
+	broker, release, err := c.controller()
 	if err != nil {
 		return err
 	}
+	defer release()
 
 	timeout := time.Duration(c.config.Timeout) * time.Second
 	req := &sarama.DeleteTopicsRequest{
@@ -247,8 +460,8 @@ func (c *Client) DeleteTopic(t string) error {
 	res, err := broker.DeleteTopics(req)
 	if err == nil {
 		for k, e := range res.TopicErrorCodes {
-			if e != sarama.ErrNoError {
-				return fmt.Errorf("%s : %s", k, e)
+			if err := newBrokerError("deleting topic", k, e, nil); err != nil {
+				return err
 			}
 		}
 	} else {
@@ -262,14 +475,26 @@ func (c *Client) DeleteTopic(t string) error {
 }
 
 func (c *Client) UpdateTopic(topic Topic) error {
-	broker, err := c.client.Controller()
+	c.InvalidateTopicConfigCache(topic.Name)
+	return c.updateTopic(topic, false)
+}
+
+// ValidateUpdateTopic asks the controller to validate the topic's config
+// without altering it
+func (c *Client) ValidateUpdateTopic(topic Topic) error {
+	return c.updateTopic(topic, true)
+}
+
+func (c *Client) updateTopic(topic Topic, validateOnly bool) error {
+	broker, release, err := c.controller()
 	if err != nil {
 		return err
 	}
+	defer release()
 
 	r := &sarama.AlterConfigsRequest{
 		Resources:    configToResources(topic, c.config),
-		ValidateOnly: false,
+		ValidateOnly: validateOnly,
 	}
 
 	if c.kafkaConfig.Version.IsAtLeast(sarama.V2_0_0_0) {
@@ -283,8 +508,8 @@ func (c *Client) UpdateTopic(topic Topic) error {
 
 	if err == nil {
 		for _, e := range res.Resources {
-			if e.ErrorCode != int16(sarama.ErrNoError) {
-				return errors.New(e.ErrorMsg)
+			if err := newBrokerError("altering config of topic", e.Name, sarama.KError(e.ErrorCode), &e.ErrorMsg); err != nil {
+				return err
 			}
 		}
 	}
@@ -293,23 +518,57 @@ func (c *Client) UpdateTopic(topic Topic) error {
 }
 
 func (c *Client) CreateTopic(t Topic) error {
-	broker, err := c.client.Controller()
+	c.InvalidateTopicConfigCache(t.Name)
+	return c.createTopic(t, false)
+}
+
+// ValidateCreateTopic asks the controller to validate the creation of the
+// topic without creating it
+func (c *Client) ValidateCreateTopic(t Topic) error {
+	return c.createTopic(t, true)
+}
+
+func (c *Client) createTopic(t Topic, validateOnly bool) error {
+	broker, release, err := c.controller()
 	if err != nil {
 		return err
 	}
+	defer release()
 
 	timeout := time.Duration(c.config.Timeout) * time.Second
 	log.Printf("[TRACE] Timeout is %v ", timeout)
 
+	detail := &sarama.TopicDetail{
+		NumPartitions:     t.Partitions,
+		ReplicationFactor: t.ReplicationFactor,
+		ConfigEntries:     t.Config,
+	}
+	if len(t.ReplicaAssignment) > 0 {
+		// the broker rejects requests that set both an assignment and
+		// the partition count or replication factor
+		detail.NumPartitions = -1
+		detail.ReplicationFactor = -1
+		detail.ReplicaAssignment = t.ReplicaAssignment
+	} else if t.Partitions == 0 || t.ReplicationFactor == 0 {
+		partitions, rf, err := c.topicDefaults(broker)
+		if err != nil {
+			return err
+		}
+		if detail.NumPartitions == 0 {
+			detail.NumPartitions = partitions
+		}
+		if detail.ReplicationFactor == 0 {
+			detail.ReplicationFactor = rf
+		}
+		log.Printf("[INFO] Creating topic %s with %d partitions and a replication factor of %d", t.Name, detail.NumPartitions, detail.ReplicationFactor)
+	}
+
 	req := &sarama.CreateTopicsRequest{
 		TopicDetails: map[string]*sarama.TopicDetail{
-			t.Name: {
-				NumPartitions:     t.Partitions,
-				ReplicationFactor: t.ReplicationFactor,
-				ConfigEntries:     t.Config,
-			},
+			t.Name: detail,
 		},
-		Timeout: timeout,
+		Timeout:      timeout,
+		ValidateOnly: validateOnly,
 	}
 	if c.kafkaConfig.Version.IsAtLeast(sarama.V2_0_0_0) {
 		req.Version = 3
@@ -321,22 +580,74 @@ func (c *Client) CreateTopic(t Topic) error {
 	res, err := broker.CreateTopics(req)
 
 	if err == nil {
-		for _, e := range res.TopicErrors {
-			if e.Err != sarama.ErrNoError {
-				return fmt.Errorf("%s", e.Err)
+		for name, e := range res.TopicErrors {
+			if err := newBrokerError("creating topic", name, e.Err, e.ErrMsg); err != nil {
+				return err
 			}
 		}
-		log.Printf("[INFO] Created topic %s in Kafka", t.Name)
+		if validateOnly {
+			log.Printf("[INFO] Creation of topic %s validated by Kafka", t.Name)
+		} else {
+			log.Printf("[INFO] Created topic %s in Kafka", t.Name)
+		}
 	}
 
 	return err
 }
 
+const (
+	numPartitionsConfig            = "num.partitions"
+	defaultReplicationFactorConfig = "default.replication.factor"
+)
+
+// topicDefaults returns the num.partitions and default.replication.factor of
+// the broker, for topics that leave out their partitions or replication
+// factor. Unlike sending -1 to have the broker apply them, this works with
+// brokers older than Kafka 2.4.0.
+func (c *Client) topicDefaults(broker *sarama.Broker) (int32, int16, error) {
+	res, err := broker.DescribeConfigs(&sarama.DescribeConfigsRequest{
+		Resources: []*sarama.ConfigResource{
+			{
+				Type:        sarama.BrokerResource,
+				Name:        strconv.Itoa(int(broker.ID())),
+				ConfigNames: []string{numPartitionsConfig, defaultReplicationFactorConfig},
+			},
+		},
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	defaults := map[string]int{}
+	for _, r := range res.Resources {
+		if err := newBrokerError("describing the topic defaults of broker", r.Name, sarama.KError(r.ErrorCode), &r.ErrorMsg); err != nil {
+			return 0, 0, err
+		}
+		for _, entry := range r.Configs {
+			value, err := strconv.Atoi(entry.Value)
+			if err != nil {
+				return 0, 0, fmt.Errorf("invalid %s %q on broker %d", entry.Name, entry.Value, broker.ID())
+			}
+			defaults[entry.Name] = value
+		}
+	}
+
+	for _, key := range []string{numPartitionsConfig, defaultReplicationFactorConfig} {
+		if defaults[key] < 1 {
+			return 0, 0, fmt.Errorf("broker %d did not return a usable %s", broker.ID(), key)
+		}
+	}
+	return int32(defaults[numPartitionsConfig]), int16(defaults[defaultReplicationFactorConfig]), nil
+}
+
 func (c *Client) AddPartitions(t Topic) error {
-	broker, err := c.client.Controller()
+	sc, release := c.acquireClient()
+	defer release()
+	broker, release, err := c.controller()
 	if err != nil {
 		return err
 	}
+	defer release()
 
 	timeout := time.Duration(c.config.Timeout) * time.Second
 	tp := map[string]*sarama.TopicPartition{
@@ -345,6 +656,22 @@ func (c *Client) AddPartitions(t Topic) error {
 		},
 	}
 
+	if len(t.ReplicaAssignment) > 0 {
+		partitions, err := sc.Partitions(t.Name)
+		if err != nil {
+			return err
+		}
+
+		// only the new partitions are assigned here
+		for p := int32(len(partitions)); p < t.Partitions; p++ {
+			replicas, ok := t.ReplicaAssignment[p]
+			if !ok {
+				return fmt.Errorf("replica_assignment is missing partition %d", p)
+			}
+			tp[t.Name].Assignment = append(tp[t.Name].Assignment, replicas)
+		}
+	}
+
 	req := &sarama.CreatePartitionsRequest{
 		TopicPartitions: tp,
 		Timeout:         timeout,
@@ -358,9 +685,9 @@ func (c *Client) AddPartitions(t Topic) error {
 	log.Printf("[INFO] Adding partitions to %s in Kafka", t.Name)
 	res, err := broker.CreatePartitions(req)
 	if err == nil {
-		for _, e := range res.TopicPartitionErrors {
-			if e.Err != sarama.ErrNoError {
-				return fmt.Errorf("%s", e.Err)
+		for name, e := range res.TopicPartitionErrors {
+			if err := newBrokerError("adding partitions to topic", name, e.Err, e.ErrMsg); err != nil {
+				return err
 			}
 		}
 		log.Printf("[INFO] Added partitions to %s in Kafka", t.Name)
@@ -370,19 +697,18 @@ func (c *Client) AddPartitions(t Topic) error {
 }
 
 func (c *Client) CanAlterReplicationFactor() bool {
-	_, ok1 := c.supportedAPIs[45] // https://kafka.apache.org/protocol#The_Messages_AlterPartitionReassignments
-	_, ok2 := c.supportedAPIs[46] // https://kafka.apache.org/protocol#The_Messages_ListPartitionReassignments
-
-	return ok1 && ok2
+	return c.SupportsAPI(apiKeyAlterPartitionReassignments, 0) && c.SupportsAPI(apiKeyListPartitionReassignments, 0)
 }
 
 func (c *Client) AlterReplicationFactor(t Topic) error {
+	sc, release := c.acquireClient()
+	defer release()
 	log.Printf("[DEBUG] Refreshing metadata for topic '%s'", t.Name)
-	if err := c.client.RefreshMetadata(t.Name); err != nil {
+	if err := sc.RefreshMetadata(t.Name); err != nil {
 		return err
 	}
 
-	admin, err := sarama.NewClusterAdminFromClient(c.client)
+	admin, err := sarama.NewClusterAdminFromClient(sc)
 	if err != nil {
 		return err
 	}
@@ -395,8 +721,43 @@ func (c *Client) AlterReplicationFactor(t Topic) error {
 	return admin.AlterPartitionReassignments(t.Name, *assignment)
 }
 
+// AlterReplicaAssignment moves the replicas of the existing partitions of
+// the topic to the brokers in its ReplicaAssignment
+func (c *Client) AlterReplicaAssignment(t Topic) error {
+	sc, release := c.acquireClient()
+	defer release()
+	log.Printf("[DEBUG] Refreshing metadata for topic '%s'", t.Name)
+	if err := sc.RefreshMetadata(t.Name); err != nil {
+		return err
+	}
+
+	partitions, err := sc.Partitions(t.Name)
+	if err != nil {
+		return err
+	}
+
+	assignment := make([][]int32, len(partitions))
+	for _, p := range partitions {
+		replicas, ok := t.ReplicaAssignment[p]
+		if !ok {
+			return fmt.Errorf("replica_assignment is missing partition %d", p)
+		}
+		assignment[p] = replicas
+	}
+
+	admin, err := sarama.NewClusterAdminFromClient(sc)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[INFO] Reassigning replicas of %s to %v", t.Name, assignment)
+	return admin.AlterPartitionReassignments(t.Name, assignment)
+}
+
 func (c *Client) buildAssignment(t Topic) (*[][]int32, error) {
-	partitions, err := c.client.Partitions(t.Name)
+	sc, release := c.acquireClient()
+	defer release()
+	partitions, err := sc.Partitions(t.Name)
 	if err != nil {
 		return nil, err
 	}
@@ -406,7 +767,7 @@ func (c *Client) buildAssignment(t Topic) (*[][]int32, error) {
 
 	assignment := make([][]int32, len(partitions))
 	for _, p := range partitions {
-		oldReplicas, err := c.client.Replicas(t.Name, p)
+		oldReplicas, err := sc.Replicas(t.Name, p)
 		if err != nil {
 			return &assignment, err
 		}
@@ -424,8 +785,43 @@ func (c *Client) buildAssignment(t Topic) (*[][]int32, error) {
 	return &assignment, nil
 }
 
+// TopicPropagated reports whether the metadata of every broker includes the
+// topic, which lags behind the controller's for a moment after the topic is
+// created. A broker that cannot be asked is left out rather than waited for.
+func (c *Client) TopicPropagated(name string) (bool, error) {
+	sc, release := c.acquireClient()
+	defer release()
+	for _, b := range sc.Brokers() {
+		broker, err := sc.Broker(b.ID())
+		if err != nil {
+			log.Printf("[WARN] Could not connect to broker %d to check topic %s: %s", b.ID(), name, err)
+			continue
+		}
+
+		res, err := broker.GetMetadata(sarama.NewMetadataRequest(c.kafkaConfig.Version, []string{name}))
+		if err != nil {
+			log.Printf("[WARN] Could not get the metadata of topic %s from broker %d: %s", name, b.ID(), err)
+			continue
+		}
+
+		known := false
+		for _, topic := range res.Topics {
+			if topic.Name == name && topic.Err == sarama.ErrNoError {
+				known = true
+			}
+		}
+		if !known {
+			log.Printf("[DEBUG] Topic %s is not in the metadata of broker %d yet", name, b.ID())
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
 func (c *Client) allReplicas() *[]int32 {
-	brokers := c.client.Brokers()
+	sc, release := c.acquireClient()
+	defer release()
+	brokers := sc.Brokers()
 	replicas := make([]int32, 0, len(brokers))
 
 	for _, b := range brokers {
@@ -488,17 +884,19 @@ func findUnusedReplicas(allReplicas *[]int32, usedReplicas *[]int32, extraCount
 }
 
 func (c *Client) IsReplicationFactorUpdating(topic string) (bool, error) {
+	sc, release := c.acquireClient()
+	defer release()
 	log.Printf("[DEBUG] Refreshing metadata for topic '%s'", topic)
-	if err := c.client.RefreshMetadata(topic); err != nil {
+	if err := sc.RefreshMetadata(topic); err != nil {
 		return false, err
 	}
 
-	partitions, err := c.client.Partitions(topic)
+	partitions, err := sc.Partitions(topic)
 	if err != nil {
 		return false, err
 	}
 
-	admin, err := sarama.NewClusterAdminFromClient(c.client)
+	admin, err := sarama.NewClusterAdminFromClient(sc)
 	if err != nil {
 		return false, err
 	}
@@ -521,19 +919,32 @@ func isPartitionRFChanging(status *sarama.PartitionReplicaReassignmentsStatus) b
 	return len(status.AddingReplicas) != 0 || len(status.RemovingReplicas) != 0
 }
 
+// topicReadError turns errors caused by the topic having been deleted since
+// the metadata was cached into a TopicMissingError
+func topicReadError(name string, err error) error {
+	if errors.Is(err, sarama.ErrUnknownTopicOrPartition) {
+		log.Printf("[WARN] Topic %s no longer exists: %s", name, err)
+		return TopicMissingError{msg: fmt.Sprintf("%s could not be found", name)}
+	}
+	return err
+}
+
 func (client *Client) ReadTopic(name string, refreshMetadata bool) (Topic, error) {
-	c := client.client
+	c, release := client.acquireClient()
+	defer release()
 	log.Printf("[INFO] 👋 reading topic '%s' from Kafka: %v", name, refreshMetadata)
 
 	topic := Topic{
 		Name: name,
 	}
 
-	if refreshMetadata {
+	// without full metadata, a topic not used so far is unknown until fetched
+	fetchMetadata := refreshMetadata || (!client.kafkaConfig.Metadata.Full && !client.knowsTopic(name))
+	if fetchMetadata {
 		log.Printf("[DEBUG] Refreshing metadata for topic '%s'", name)
 		err := c.RefreshMetadata(name)
 
-		if err == sarama.ErrUnknownTopicOrPartition {
+		if errors.Is(err, sarama.ErrUnknownTopicOrPartition) {
 			err := TopicMissingError{msg: fmt.Sprintf("%s could not be found", name)}
 			return topic, err
 		}
@@ -549,10 +960,7 @@ func (client *Client) ReadTopic(name string, refreshMetadata bool) (Topic, error
 		log.Printf("[DEBUG] skipping metadata refresh for topic '%s'", name)
 	}
 
-	client.topicsMutex.RLock()
-	_, topicExists := client.topics[name]
-	client.topicsMutex.RUnlock()
-	if topicExists {
+	if client.knowsTopic(name) {
 		log.Printf("[DEBUG] Found %s from Kafka", name)
 		p, err := c.Partitions(name)
 		if err == nil {
@@ -562,16 +970,27 @@ func (client *Client) ReadTopic(name string, refreshMetadata bool) (Topic, error
 
 			r, err := ReplicaCount(c, name, p)
 			if err != nil {
-				return topic, err
+				return topic, topicReadError(name, err)
 			}
 
 			log.Printf("[DEBUG] [%s] ReplicationFactor %d from Kafka", name, r)
 			topic.ReplicationFactor = int16(r)
 
-			configToSave, err := client.topicConfig(name)
+			assignment, err := ReplicaAssignment(c, name, p)
+			if err != nil {
+				return topic, topicReadError(name, err)
+			}
+			topic.ReplicaAssignment = assignment
+
+			var configToSave map[string]*string
+			if refreshMetadata {
+				configToSave, err = client.topicConfig(name)
+			} else {
+				configToSave, err = client.cachedTopicConfig(name)
+			}
 			if err != nil {
 				log.Printf("[ERROR] [%s] Could not get config for topic %s", name, err)
-				return topic, err
+				return topic, topicReadError(name, err)
 			}
 
 			log.Printf("[TRACE] [%s] Config %v from Kafka", name, strPtrMapToStrMap(configToSave))
@@ -597,51 +1016,195 @@ func (c *Client) versionForKey(apiKey, wantedMaxVersion int) int {
 
 // topicConfig retrives the non-default config map for a topic
 func (c *Client) topicConfig(topic string) (map[string]*string, error) {
-	conf := map[string]*string{}
-	request := &sarama.DescribeConfigsRequest{
-		Version: c.getDescribeConfigAPIVersion(),
-		Resources: []*sarama.ConfigResource{
-			{
-				Type: sarama.TopicResource,
-				Name: topic,
-			},
-		},
+	broker, release, err := c.controller()
+	if err != nil {
+		return map[string]*string{}, err
 	}
+	defer release()
 
-	broker, err := c.client.Controller()
+	configs, errs, err := c.describeTopicConfigs(broker, []string{topic})
 	if err != nil {
-		return conf, err
+		return map[string]*string{}, err
 	}
-
-	if c.kafkaConfig.Version.IsAtLeast(sarama.V1_1_0_0) {
-		request.Version = 1
+	if err, ok := errs[topic]; ok {
+		return map[string]*string{}, err
 	}
 
-	if c.kafkaConfig.Version.IsAtLeast(sarama.V2_0_0_0) {
-		request.Version = 2
+	c.topicConfigCache.mutex.Lock()
+	c.topicConfigCache.configs[topic] = configs[topic]
+	c.topicConfigCache.mutex.Unlock()
+
+	return copyConfig(configs[topic]), nil
+}
+
+// cachedTopicConfig returns the non-default config map for a topic from the
+// cache, coalescing concurrent cache misses into batched DescribeConfigs
+// requests
+func (c *Client) cachedTopicConfig(topic string) (map[string]*string, error) {
+	c.topicConfigCache.mutex.RLock()
+	conf, ok := c.topicConfigCache.configs[topic]
+	c.topicConfigCache.mutex.RUnlock()
+	if ok {
+		log.Printf("[INFO] Using cached config for topic %s", topic)
+		return copyConfig(conf), nil
 	}
 
-	cr, err := broker.DescribeConfigs(request)
+	broker, release, err := c.controller()
 	if err != nil {
-		return conf, err
+		return map[string]*string{}, err
+	}
+	defer release()
+
+	if err := c.enqueueDescribeTopicConfig(broker, topic); err != nil {
+		return map[string]*string{}, err
+	}
+
+	c.topicConfigCache.mutex.RLock()
+	conf = c.topicConfigCache.configs[topic]
+	c.topicConfigCache.mutex.RUnlock()
+	return copyConfig(conf), nil
+}
+
+// InvalidateTopicConfigCache drops the cached config for a topic
+func (c *Client) InvalidateTopicConfigCache(topic string) {
+	c.topicConfigCache.mutex.Lock()
+	delete(c.topicConfigCache.configs, topic)
+	c.topicConfigCache.mutex.Unlock()
+}
+
+func (c *Client) enqueueDescribeTopicConfig(broker *sarama.Broker, topic string) error {
+	c.topicConfigQueue.mutex.Lock()
+	log.Printf("[DEBUG] Enqueueing config description for topic %s", topic)
+	if c.topicConfigQueue.timer != nil {
+		c.topicConfigQueue.timer.Stop()
 	}
+	c.topicConfigQueue.topics = append(c.topicConfigQueue.topics, topic)
+	c.topicConfigQueue.waitChans = append(c.topicConfigQueue.waitChans, make(chan error))
+	var waitChan = c.topicConfigQueue.waitChans[len(c.topicConfigQueue.waitChans)-1]
+
+	c.topicConfigQueue.timer = time.AfterFunc(c.topicConfigQueue.after, func() {
+		c.topicConfigQueue.mutex.Lock()
+		defer c.topicConfigQueue.mutex.Unlock()
+		log.Printf("[INFO] Describing config for %d topics", len(c.topicConfigQueue.topics))
+		defer func() {
+			c.topicConfigQueue.timer = nil
+			c.topicConfigQueue.topics = nil
+			c.topicConfigQueue.waitChans = nil
+		}()
+
+		configs, errs, err := c.describeTopicConfigs(broker, c.topicConfigQueue.topics)
+		if err != nil {
+			for _, wc := range c.topicConfigQueue.waitChans {
+				wc <- err
+			}
+			return
+		}
 
-	if len(cr.Resources) > 0 && len(cr.Resources[0].Configs) > 0 {
-		for _, tConf := range cr.Resources[0].Configs {
+		c.topicConfigCache.mutex.Lock()
+		for t, conf := range configs {
+			c.topicConfigCache.configs[t] = conf
+		}
+		c.topicConfigCache.mutex.Unlock()
+
+		for i, t := range c.topicConfigQueue.topics {
+			if err, ok := errs[t]; ok {
+				c.topicConfigQueue.waitChans[i] <- err
+			} else if _, ok := configs[t]; !ok {
+				c.topicConfigQueue.waitChans[i] <- fmt.Errorf("no config returned for topic %s", t)
+			} else {
+				c.topicConfigQueue.waitChans[i] <- nil
+			}
+		}
+	})
+
+	c.topicConfigQueue.mutex.Unlock()
+	return <-waitChan
+}
+
+// describeTopicConfigs fetches the non-default config of each topic, sending
+// at most describeConfigsBatchSize topics per request. Errors for individual
+// topics are returned keyed by topic name.
+func (c *Client) describeTopicConfigs(broker *sarama.Broker, topics []string) (map[string]map[string]*string, map[string]error, error) {
+	configs := map[string]map[string]*string{}
+	errs := map[string]error{}
+
+	for _, batch := range chunkStrings(uniqueStrings(topics), describeConfigsBatchSize) {
+		request := &sarama.DescribeConfigsRequest{
+			Version:   c.getDescribeConfigAPIVersion(),
+			Resources: make([]*sarama.ConfigResource, 0, len(batch)),
+		}
+		for _, topic := range batch {
+			request.Resources = append(request.Resources, &sarama.ConfigResource{
+				Type: sarama.TopicResource,
+				Name: topic,
+			})
+		}
+
+		if c.kafkaConfig.Version.IsAtLeast(sarama.V1_1_0_0) {
+			request.Version = 1
+		}
+
+		if c.kafkaConfig.Version.IsAtLeast(sarama.V2_0_0_0) {
+			request.Version = 2
+		}
+
+		cr, err := broker.DescribeConfigs(request)
+		if err != nil {
+			return configs, errs, err
+		}
+
+		batchConfigs, batchErrs := topicConfigsFromResponse(cr)
+		for t, conf := range batchConfigs {
+			configs[t] = conf
+		}
+		for t, err := range batchErrs {
+			errs[t] = err
+		}
+	}
+
+	return configs, errs, nil
+}
+
+func topicConfigsFromResponse(cr *sarama.DescribeConfigsResponse) (map[string]map[string]*string, map[string]error) {
+	configs := map[string]map[string]*string{}
+	errs := map[string]error{}
+
+	for _, res := range cr.Resources {
+		if err := newBrokerError("describing config of topic", res.Name, sarama.KError(res.ErrorCode), &res.ErrorMsg); err != nil {
+			errs[res.Name] = err
+			continue
+		}
+
+		conf := map[string]*string{}
+		for _, tConf := range res.Configs {
 			v := tConf.Value
-			log.Printf("[TRACE] [%s] %s: %v. Default %v, Source %v, Version %d", topic, tConf.Name, v, tConf.Default, tConf.Source, cr.Version)
+			log.Printf("[TRACE] [%s] %s: %v. Default %v, Source %v, Version %d", res.Name, tConf.Name, v, tConf.Default, tConf.Source, cr.Version)
 
 			for _, s := range tConf.Synonyms {
 				log.Printf("[TRACE] Syonyms: %v", s)
 			}
 
-			if isDefault(tConf, int(cr.Version)) {
+			if !isSetOnTopic(tConf, int(cr.Version)) {
+				continue
+			}
+			if tConf.Sensitive {
+				conf[tConf.Name] = nil
 				continue
 			}
 			conf[tConf.Name] = &v
 		}
+		configs[res.Name] = conf
+	}
+
+	return configs, errs
+}
+
+func copyConfig(conf map[string]*string) map[string]*string {
+	res := make(map[string]*string, len(conf))
+	for k, v := range conf {
+		res[k] = v
 	}
-	return conf, nil
+	return res
 }
 
 func (c *Client) getDescribeAclsRequestAPIVersion() int16 {
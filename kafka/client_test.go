@@ -1,6 +1,13 @@
 package kafka
 
-import "testing"
+import (
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+)
 
 func Test_NewClient(t *testing.T) {
 	config := &Config{}
@@ -34,3 +41,242 @@ func Test_ClientAPIVersion(t *testing.T) {
 		t.Errorf("Got %d, expected %d", maxVersion, 1)
 	}
 }
+
+func Test_topicConfigsFromResponse(t *testing.T) {
+	res := &sarama.DescribeConfigsResponse{
+		Version: 2,
+		Resources: []*sarama.ResourceResponse{
+			{
+				Name: "a",
+				Configs: []*sarama.ConfigEntry{
+					{Name: "retention.ms", Value: "1000", Source: sarama.SourceTopic},
+					{Name: "segment.ms", Value: "2000", Source: sarama.SourceDefault},
+					{Name: "cleanup.policy", Value: "compact", Source: sarama.SourceDynamicBroker},
+					{Name: "min.insync.replicas", Value: "2", Source: sarama.SourceDynamicDefaultBroker},
+					{Name: "max.message.bytes", Value: "1048588", Source: sarama.SourceStaticBroker},
+					{Name: "custom.password", Value: "", Sensitive: true, Source: sarama.SourceTopic},
+				},
+			},
+			{
+				Name:      "b",
+				ErrorCode: int16(sarama.ErrUnknownTopicOrPartition),
+				ErrorMsg:  "unknown topic",
+			},
+		},
+	}
+
+	configs, errs := topicConfigsFromResponse(res)
+
+	if len(configs) != 1 || len(configs["a"]) != 2 || *configs["a"]["retention.ms"] != "1000" {
+		t.Errorf("unexpected configs %v", configs)
+	}
+	if v, ok := configs["a"]["custom.password"]; !ok || v != nil {
+		t.Errorf("expected the sensitive entry to be set without a value, got %v", v)
+	}
+	if _, ok := errs["b"]; !ok || len(errs) != 1 {
+		t.Errorf("expected an error for topic b, got %v", errs)
+	}
+}
+
+func Test_topicConfigsFromResponse_V0(t *testing.T) {
+	res := &sarama.DescribeConfigsResponse{
+		Version: 0,
+		Resources: []*sarama.ResourceResponse{
+			{
+				Name: "a",
+				Configs: []*sarama.ConfigEntry{
+					{Name: "retention.ms", Value: "1000", Source: sarama.SourceUnknown},
+					{Name: "segment.ms", Value: "2000", Default: true, Source: sarama.SourceDefault},
+				},
+			},
+		},
+	}
+
+	configs, _ := topicConfigsFromResponse(res)
+
+	if len(configs["a"]) != 1 || *configs["a"]["retention.ms"] != "1000" {
+		t.Errorf("unexpected configs %v", configs)
+	}
+}
+
+func Test_createTopicBrokerDefaults(t *testing.T) {
+	mb := sarama.NewMockBroker(t, 1)
+	defer mb.Close()
+	mb.SetHandlerByMap(map[string]sarama.MockResponse{
+		"ApiVersionsRequest": sarama.NewMockApiVersionsResponse(t),
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(mb.Addr(), mb.BrokerID()).
+			SetController(mb.BrokerID()),
+		"DescribeConfigsRequest": sarama.NewMockWrapper(&sarama.DescribeConfigsResponse{
+			Resources: []*sarama.ResourceResponse{
+				{
+					Type: sarama.BrokerResource,
+					Name: "1",
+					Configs: []*sarama.ConfigEntry{
+						{Name: "num.partitions", Value: "6"},
+						{Name: "default.replication.factor", Value: "3"},
+					},
+				},
+			},
+		}),
+		"CreateTopicsRequest": sarama.NewMockCreateTopicsResponse(t),
+	})
+
+	client, err := NewClient(&Config{
+		BootstrapServers: &[]string{mb.Addr()},
+		Timeout:          5,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.client.Close()
+
+	assertNil(t, client.CreateTopic(Topic{Name: "defaults"}))
+	assertNil(t, client.CreateTopic(Topic{Name: "partitions", Partitions: 2}))
+	assertNil(t, client.CreateTopic(Topic{Name: "explicit", Partitions: 2, ReplicationFactor: 1}))
+
+	describes := 0
+	details := map[string]*sarama.TopicDetail{}
+	for _, rr := range mb.History() {
+		switch req := rr.Request.(type) {
+		case *sarama.DescribeConfigsRequest:
+			describes++
+		case *sarama.CreateTopicsRequest:
+			for name, detail := range req.TopicDetails {
+				details[name] = detail
+			}
+		}
+	}
+
+	// the defaults are only read for topics that need them
+	assertEquals(t, 2, describes)
+	assertEquals(t, int32(6), details["defaults"].NumPartitions)
+	assertEquals(t, int16(3), details["defaults"].ReplicationFactor)
+	assertEquals(t, int32(2), details["partitions"].NumPartitions)
+	assertEquals(t, int16(3), details["partitions"].ReplicationFactor)
+	assertEquals(t, int32(2), details["explicit"].NumPartitions)
+	assertEquals(t, int16(1), details["explicit"].ReplicationFactor)
+}
+
+func Test_TopicPropagated(t *testing.T) {
+	mb := sarama.NewMockBroker(t, 1)
+	defer mb.Close()
+	metadata := sarama.NewMockMetadataResponse(t).
+		SetBroker(mb.Addr(), mb.BrokerID()).
+		SetController(mb.BrokerID())
+	mb.SetHandlerByMap(map[string]sarama.MockResponse{
+		"ApiVersionsRequest": sarama.NewMockApiVersionsResponse(t),
+		"MetadataRequest":    metadata,
+	})
+
+	client, err := NewClient(&Config{
+		BootstrapServers: &[]string{mb.Addr()},
+		Timeout:          5,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.client.Close()
+
+	propagated, err := client.TopicPropagated("new")
+	assertNil(t, err)
+	assertEquals(t, false, propagated)
+
+	metadata.SetLeader("new", 0, mb.BrokerID())
+	propagated, err = client.TopicPropagated("new")
+	assertNil(t, err)
+	assertEquals(t, true, propagated)
+}
+
+func Test_topicReadError(t *testing.T) {
+	_, errs := topicConfigsFromResponse(&sarama.DescribeConfigsResponse{
+		Resources: []*sarama.ResourceResponse{
+			{Name: "b", ErrorCode: int16(sarama.ErrUnknownTopicOrPartition), ErrorMsg: "unknown topic"},
+		},
+	})
+
+	if _, ok := topicReadError("b", errs["b"]).(TopicMissingError); !ok {
+		t.Errorf("expected a TopicMissingError, got %v", topicReadError("b", errs["b"]))
+	}
+
+	wrapped := fmt.Errorf("could not get replicas for partition: %w", sarama.ErrUnknownTopicOrPartition)
+	if _, ok := topicReadError("b", wrapped).(TopicMissingError); !ok {
+		t.Errorf("expected a TopicMissingError, got %v", topicReadError("b", wrapped))
+	}
+
+	other := sarama.ErrBrokerNotAvailable
+	if err := topicReadError("b", other); err != other {
+		t.Errorf("expected %v to pass through, got %v", other, err)
+	}
+}
+
+func Test_NewClientIPv6(t *testing.T) {
+	l, err := net.Listen("tcp", "[::1]:0")
+	if err != nil {
+		t.Skipf("IPv6 is not available: %s", err)
+	}
+	mb := sarama.NewMockBrokerListener(t, 1, l)
+	defer mb.Close()
+	mb.SetHandlerByMap(map[string]sarama.MockResponse{
+		"ApiVersionsRequest": sarama.NewMockApiVersionsResponse(t),
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(mb.Addr(), mb.BrokerID()).
+			SetController(mb.BrokerID()).
+			SetLeader("events", 0, mb.BrokerID()),
+	})
+
+	client, err := NewClient(&Config{
+		BootstrapServers: &[]string{mb.Addr()},
+		Timeout:          5,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.client.Close()
+
+	brokers, controllerID, err := client.DescribeBrokers()
+	assertNil(t, err)
+	assertEquals(t, int32(1), controllerID)
+	assertEquals(t, 1, len(brokers))
+	assertEquals(t, "::1", brokers[0].Host)
+	assertEquals(t, true, client.knowsTopic("events"))
+}
+
+func Test_rebootstrap(t *testing.T) {
+	mb := sarama.NewMockBroker(t, 1)
+	defer mb.Close()
+	mb.SetHandlerByMap(map[string]sarama.MockResponse{
+		"ApiVersionsRequest": sarama.NewMockApiVersionsResponse(t),
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(mb.Addr(), mb.BrokerID()).
+			SetController(mb.BrokerID()),
+	})
+
+	client, err := NewClient(&Config{
+		BootstrapServers: &[]string{mb.Addr()},
+		Timeout:          5,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	failed, release := client.acquireClient()
+	assertNil(t, client.rebootstrap(failed))
+	replaced := client.SaramaClient()
+	if replaced == sarama.Client(failed) {
+		t.Fatal("expected the client to be replaced")
+	}
+	defer replaced.Close()
+
+	// another operation failing on the same client finds it replaced already
+	assertNil(t, client.rebootstrap(failed))
+	assertEquals(t, replaced, client.SaramaClient())
+
+	// the replaced client stays open until it was released
+	assertEquals(t, false, failed.Closed())
+	release()
+	for i := 0; i < 100 && !failed.Closed(); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assertEquals(t, true, failed.Closed())
+}
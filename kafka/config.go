@@ -4,22 +4,43 @@ import (
 	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/base64"
 	"encoding/pem"
+	"errors"
 	"fmt"
 	"log"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
 	"os"
+	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/IBM/sarama"
 	"github.com/aws/aws-msk-iam-sasl-signer-go/signer"
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/credentials"
-	"github.com/aws/aws-sdk-go-v2/credentials/endpointcreds"
+	"github.com/xdg/scram"
+	"golang.org/x/crypto/pkcs12" //nolint:staticcheck
 	"golang.org/x/net/proxy"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/clientcredentials"
 )
 
+const defaultClientID = "terraform-provider-kafka"
+
+// defaultDialTimeout matches sarama's own default; falling back to the much
+// longer operation timeout would leave an unreachable broker hanging instead
+const defaultDialTimeout = 30 * time.Second
+
+const (
+	clientCertFormatPEM    = "pem"
+	clientCertFormatPKCS12 = "pkcs12"
+)
+
 type Config struct {
 	BootstrapServers                       *[]string
 	Timeout                                int
@@ -27,6 +48,7 @@ type Config struct {
 	ClientCert                             string
 	ClientCertKey                          string
 	ClientCertKeyPassphrase                string
+	ClientCertFormat                       string
 	KafkaVersion                           string
 	TLSEnabled                             bool
 	SkipTLSVerify                          bool
@@ -38,6 +60,8 @@ type Config struct {
 	SASLAWSRegion                          string
 	SASLAWSRoleArn                         string
 	SASLAWSExternalId                      string
+	SASLAWSWebIdentityTokenFile            string
+	SASLAWSRoleArnChain                    []AWSAssumeRole
 	SASLAWSProfile                         string
 	SASLAWSAccessKey                       string
 	SASLAWSSecretKey                       string
@@ -46,33 +70,113 @@ type Config struct {
 	SASLTokenUrl                           string
 	SASLAWSSharedConfigFiles               *[]string
 	SASLOAuthScopes                        []string
+	ClientID                               string
+	DialTimeout                            int
+	ReadTimeout                            int
+	WriteTimeout                           int
+	MetadataTimeout                        int
+	SASLOAuthClientCertEnabled             bool
+	SASLOAuthRefreshSkewSeconds            int
+	SASLOAuthRefreshJitterSeconds          int
+	MetadataRefreshFrequency               int
+	MetadataFull                           *bool
+	ResolveCanonicalBootstrapServers       bool
+	DefaultTopicConfig                     map[string]string
+	SASLTokenAuth                          bool
+	RetryMaxRetries                        int
+	RetryMaxElapsedTime                    int
+	AdminRetryMax                          *int
+	AdminRetryBackoff                      int
+	TLSMinVersion                          string
+	TLSMaxVersion                          string
+	TLSCipherSuites                        []string
+	TLSServerName                          string
+	CACerts                                []string
+	ProxyURL                               string
+	ProxyUsername                          string
+	ProxyPassword                          string
+	SASLOAuthTokenSource                   string
+	SASLOAuthTokenFile                     string
+	SASLOAuthTokenCommand                  []string
+	SASLOAuthAzureClientID                 string
+	SASLOAuthExtensions                    map[string]string
+	MaxConcurrency                         int
+	ValidateOnly                           bool
+	AllowAutoTopicCreation                 bool
+	Debug                                  bool
+	TLSExclusiveCA                         bool
+	ClientCertKeyPassphraseFile            string
+	SSHTunnel                              *SSHTunnel
+	BootstrapServersSRV                    string
+	KeepAlive                              int
+	MaxOpenRequests                        int
+	SASLAuthzID                            string
+	// FailoverBootstrapServers are the bootstrap servers of other clusters,
+	// tried in turn when none of BootstrapServers can be reached
+	FailoverBootstrapServers [][]string
+	// SASLDisableHandshake skips the SaslHandshake sent before
+	// authenticating, which every broker since Kafka 0.10 expects. Only
+	// legacy brokers need it disabled.
+	SASLDisableHandshake bool
+	// SASLVersion is the version of the SASL flow: 1 authenticates with
+	// SaslAuthenticate requests, 0 sends the tokens on their own after the
+	// handshake, for brokers rejecting SaslAuthenticate. When nil, sarama's
+	// default of 1 is used, or 0 without the handshake.
+	SASLVersion *int
 }
 
 type OAuth2Config interface {
 	Token(ctx context.Context) (*oauth2.Token, error)
 }
 
+const defaultOAuthRefreshSkew = 2 * time.Second
+
+// awsIAMTokenRefreshSkew is how long before an MSK auth token, valid for 15
+// minutes, expires that a new one is generated
+const awsIAMTokenRefreshSkew = time.Minute
+
 type oauthbearerTokenProvider struct {
+	mu              sync.Mutex
 	tokenExpiration time.Time
 	token           string
 	oauth2Config    OAuth2Config
+	httpClient      *http.Client
+	refreshSkew     time.Duration
+	refreshJitter   time.Duration
+	jitter          time.Duration
+	extensions      map[string]string
 }
 
-func newOauthbearerTokenProvider(oauth2Config OAuth2Config) *oauthbearerTokenProvider {
+// newOauthbearerTokenProvider creates a token provider for oauth2Config. When
+// httpClient is not nil it is used for requests to the token endpoint.
+// Tokens are refreshed refreshSkew before they expire, plus a random amount
+// of up to refreshJitter so that concurrent clients don't refresh together.
+func newOauthbearerTokenProvider(oauth2Config OAuth2Config, httpClient *http.Client, refreshSkew time.Duration, refreshJitter time.Duration) *oauthbearerTokenProvider {
 	return &oauthbearerTokenProvider{
 		tokenExpiration: time.Time{},
 		token:           "",
 		oauth2Config:    oauth2Config,
+		httpClient:      httpClient,
+		refreshSkew:     refreshSkew,
+		refreshJitter:   refreshJitter,
 	}
 }
 
 func (o *oauthbearerTokenProvider) Token() (*sarama.AccessToken, error) {
+	// sarama asks for a token on every new broker connection, which can be
+	// opened at the same time
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
 	var accessToken string
 	var err error
 	currentTime := time.Now()
 	ctx := context.Background()
+	if o.httpClient != nil {
+		ctx = context.WithValue(ctx, oauth2.HTTPClient, o.httpClient)
+	}
 
-	if o.token != "" && currentTime.Before(o.tokenExpiration.Add(time.Duration(-2)*time.Second)) {
+	if o.token != "" && currentTime.Before(o.tokenExpiration.Add(-(o.refreshSkew + o.jitter))) {
 		accessToken = o.token
 		err = nil
 	} else {
@@ -82,116 +186,245 @@ func (o *oauthbearerTokenProvider) Token() (*sarama.AccessToken, error) {
 			accessToken = token.AccessToken
 			o.token = token.AccessToken
 			o.tokenExpiration = token.Expiry
+			o.jitter = 0
+			if o.refreshJitter > 0 {
+				o.jitter = time.Duration(rand.Int63n(int64(o.refreshJitter)))
+			}
 		}
 	}
 
-	return &sarama.AccessToken{Token: accessToken}, err
+	return &sarama.AccessToken{Token: accessToken, Extensions: o.extensions}, err
 }
 
+// Token generates a new MSK auth token. The aws-iam mechanism goes through
+// an awsIAMTokenSource instead, which reuses each token until it expires.
 func (c *Config) Token() (*sarama.AccessToken, error) {
+	token, _, err := c.awsAuthToken(context.TODO())
+	return &sarama.AccessToken{Token: token, Extensions: c.SASLOAuthExtensions}, err
+}
+
+// awsAuthToken generates an MSK auth token, returning it with its expiry in
+// milliseconds since the epoch
+func (c *Config) awsAuthToken(ctx context.Context) (string, int64, error) {
+	if c.SASLAWSWebIdentityTokenFile != "" && c.SASLAWSExternalId != "" {
+		return "", 0, fmt.Errorf("sasl_aws_external_id cannot be used with sasl_aws_web_identity_token_file, as roles are assumed with a web identity without an external ID")
+	}
+
 	signer.AwsDebugCreds = c.SASLAWSCredsDebug
+	region := c.awsRegion()
 	var token string
+	var expiry int64
 	var err error
 
-	if c.SASLAWSContainerAuthorizationTokenFile != "" && c.SASLAWSContainerCredentialsFullUri != "" {
-		log.Printf("[INFO] Generating auth token using container credentials in '%s'", c.SASLAWSRegion)
-		var containerAuthorizationToken []byte
-		containerAuthorizationToken, err = os.ReadFile(c.SASLAWSContainerAuthorizationTokenFile)
+	if len(c.SASLAWSRoleArnChain) > 0 {
+		var credProvider aws.CredentialsProvider
+		credProvider, err = c.awsRoleChainCredentials(ctx, region)
 		if err != nil {
-			return nil, fmt.Errorf("failed to read authorization token file: %w", err)
+			return "", 0, err
 		}
-		tokenOpt := func(o *endpointcreds.Options) {
-			o.AuthorizationToken = string(containerAuthorizationToken)
+		log.Printf("[INFO] Generating auth token with the role chain in '%s'", region)
+		token, expiry, err = signer.GenerateAuthTokenFromCredentialsProvider(ctx, region, credProvider)
+	} else if c.SASLAWSContainerAuthorizationTokenFile != "" && c.SASLAWSContainerCredentialsFullUri != "" {
+		log.Printf("[INFO] Generating auth token using container credentials in '%s'", region)
+		var credProvider aws.CredentialsProvider
+		credProvider, err = c.awsContainerCredentials()
+		if err != nil {
+			return "", 0, err
 		}
-		credProvider := endpointcreds.New(c.SASLAWSContainerCredentialsFullUri, tokenOpt)
-		token, _, err = signer.GenerateAuthTokenFromCredentialsProvider(context.TODO(), c.SASLAWSRegion, credProvider)
+		token, expiry, err = signer.GenerateAuthTokenFromCredentialsProvider(ctx, region, credProvider)
+	} else if c.SASLAWSWebIdentityTokenFile != "" && c.SASLAWSRoleArn != "" {
+		log.Printf("[INFO] Generating auth token using web identity token file '%s' with a role '%s' in '%s'", c.SASLAWSWebIdentityTokenFile, c.SASLAWSRoleArn, region)
+		token, expiry, err = signer.GenerateAuthTokenFromCredentialsProvider(ctx, region, c.awsWebIdentityCredentials(region))
 	} else if c.SASLAWSRoleArn != "" {
-		log.Printf("[INFO] Generating auth token with a role '%s' in '%s'", c.SASLAWSRoleArn, c.SASLAWSRegion)
-		token, _, err = signer.GenerateAuthTokenFromRoleWithExternalId(context.TODO(), c.SASLAWSRegion, c.SASLAWSRoleArn, "terraform-kafka-provider", c.SASLAWSExternalId)
+		log.Printf("[INFO] Generating auth token with a role '%s' in '%s'", c.SASLAWSRoleArn, region)
+		token, expiry, err = signer.GenerateAuthTokenFromRoleWithExternalId(ctx, region, c.SASLAWSRoleArn, awsSessionName, c.SASLAWSExternalId)
 	} else if c.SASLAWSProfile != "" {
 		if c.SASLAWSSharedConfigFiles != nil && len(*c.SASLAWSSharedConfigFiles) > 0 {
-			log.Printf("[INFO] Generating auth token using profile '%s', shared config files '%s' in '%s'", c.SASLAWSProfile, strings.Join(*c.SASLAWSSharedConfigFiles, ","), c.SASLAWSRegion)
-			token, _, err = signer.GenerateAuthTokenFromProfileWithSharedConfigFiles(context.TODO(), c.SASLAWSRegion, c.SASLAWSProfile, *c.SASLAWSSharedConfigFiles)
+			log.Printf("[INFO] Generating auth token using profile '%s', shared config files '%s' in '%s'", c.SASLAWSProfile, strings.Join(*c.SASLAWSSharedConfigFiles, ","), region)
+			token, expiry, err = signer.GenerateAuthTokenFromProfileWithSharedConfigFiles(ctx, region, c.SASLAWSProfile, *c.SASLAWSSharedConfigFiles)
 		} else {
-			log.Printf("[INFO] Generating auth token using profile '%s' in '%s'", c.SASLAWSProfile, c.SASLAWSRegion)
-			token, _, err = signer.GenerateAuthTokenFromProfile(context.TODO(), c.SASLAWSRegion, c.SASLAWSProfile)
+			log.Printf("[INFO] Generating auth token using profile '%s' in '%s'", c.SASLAWSProfile, region)
+			token, expiry, err = signer.GenerateAuthTokenFromProfile(ctx, region, c.SASLAWSProfile)
 		}
 	} else if c.SASLAWSAccessKey != "" && c.SASLAWSSecretKey != "" {
-		log.Printf("[INFO] Generating auth token using static credentials in '%s'", c.SASLAWSRegion)
-		token, _, err = signer.GenerateAuthTokenFromCredentialsProvider(context.TODO(), c.SASLAWSRegion, credentials.NewStaticCredentialsProvider(c.SASLAWSAccessKey, c.SASLAWSSecretKey, c.SASLAWSToken))
+		log.Printf("[INFO] Generating auth token using static credentials in '%s'", region)
+		token, expiry, err = signer.GenerateAuthTokenFromCredentialsProvider(ctx, region, credentials.NewStaticCredentialsProvider(c.SASLAWSAccessKey, c.SASLAWSSecretKey, c.SASLAWSToken))
 	} else {
-		log.Printf("[INFO] Generating auth token in '%s'", c.SASLAWSRegion)
-		token, _, err = signer.GenerateAuthToken(context.TODO(), c.SASLAWSRegion)
+		log.Printf("[INFO] Generating auth token in '%s'", region)
+		token, expiry, err = signer.GenerateAuthToken(ctx, region)
+	}
+	return token, expiry, err
+}
+
+// awsIAMTokenSource generates MSK auth tokens for the aws-iam mechanism, so
+// that the oauthbearerTokenProvider caches them like oauth tokens
+type awsIAMTokenSource struct {
+	config *Config
+}
+
+func (a *awsIAMTokenSource) Token(ctx context.Context) (*oauth2.Token, error) {
+	token, expiry, err := a.config.awsAuthToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &oauth2.Token{AccessToken: token, Expiry: time.UnixMilli(expiry)}, nil
+}
+
+// awsRegion returns the region of the MSK cluster, falling back to the
+// AWS_REGION environment variable
+func (c *Config) awsRegion() string {
+	if c.SASLAWSRegion != "" {
+		return c.SASLAWSRegion
 	}
-	return &sarama.AccessToken{Token: token}, err
+	return os.Getenv("AWS_REGION")
+}
+
+// kafkaVersion returns the parsed kafka_version, 2.7.0 if it is not set. It
+// is 2.7.0 for auto too, until the client connects and detects the version.
+func (c *Config) kafkaVersion() (sarama.KafkaVersion, error) {
+	if c.KafkaVersion == "" || c.KafkaVersion == kafkaVersionAuto {
+		return sarama.V2_7_0_0, nil
+	}
+	version, err := sarama.ParseKafkaVersion(c.KafkaVersion)
+	if err != nil {
+		return version, fmt.Errorf("error parsing kafka version '%s': %w", c.KafkaVersion, err)
+	}
+	return version, nil
 }
 
 func (c *Config) newKafkaConfig() (*sarama.Config, error) {
 	kafkaConfig := sarama.NewConfig()
 
-	if c.KafkaVersion != "" {
-		version, err := sarama.ParseKafkaVersion(c.KafkaVersion)
-		if err != nil {
-			return kafkaConfig, fmt.Errorf("error parsing kafka version '%s': %w", c.KafkaVersion, err)
-		}
-		kafkaConfig.Version = version
-	} else {
-		kafkaConfig.Version = sarama.V2_7_0_0
+	version, err := c.kafkaVersion()
+	if err != nil {
+		return kafkaConfig, err
 	}
+	kafkaConfig.Version = version
 
-	kafkaConfig.ClientID = "terraform-provider-kafka"
+	kafkaConfig.ClientID = defaultClientID
+	if c.ClientID != "" {
+		kafkaConfig.ClientID = c.ClientID
+	}
 	kafkaConfig.Admin.Timeout = time.Duration(c.Timeout) * time.Second
-	kafkaConfig.Metadata.Full = true // the default, but just being clear
-	kafkaConfig.Metadata.AllowAutoTopicCreation = false
+	// sarama retries admin requests failing with a controller error itself;
+	// the provider's own retry policy wraps these. 0 disables them, nil keeps
+	// sarama's default
+	if c.AdminRetryMax != nil {
+		kafkaConfig.Admin.Retry.Max = *c.AdminRetryMax
+	}
+	if c.AdminRetryBackoff > 0 {
+		kafkaConfig.Admin.Retry.Backoff = time.Duration(c.AdminRetryBackoff) * time.Millisecond
+	}
+	// with full metadata disabled, only the topics used so far are fetched,
+	// rather than every topic of the cluster on connect and on each refresh.
+	// nil keeps sarama's default of fetching everything
+	if c.MetadataFull != nil {
+		kafkaConfig.Metadata.Full = *c.MetadataFull
+	}
+	// metadata requests for unknown topics create them only where the
+	// brokers auto-create topics and it is explicitly allowed
+	kafkaConfig.Metadata.AllowAutoTopicCreation = c.AllowAutoTopicCreation
+	if c.MetadataRefreshFrequency > 0 {
+		kafkaConfig.Metadata.RefreshFrequency = time.Duration(c.MetadataRefreshFrequency) * time.Second
+	}
+	kafkaConfig.Net.ResolveCanonicalBootstrapServers = c.ResolveCanonicalBootstrapServers
 
-	kafkaConfig.Net.Proxy.Enable = true
-	kafkaConfig.Net.Proxy.Dialer = proxy.FromEnvironment()
+	kafkaConfig.Net.DialTimeout = defaultDialTimeout
+	if c.DialTimeout > 0 {
+		kafkaConfig.Net.DialTimeout = time.Duration(c.DialTimeout) * time.Second
+	}
+	kafkaConfig.Net.ReadTimeout = c.timeoutOrDefault(c.ReadTimeout)
+	kafkaConfig.Net.WriteTimeout = c.timeoutOrDefault(c.WriteTimeout)
+	kafkaConfig.Metadata.Timeout = c.timeoutOrDefault(c.MetadataTimeout)
+	// sarama leaves keep-alives to the OS unless told otherwise, which some
+	// firewalls drop idle connections well before
+	if c.KeepAlive > 0 {
+		kafkaConfig.Net.KeepAlive = time.Duration(c.KeepAlive) * time.Second
+	}
+	if c.MaxOpenRequests > 0 {
+		kafkaConfig.Net.MaxOpenRequests = c.MaxOpenRequests
+	}
 
-	kafkaConfig.Net.ReadTimeout = time.Duration(c.Timeout) * time.Second
-	kafkaConfig.Net.WriteTimeout = time.Duration(c.Timeout) * time.Second
-	kafkaConfig.Metadata.Timeout = time.Duration(c.Timeout) * time.Second
+	// Kafka connections only go through a proxy or SSH tunnel when asked to;
+	// the proxy environment variables are left to the oauth token client, as
+	// an HTTP proxy cannot carry the Kafka protocol
+	dialer, err := c.dialer(kafkaConfig.Net.DialTimeout)
+	if err != nil {
+		return kafkaConfig, err
+	}
+	if dialer != nil {
+		kafkaConfig.Net.Proxy.Enable = true
+		kafkaConfig.Net.Proxy.Dialer = dialer
+	}
+
+	if c.SASLTokenAuth && c.SASLMechanism != "scram-sha256" && c.SASLMechanism != "scram-sha512" {
+		return kafkaConfig, fmt.Errorf("sasl_token_auth requires the scram-sha256 or scram-sha512 sasl mechanism, got %q", c.SASLMechanism)
+	}
+
+	if err := validateOAuthExtensions(c.SASLOAuthExtensions); err != nil {
+		return kafkaConfig, err
+	}
+	if len(c.SASLAWSRoleArnChain) > 0 && c.SASLMechanism != "aws-iam" {
+		return kafkaConfig, fmt.Errorf("sasl_aws_role_chain requires the aws-iam sasl mechanism, got %q", c.SASLMechanism)
+	}
+	if len(c.SASLOAuthExtensions) > 0 && c.SASLMechanism != "oauthbearer" && c.SASLMechanism != "aws-iam" {
+		return kafkaConfig, fmt.Errorf("sasl_oauth_extensions requires the oauthbearer or aws-iam sasl mechanism, got %q", c.SASLMechanism)
+	}
+	if c.SASLAuthzID != "" && c.SASLMechanism != "plain" && c.SASLMechanism != "scram-sha256" && c.SASLMechanism != "scram-sha512" {
+		return kafkaConfig, fmt.Errorf("sasl_authz_id requires the plain, scram-sha256 or scram-sha512 sasl mechanism, got %q", c.SASLMechanism)
+	}
+	if c.SASLVersion != nil && *c.SASLVersion != int(sarama.SASLHandshakeV0) && *c.SASLVersion != int(sarama.SASLHandshakeV1) {
+		return kafkaConfig, fmt.Errorf("invalid sasl_version %d: can only be 0 or 1", *c.SASLVersion)
+	}
+	if c.SASLDisableHandshake && c.SASLVersion != nil && *c.SASLVersion != int(sarama.SASLHandshakeV0) {
+		return kafkaConfig, fmt.Errorf("sasl_version %d requires sasl_handshake, only 0 can be used without it", *c.SASLVersion)
+	}
 
 	if c.saslEnabled() {
 		switch c.SASLMechanism {
 		case "scram-sha512":
-			kafkaConfig.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient { return &XDGSCRAMClient{HashGeneratorFcn: SHA512} }
+			kafkaConfig.Net.SASL.SCRAMClientGeneratorFunc = c.scramClientGenerator(SHA512)
 			kafkaConfig.Net.SASL.Mechanism = sarama.SASLMechanism(sarama.SASLTypeSCRAMSHA512)
 		case "scram-sha256":
-			kafkaConfig.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient { return &XDGSCRAMClient{HashGeneratorFcn: SHA256} }
+			kafkaConfig.Net.SASL.SCRAMClientGeneratorFunc = c.scramClientGenerator(SHA256)
 			kafkaConfig.Net.SASL.Mechanism = sarama.SASLMechanism(sarama.SASLTypeSCRAMSHA256)
 		case "aws-iam":
 			kafkaConfig.Net.SASL.Mechanism = sarama.SASLMechanism(sarama.SASLTypeOAuth)
-			region := c.SASLAWSRegion
-			if region == "" {
-				region = os.Getenv("AWS_REGION")
+			if c.awsRegion() == "" {
+				return kafkaConfig, fmt.Errorf("aws region must be configured or AWS_REGION environment variable must be set to use aws-iam sasl mechanism")
 			}
-			if region == "" {
-				log.Fatalf("[ERROR] aws region must be configured or AWS_REGION environment variable must be set to use aws-iam sasl mechanism")
-			}
-			kafkaConfig.Net.SASL.TokenProvider = c
+			tokenProvider := newOauthbearerTokenProvider(&awsIAMTokenSource{config: c}, nil, awsIAMTokenRefreshSkew, 0)
+			tokenProvider.extensions = c.SASLOAuthExtensions
+			kafkaConfig.Net.SASL.TokenProvider = tokenProvider
 		case "oauthbearer":
 			kafkaConfig.Net.SASL.Mechanism = sarama.SASLMechanism(sarama.SASLTypeOAuth)
-			tokenUrl := c.SASLTokenUrl
-			if tokenUrl == "" {
-				tokenUrl = os.Getenv("TOKEN_URL")
-			}
-			if tokenUrl == "" {
-				log.Fatalf("[ERROR] token url must be configured or TOKEN_URL environment variable must be set to use oauthbearer sasl mechanism")
+			oauth2Config, httpClient, err := c.oauthTokenSource()
+			if err != nil {
+				return kafkaConfig, err
 			}
-			oauth2Config := clientcredentials.Config{
-				TokenURL:     tokenUrl,
-				ClientID:     c.SASLUsername,
-				ClientSecret: c.SASLPassword,
-				Scopes:       c.SASLOAuthScopes,
+			refreshSkew := defaultOAuthRefreshSkew
+			if c.SASLOAuthRefreshSkewSeconds > 0 {
+				refreshSkew = time.Duration(c.SASLOAuthRefreshSkewSeconds) * time.Second
 			}
-			kafkaConfig.Net.SASL.TokenProvider = newOauthbearerTokenProvider(&oauth2Config)
+			refreshJitter := time.Duration(c.SASLOAuthRefreshJitterSeconds) * time.Second
+			tokenProvider := newOauthbearerTokenProvider(oauth2Config, httpClient, refreshSkew, refreshJitter)
+			tokenProvider.extensions = c.SASLOAuthExtensions
+			kafkaConfig.Net.SASL.TokenProvider = tokenProvider
 		case "plain":
 		default:
-			log.Fatalf("[ERROR] Invalid sasl mechanism \"%s\": can only be \"scram-sha256\", \"scram-sha512\", \"aws-iam\" or \"plain\"", c.SASLMechanism)
+			return kafkaConfig, fmt.Errorf("invalid sasl mechanism \"%s\": can only be \"scram-sha256\", \"scram-sha512\", \"aws-iam\", \"oauthbearer\" or \"plain\"", c.SASLMechanism)
 		}
 
 		kafkaConfig.Net.SASL.Enable = true
-		kafkaConfig.Net.SASL.Handshake = true
+		kafkaConfig.Net.SASL.Handshake = !c.SASLDisableHandshake
+		if c.SASLVersion != nil {
+			kafkaConfig.Net.SASL.Version = int16(*c.SASLVersion)
+		} else if c.SASLDisableHandshake {
+			// brokers only accept a SaslAuthenticate after a handshake, so
+			// without one the PLAIN token is sent as it is, as legacy brokers
+			// expect it
+			kafkaConfig.Net.SASL.Version = sarama.SASLHandshakeV0
+		}
 
 		if c.SASLUsername != "" {
 			kafkaConfig.Net.SASL.User = c.SASLUsername
@@ -199,43 +432,361 @@ func (c *Config) newKafkaConfig() (*sarama.Config, error) {
 		if c.SASLPassword != "" {
 			kafkaConfig.Net.SASL.Password = c.SASLPassword
 		}
+		// sarama sends the authzid of PLAIN and SCRAM from separate fields
+		kafkaConfig.Net.SASL.AuthIdentity = c.SASLAuthzID
+		kafkaConfig.Net.SASL.SCRAMAuthzID = c.SASLAuthzID
 	} else {
 		log.Printf("[WARN] SASL disabled username: '%s', password '%s'", c.SASLUsername, "****")
 	}
 
 	if c.TLSEnabled {
-		tlsConfig, err := newTLSConfig(
-			c.ClientCert,
-			c.ClientCertKey,
-			c.CACert,
-			c.ClientCertKeyPassphrase,
-		)
+		tlsConfig, err := c.tlsConfig()
 		if err != nil {
 			return kafkaConfig, err
 		}
 
 		kafkaConfig.Net.TLS.Enable = true
 		kafkaConfig.Net.TLS.Config = tlsConfig
-		kafkaConfig.Net.TLS.Config.InsecureSkipVerify = c.SkipTLSVerify
 	}
 
 	return kafkaConfig, nil
 }
 
+// tlsConfig builds the TLS config used for the brokers and the oauth token
+// endpoint
+func (c *Config) tlsConfig() (*tls.Config, error) {
+	minVersion, err := parseTLSVersion(c.TLSMinVersion, tls.VersionTLS12)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tls_min_version: %w", err)
+	}
+	maxVersion, err := parseTLSVersion(c.TLSMaxVersion, 0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tls_max_version: %w", err)
+	}
+	if maxVersion != 0 && maxVersion < minVersion {
+		return nil, fmt.Errorf("tls_max_version %s is lower than tls_min_version %s", c.TLSMaxVersion, tlsVersionName(minVersion))
+	}
+	cipherSuites, err := parseCipherSuites(c.TLSCipherSuites)
+	if err != nil {
+		return nil, err
+	}
+	if len(cipherSuites) > 0 && minVersion >= tls.VersionTLS13 {
+		// Go does not allow configuring the TLS 1.3 cipher suites
+		log.Printf("[WARN] tls_cipher_suites has no effect as tls_min_version is 1.3")
+		cipherSuites = nil
+	}
+
+	passphrase, err := c.clientKeyPassphrase()
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig, err := newTLSConfig(
+		c.ClientCert,
+		c.ClientCertKey,
+		append([]string{c.CACert}, c.CACerts...),
+		passphrase,
+		c.ClientCertFormat,
+		c.TLSExclusiveCA,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig.MinVersion = minVersion
+	tlsConfig.MaxVersion = maxVersion
+	tlsConfig.CipherSuites = cipherSuites
+	tlsConfig.ServerName = c.TLSServerName
+	tlsConfig.InsecureSkipVerify = c.SkipTLSVerify
+	return tlsConfig, nil
+}
+
+// clientKeyPassphrase returns client_key_passphrase, or else the content of
+// client_key_passphrase_file without its trailing newline
+func (c *Config) clientKeyPassphrase() (string, error) {
+	if c.ClientCertKeyPassphrase != "" || c.ClientCertKeyPassphraseFile == "" {
+		return c.ClientCertKeyPassphrase, nil
+	}
+	passphrase, err := os.ReadFile(c.ClientCertKeyPassphraseFile)
+	if err != nil {
+		return "", fmt.Errorf("unable to read client_key_passphrase_file: %w", err)
+	}
+	return strings.TrimRight(string(passphrase), "\r\n"), nil
+}
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// parseTLSVersion parses a version such as "1.2", returning def if v is empty
+func parseTLSVersion(v string, def uint16) (uint16, error) {
+	if v == "" {
+		return def, nil
+	}
+	version, ok := tlsVersions[v]
+	if !ok {
+		return 0, fmt.Errorf("unknown TLS version %q, must be one of 1.0, 1.1, 1.2 or 1.3", v)
+	}
+	return version, nil
+}
+
+// parseCipherSuites maps cipher suite names such as
+// TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384 to their IDs. They only apply to TLS
+// 1.2 and lower, so TLS 1.3 suites are ignored.
+func parseCipherSuites(names []string) ([]uint16, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	known := map[string]*tls.CipherSuite{}
+	for _, cs := range tls.CipherSuites() {
+		known[cs.Name] = cs
+	}
+	for _, cs := range tls.InsecureCipherSuites() {
+		known[cs.Name] = cs
+	}
+
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		cs, ok := known[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown cipher suite %q in tls_cipher_suites", name)
+		}
+		if cs.Insecure {
+			log.Printf("[WARN] tls_cipher_suites includes the insecure cipher suite %s", name)
+		}
+		if !supportsPreTLS13(cs) {
+			log.Printf("[WARN] Ignoring TLS 1.3 cipher suite %s in tls_cipher_suites, TLS 1.3 cipher suites cannot be configured", name)
+			continue
+		}
+		ids = append(ids, cs.ID)
+	}
+
+	return ids, nil
+}
+
+func supportsPreTLS13(cs *tls.CipherSuite) bool {
+	for _, v := range cs.SupportedVersions {
+		if v < tls.VersionTLS13 {
+			return true
+		}
+	}
+	return false
+}
+
+func tlsVersionName(version uint16) string {
+	for name, v := range tlsVersions {
+		if v == version {
+			return name
+		}
+	}
+	return fmt.Sprintf("0x%04x", version)
+}
+
+// oauthTokenSource returns where the oauthbearer mechanism gets its tokens
+// from, and the http client for the token endpoint if it needs one
+func (c *Config) oauthTokenSource() (OAuth2Config, *http.Client, error) {
+	switch c.SASLOAuthTokenSource {
+	case "", oauthTokenSourceClientCredentials:
+	case oauthTokenSourceFile:
+		if c.SASLOAuthTokenFile == "" {
+			return nil, nil, fmt.Errorf("sasl_oauth_token_file must be configured to use the %q oauth token source", oauthTokenSourceFile)
+		}
+		return &fileTokenSource{path: c.SASLOAuthTokenFile}, nil, nil
+	case oauthTokenSourceExec:
+		if len(c.SASLOAuthTokenCommand) == 0 {
+			return nil, nil, fmt.Errorf("sasl_oauth_token_command must be configured to use the %q oauth token source", oauthTokenSourceExec)
+		}
+		return &execTokenSource{command: c.SASLOAuthTokenCommand}, nil, nil
+	case oauthTokenSourceAzureMSI:
+		resource, err := c.azureMSIResource()
+		if err != nil {
+			return nil, nil, err
+		}
+		clientID := c.SASLOAuthAzureClientID
+		if clientID == "" {
+			clientID = os.Getenv("AZURE_CLIENT_ID")
+		}
+		return newAzureMSITokenSource(resource, clientID), nil, nil
+	case oauthTokenSourceGCP:
+		scopes := c.SASLOAuthScopes
+		if len(scopes) == 0 {
+			scopes = []string{gcpDefaultScope}
+		}
+		return newGCPTokenSource(scopes), nil, nil
+	default:
+		return nil, nil, fmt.Errorf("invalid sasl_oauth_token_source %q: can only be %s", c.SASLOAuthTokenSource, strings.Join(oauthTokenSources, ", "))
+	}
+
+	tokenUrl := c.SASLTokenUrl
+	if tokenUrl == "" {
+		tokenUrl = os.Getenv("TOKEN_URL")
+	}
+	if tokenUrl == "" {
+		return nil, nil, fmt.Errorf("token url must be configured or TOKEN_URL environment variable must be set to use oauthbearer sasl mechanism")
+	}
+	oauth2Config := &clientcredentials.Config{
+		TokenURL:     tokenUrl,
+		ClientID:     c.SASLUsername,
+		ClientSecret: c.SASLPassword,
+		Scopes:       c.SASLOAuthScopes,
+	}
+
+	var httpClient *http.Client
+	if c.SASLOAuthClientCertEnabled {
+		var err error
+		httpClient, err = c.newOAuthHTTPClient()
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return oauth2Config, httpClient, nil
+}
+
+var (
+	oauthExtensionKeyRegexp   = regexp.MustCompile(`^[A-Za-z]+$`)
+	oauthExtensionValueRegexp = regexp.MustCompile(`^[\x21-\x7E \t\r\n]+$`)
+)
+
+// validateOAuthExtensions checks the SASL extensions against RFC 7628, as
+// brokers reject the whole authentication for a single malformed one
+func validateOAuthExtensions(extensions map[string]string) error {
+	for k, v := range extensions {
+		if k == sarama.SASLExtKeyAuth {
+			return fmt.Errorf("sasl_oauth_extensions cannot set the reserved %q extension", k)
+		}
+		if !oauthExtensionKeyRegexp.MatchString(k) {
+			return fmt.Errorf("sasl_oauth_extensions key %q must only contain letters", k)
+		}
+		if !oauthExtensionValueRegexp.MatchString(v) {
+			return fmt.Errorf("sasl_oauth_extensions value of %q must be non-empty printable ASCII", k)
+		}
+	}
+	return nil
+}
+
+// newOAuthHTTPClient builds an http client that presents the configured client
+// certificate to the oauth token endpoint
+func (c *Config) newOAuthHTTPClient() (*http.Client, error) {
+	if c.ClientCert == "" || (c.ClientCertKey == "" && c.ClientCertFormat != clientCertFormatPKCS12) {
+		return nil, fmt.Errorf("client_cert and client_key must be configured to use sasl_oauth_client_cert_enabled")
+	}
+
+	// the token endpoint is not a broker, so only the CAs and the client
+	// certificate are shared with the broker connections, not e.g.
+	// tls_server_name; public identity providers also need the system's CAs,
+	// so tls_exclusive_ca is left to the brokers
+	passphrase, err := c.clientKeyPassphrase()
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig, err := newTLSConfig(
+		c.ClientCert,
+		c.ClientCertKey,
+		append([]string{c.CACert}, c.CACerts...),
+		passphrase,
+		c.ClientCertFormat,
+		false,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   time.Duration(c.Timeout) * time.Second,
+	}, nil
+}
+
+// newProxyDialer returns a dialer connecting through the SOCKS5 proxy at
+// proxyURL, e.g. socks5://proxy:1080. The username and password take
+// precedence over any given in the URL.
+func newProxyDialer(proxyURL, username, password string, timeout time.Duration) (proxy.Dialer, error) {
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy_url: %w", err)
+	}
+	if u.Scheme != "socks5" && u.Scheme != "socks5h" {
+		return nil, fmt.Errorf("invalid proxy_url %q: only socks5 and socks5h proxies can carry Kafka connections", u.Redacted())
+	}
+
+	if password != "" && username == "" {
+		return nil, fmt.Errorf("proxy_password requires proxy_username to be set")
+	}
+
+	var auth *proxy.Auth
+	if u.User != nil {
+		auth = &proxy.Auth{User: u.User.Username()}
+		auth.Password, _ = u.User.Password()
+	}
+	if username != "" {
+		auth = &proxy.Auth{User: username, Password: password}
+	}
+
+	return proxy.SOCKS5("tcp", u.Host, auth, &net.Dialer{Timeout: timeout})
+}
+
+// redactedURL replaces the password of rawURL, if any, with "xxxxx"
+func redactedURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "*****"
+	}
+	return u.Redacted()
+}
+
+// scramClientGenerator returns a generator for SCRAM clients using hashGen,
+// authenticating with a delegation token when SASLTokenAuth is set
+func (c *Config) scramClientGenerator(hashGen scram.HashGeneratorFcn) func() sarama.SCRAMClient {
+	if c.SASLTokenAuth {
+		return func() sarama.SCRAMClient { return &TokenSCRAMClient{HashGeneratorFcn: hashGen} }
+	}
+	return func() sarama.SCRAMClient { return &XDGSCRAMClient{HashGeneratorFcn: hashGen} }
+}
+
+// timeoutOrDefault returns override as a duration in seconds, falling back to
+// the shared Timeout when override is not set
+func (c *Config) timeoutOrDefault(override int) time.Duration {
+	if override > 0 {
+		return time.Duration(override) * time.Second
+	}
+	return time.Duration(c.Timeout) * time.Second
+}
+
 func (c *Config) saslEnabled() bool {
+	if c.SASLMechanism == "oauthbearer" && c.SASLOAuthTokenSource != "" && c.SASLOAuthTokenSource != oauthTokenSourceClientCredentials {
+		// the token comes from elsewhere, without a client id and secret
+		return true
+	}
 	return c.SASLUsername != "" || c.SASLPassword != "" || c.SASLMechanism == "aws-iam"
 }
 
 func NewTLSConfig(clientCert, clientKey, caCert, clientKeyPassphrase string) (*tls.Config, error) {
-	return newTLSConfig(clientCert, clientKey, caCert, clientKeyPassphrase)
+	return newTLSConfig(clientCert, clientKey, []string{caCert}, clientKeyPassphrase, clientCertFormatPEM, false)
 }
 
 func parsePemOrLoadFromFile(input string) (*pem.Block, []byte, error) {
+	blocks, inputBytes, err := parsePemBlocksOrLoadFromFile(input)
+	if err != nil {
+		return nil, nil, err
+	}
+	return blocks[0], inputBytes, nil
+}
+
+// parsePemBlocksOrLoadFromFile decodes every PEM block of input, or of the
+// file named by input if it is not PEM itself
+func parsePemBlocksOrLoadFromFile(input string) ([]*pem.Block, []byte, error) {
 	// attempt to parse
 	inputBytes := []byte(input)
-	inputBlock, _ := pem.Decode(inputBytes)
+	blocks := decodePemBlocks(inputBytes)
 
-	if inputBlock == nil {
+	if len(blocks) == 0 {
 		// attempt to load from file
 		log.Printf("[INFO] Attempting to load from file '%s'", input)
 		var err error
@@ -243,18 +794,116 @@ func parsePemOrLoadFromFile(input string) (*pem.Block, []byte, error) {
 		if err != nil {
 			return nil, nil, err
 		}
-		inputBlock, _ = pem.Decode(inputBytes)
-		if inputBlock == nil {
+		blocks = decodePemBlocks(inputBytes)
+		if len(blocks) == 0 {
 			return nil, nil, fmt.Errorf("[ERROR] Error unable to decode pem")
 		}
 	}
-	return inputBlock, inputBytes, nil
+	return blocks, inputBytes, nil
+}
+
+func decodePemBlocks(data []byte) []*pem.Block {
+	var blocks []*pem.Block
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return blocks
+		}
+		blocks = append(blocks, block)
+	}
+}
+
+// loadPKCS12Certificate reads the certificate and private key from a PKCS#12
+// bundle, given either as a path to a .p12/.pfx file or as base64 encoded
+// content
+func loadPKCS12Certificate(input, passphrase string) (tls.Certificate, error) {
+	data, err := os.ReadFile(input)
+	if err != nil {
+		var decodeErr error
+		data, decodeErr = base64.StdEncoding.DecodeString(input)
+		if decodeErr != nil {
+			return tls.Certificate{}, fmt.Errorf("client_cert is neither a readable file nor base64 encoded: %w", err)
+		}
+	}
+
+	if block, _ := pem.Decode(data); block != nil {
+		return tls.Certificate{}, fmt.Errorf("client_cert is PEM encoded; set client_cert_format to %q", clientCertFormatPEM)
+	}
+
+	key, cert, err := pkcs12.Decode(data, passphrase) //nolint:staticcheck
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("error decoding PKCS#12 bundle: %w", err)
+	}
+
+	return tls.Certificate{
+		Certificate: [][]byte{cert.Raw},
+		PrivateKey:  key,
+		Leaf:        cert,
+	}, nil
+}
+
+func isEncryptedKeyBlock(block *pem.Block) bool {
+	return block.Type == "ENCRYPTED PRIVATE KEY" || x509.IsEncryptedPEMBlock(block) //nolint:staticcheck
+}
+
+// decryptKeyBlock decrypts an encrypted PKCS#8 key, or a key using the legacy
+// PEM encryption of OpenSSL's "Proc-Type: 4,ENCRYPTED" header, returning the
+// decrypted key PEM encoded
+func decryptKeyBlock(block *pem.Block, passphrase string) ([]byte, error) {
+	if passphrase == "" {
+		return nil, fmt.Errorf("the private key is encrypted, but no client_key_passphrase is set")
+	}
+
+	if block.Type == "ENCRYPTED PRIVATE KEY" {
+		der, err := decryptPKCS8PrivateKey(block.Bytes, []byte(passphrase))
+		if err != nil {
+			return nil, err
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+	}
+
+	// the legacy encryption is deprecated as it cannot reliably detect a wrong
+	// passphrase, hence the check that the result parses, but it is still
+	// written by `openssl genrsa -des3`
+	der, err := x509.DecryptPEMBlock(block, []byte(passphrase)) //nolint:staticcheck
+	if errors.Is(err, x509.IncorrectPasswordError) {
+		return nil, errIncorrectPassphrase
+	}
+	if err != nil {
+		return nil, UnsupportedKeyFormatError{msg: err.Error()}
+	}
+	if !isPrivateKeyDER(der) {
+		return nil, errIncorrectPassphrase
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: block.Type, Bytes: der}), nil
 }
 
-func newTLSConfig(clientCert, clientKey, caCert, clientKeyPassphrase string) (*tls.Config, error) {
+func isPrivateKeyDER(der []byte) bool {
+	if _, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return true
+	}
+	if _, err := x509.ParseECPrivateKey(der); err == nil {
+		return true
+	}
+	_, err := x509.ParsePKCS8PrivateKey(der)
+	return err == nil
+}
+
+// newTLSConfig trusts the given CAs on top of the system's, or only them when
+// exclusiveCA is set
+func newTLSConfig(clientCert, clientKey string, caCerts []string, clientKeyPassphrase, clientCertFormat string, exclusiveCA bool) (*tls.Config, error) {
 	tlsConfig := tls.Config{}
 
-	if clientCert != "" && clientKey != "" {
+	if clientCert != "" && clientCertFormat == clientCertFormatPKCS12 {
+		log.Printf("[INFO] Using PKCS#12 client certificate")
+		cert, err := loadPKCS12Certificate(clientCert, clientKeyPassphrase)
+		if err != nil {
+			log.Printf("[ERROR] Unable to read PKCS#12 certificate %s", err)
+			return &tlsConfig, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	} else if clientCert != "" && clientKey != "" {
 		_, certBytes, err := parsePemOrLoadFromFile(clientCert)
 		if err != nil {
 			log.Printf("[ERROR] Unable to read certificate %s", err)
@@ -267,19 +916,13 @@ func newTLSConfig(clientCert, clientKey, caCert, clientKeyPassphrase string) (*t
 			return &tlsConfig, err
 		}
 
-		if x509.IsEncryptedPEMBlock(keyBlock) { //nolint:staticcheck
+		if isEncryptedKeyBlock(keyBlock) {
 			log.Printf("[INFO] Using encrypted private key")
-			var err error
-
-			keyBytes, err = x509.DecryptPEMBlock(keyBlock, []byte(clientKeyPassphrase)) //nolint:staticcheck
+			keyBytes, err = decryptKeyBlock(keyBlock, clientKeyPassphrase)
 			if err != nil {
 				log.Printf("[ERROR] Error decrypting private key with passphrase %s", err)
 				return &tlsConfig, err
 			}
-			keyBytes = pem.EncodeToMemory(&pem.Block{
-				Type:  keyBlock.Type,
-				Bytes: keyBytes,
-			})
 		}
 
 		cert, err := tls.X509KeyPair(certBytes, keyBytes)
@@ -290,58 +933,104 @@ func newTLSConfig(clientCert, clientKey, caCert, clientKeyPassphrase string) (*t
 		tlsConfig.Certificates = []tls.Certificate{cert}
 	}
 
-	if caCert == "" {
+	nonEmpty := make([]string, 0, len(caCerts))
+	for _, caCert := range caCerts {
+		if caCert != "" {
+			nonEmpty = append(nonEmpty, caCert)
+		}
+	}
+	if len(nonEmpty) == 0 {
+		if exclusiveCA {
+			return &tlsConfig, errors.New("tls_exclusive_ca requires ca_cert or ca_certs to be set")
+		}
 		log.Println("[WARN] no CA file set skipping")
 		return &tlsConfig, nil
 	}
 
-	caCertPool, _ := x509.SystemCertPool()
+	var caCertPool *x509.CertPool
+	if !exclusiveCA {
+		caCertPool, _ = x509.SystemCertPool()
+	} else {
+		log.Printf("[INFO] Trusting only the configured CAs, not the system's")
+	}
 	if caCertPool == nil {
 		caCertPool = x509.NewCertPool()
 	}
 
-	_, caBytes, err := parsePemOrLoadFromFile(caCert)
-	if err != nil {
-		log.Printf("[ERROR] Unable to read CA %s", err)
-		return &tlsConfig, err
-	}
-	ok := caCertPool.AppendCertsFromPEM(caBytes)
-	log.Printf("[TRACE] set cert pool %v", ok)
-	if !ok {
-		return &tlsConfig, fmt.Errorf("could not add the caPem")
+	for _, caCert := range nonEmpty {
+		if err := appendCACerts(caCertPool, caCert); err != nil {
+			log.Printf("[ERROR] Unable to read CA %s", err)
+			return &tlsConfig, err
+		}
 	}
 
 	tlsConfig.RootCAs = caCertPool
 	return &tlsConfig, nil
 }
 
-func (config *Config) copyWithMaskedSensitiveValues() Config {
-	copy := Config{
-		config.BootstrapServers,
-		config.Timeout,
-		config.CACert,
-		config.ClientCert,
-		"*****",
-		"*****",
-		config.KafkaVersion,
-		config.TLSEnabled,
-		config.SkipTLSVerify,
-		config.SASLUsername,
-		"*****",
-		config.SASLMechanism,
-		config.SASLAWSContainerAuthorizationTokenFile,
-		config.SASLAWSContainerCredentialsFullUri,
-		config.SASLAWSRegion,
-		config.SASLAWSRoleArn,
-		"*****",
-		config.SASLAWSProfile,
-		config.SASLAWSAccessKey,
-		"*****",
-		config.SASLAWSToken,
-		config.SASLAWSCredsDebug,
-		config.SASLTokenUrl,
-		config.SASLAWSSharedConfigFiles,
-		config.SASLOAuthScopes,
+// appendCACerts adds every certificate of the PEM bundle in caCert, or in the
+// file it names, to pool. Unlike AppendCertsFromPEM, a certificate that
+// cannot be parsed is an error rather than silently skipped.
+func appendCACerts(pool *x509.CertPool, caCert string) error {
+	blocks, _, err := parsePemBlocksOrLoadFromFile(caCert)
+	if err != nil {
+		return err
 	}
+
+	added := 0
+	for i, block := range blocks {
+		if block.Type != "CERTIFICATE" {
+			log.Printf("[WARN] Skipping %s PEM block %d of CA bundle", block.Type, i)
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return fmt.Errorf("could not parse certificate %d of CA bundle: %w", i, err)
+		}
+		pool.AddCert(cert)
+		added++
+	}
+	log.Printf("[TRACE] Added %d CA certificates to the cert pool", added)
+
+	if added == 0 {
+		return fmt.Errorf("could not add the caPem: no certificates found")
+	}
+	return nil
+}
+
+// summary describes how the provider connects, for support tickets
+func (c *Config) summary() string {
+	var bootstrapServers []string
+	if c.BootstrapServers != nil {
+		bootstrapServers = *c.BootstrapServers
+	}
+	tls := "disabled"
+	if c.TLSEnabled {
+		tls = "enabled"
+	}
+	sasl := "none"
+	if c.saslEnabled() {
+		sasl = c.SASLMechanism
+	}
+	return fmt.Sprintf("%d bootstrap servers %v, TLS %s, SASL mechanism %s, kafka_version %q", len(bootstrapServers), bootstrapServers, tls, sasl, c.KafkaVersion)
+}
+
+// copyWithMaskedSensitiveValues returns a copy of the config that is safe to
+// log. Fields are copied as they are unless masked here, so a new secret
+// field must be masked here too.
+func (config *Config) copyWithMaskedSensitiveValues() Config {
+	copy := *config
+	// a pkcs12 client_cert bundles the private key
+	copy.ClientCert = "*****"
+	copy.ClientCertKey = "*****"
+	copy.ClientCertKeyPassphrase = "*****"
+	copy.SASLPassword = "*****"
+	copy.SASLAWSExternalId = "*****"
+	copy.SASLAWSRoleArnChain = maskedRoleChain(config.SASLAWSRoleArnChain)
+	copy.SASLAWSSecretKey = "*****"
+	copy.SASLAWSToken = "*****"
+	copy.ProxyURL = redactedURL(config.ProxyURL)
+	copy.ProxyPassword = "*****"
+	copy.SSHTunnel = maskedSSHTunnel(config.SSHTunnel)
 	return copy
 }
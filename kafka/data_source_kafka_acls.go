@@ -0,0 +1,136 @@
+package kafka
+
+import (
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func kafkaACLsDataSource() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceACLsRead,
+		Schema: map[string]*schema.Schema{
+			"resource_name": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return ACLs for this resource name. Matches any name when empty.",
+			},
+			"resource_type": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice([]string{"Any", "Topic", "Group", "Cluster", "TransactionalID"}, false),
+				Description:  "Only return ACLs for this resource type. Matches any type when empty.",
+			},
+			"resource_pattern_type_filter": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice([]string{"Any", "Match", "Literal", "Prefixed"}, false),
+				Description:  "Only return ACLs with this pattern type. `Match` also returns prefixed and wildcard ACLs that apply to `resource_name`. Matches any pattern type when empty.",
+			},
+			"acl_principal": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return ACLs for this principal. Matches any principal when empty.",
+			},
+			"acl_host": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return ACLs for this host. Matches any host when empty.",
+			},
+			"acl_operation": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice([]string{"Any", "All", "Read", "Write", "Create", "Delete", "Alter", "Describe", "ClusterAction", "DescribeConfigs", "AlterConfigs", "IdempotentWrite"}, false),
+				Description:  "Only return ACLs for this operation. Matches any operation when empty.",
+			},
+			"acl_permission_type": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice([]string{"Any", "Allow", "Deny"}, false),
+				Description:  "Only return ACLs with this permission type. Matches any permission type when empty.",
+			},
+			"acls": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The matching ACLs.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"resource_name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"resource_type": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"resource_pattern_type_filter": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"acl_principal": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"acl_host": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"acl_operation": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"acl_permission_type": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceACLsRead(d *schema.ResourceData, meta interface{}) error {
+	filter := StringlyTypedACL{
+		ACL: ACL{
+			Principal:      d.Get("acl_principal").(string),
+			Host:           d.Get("acl_host").(string),
+			Operation:      d.Get("acl_operation").(string),
+			PermissionType: d.Get("acl_permission_type").(string),
+		},
+		Resource: Resource{
+			Type:              d.Get("resource_type").(string),
+			Name:              d.Get("resource_name").(string),
+			PatternTypeFilter: d.Get("resource_pattern_type_filter").(string),
+		},
+	}
+
+	client := meta.(*LazyClient)
+	acls, err := client.FindACLs(filter)
+	if err != nil {
+		log.Printf("[ERROR] Error describing ACLs from Kafka: %s", err)
+		return err
+	}
+
+	flattened := make([]map[string]interface{}, len(acls))
+	for i, a := range acls {
+		flattened[i] = map[string]interface{}{
+			"resource_name":                a.Resource.Name,
+			"resource_type":                a.Resource.Type,
+			"resource_pattern_type_filter": a.Resource.PatternTypeFilter,
+			"acl_principal":                a.ACL.Principal,
+			"acl_host":                     a.ACL.Host,
+			"acl_operation":                a.ACL.Operation,
+			"acl_permission_type":          a.ACL.PermissionType,
+		}
+	}
+
+	log.Printf("[DEBUG] Found %d ACLs matching %s", len(acls), filter)
+	errSet := errSetter{d: d}
+	errSet.Set("acls", flattened)
+
+	d.SetId(strings.Join([]string{"acls", filter.String()}, "|"))
+	return errSet.err
+}
@@ -0,0 +1,59 @@
+package kafka
+
+import (
+	"fmt"
+	"testing"
+
+	uuid "github.com/hashicorp/go-uuid"
+	r "github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAcc_ACLsData(t *testing.T) {
+	t.Parallel()
+	u, err := uuid.GenerateUUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	aclResourceName := fmt.Sprintf("syslog-%s", u)
+	bs := testBootstrapServers[0]
+
+	r.Test(t, r.TestCase{
+		ProviderFactories: overrideProviderFactory(),
+		PreCheck:          func() { testAccPreCheck(t) },
+		Steps: []r.TestStep{
+			{
+				Config: cfg(t, bs, fmt.Sprintf(testDataSourceACLs, aclResourceName)),
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckResourceAttr("data.kafka_acls.test", "acls.#", "1"),
+					r.TestCheckResourceAttr("data.kafka_acls.test", "acls.0.resource_name", aclResourceName),
+					r.TestCheckResourceAttr("data.kafka_acls.test", "acls.0.resource_type", "Topic"),
+					r.TestCheckResourceAttr("data.kafka_acls.test", "acls.0.resource_pattern_type_filter", "Literal"),
+					r.TestCheckResourceAttr("data.kafka_acls.test", "acls.0.acl_principal", "User:Alice"),
+					r.TestCheckResourceAttr("data.kafka_acls.test", "acls.0.acl_operation", "Write"),
+					r.TestCheckResourceAttr("data.kafka_acls.test", "acls.0.acl_permission_type", "Allow"),
+					r.TestCheckResourceAttr("data.kafka_acls.none", "acls.#", "0"),
+				),
+			},
+		},
+	})
+}
+
+const testDataSourceACLs = `
+resource "kafka_acl" "test" {
+  resource_name       = "%s"
+  resource_type       = "Topic"
+  acl_principal       = "User:Alice"
+  acl_host            = "*"
+  acl_operation       = "Write"
+  acl_permission_type = "Allow"
+}
+
+data "kafka_acls" "test" {
+  resource_name = kafka_acl.test.resource_name
+}
+
+data "kafka_acls" "none" {
+  resource_name = kafka_acl.test.resource_name
+  acl_operation = "Read"
+}
+`
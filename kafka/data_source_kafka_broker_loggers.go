@@ -0,0 +1,55 @@
+package kafka
+
+import (
+	"log"
+	"regexp"
+
+	"github.com/IBM/sarama"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func kafkaBrokerLoggersDataSource() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceBrokerLoggersRead,
+		Schema: map[string]*schema.Schema{
+			"broker_id": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringMatch(regexp.MustCompile(`^[0-9]+$`), "must be a broker ID"),
+				Description:  "The ID of the broker to read the log levels of.",
+			},
+			"loggers": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Description: "The level of each logger of the broker, keyed by logger name, e.g. INFO for kafka.controller.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func dataSourceBrokerLoggersRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*LazyClient)
+	broker := d.Get("broker_id").(string)
+
+	conf, err := client.BrokerConfig(sarama.BrokerLoggerResource, broker)
+	if err != nil {
+		log.Printf("[ERROR] Error reading the loggers of broker %s from Kafka: %s", broker, err)
+		return err
+	}
+
+	loggers := map[string]string{}
+	for name, level := range conf {
+		if level != nil {
+			loggers[name] = *level
+		}
+	}
+
+	log.Printf("[DEBUG] Broker %s has %d loggers", broker, len(loggers))
+	errSet := errSetter{d: d}
+	errSet.Set("loggers", loggers)
+
+	d.SetId(broker)
+	return errSet.err
+}
@@ -0,0 +1,85 @@
+package kafka
+
+import (
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func kafkaBrokersDataSource() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceBrokersRead,
+		Schema: map[string]*schema.Schema{
+			"controller_id": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The ID of the controller broker, or -1 if the cluster currently has no controller.",
+			},
+			"broker_ids": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The IDs of the live brokers, sorted.",
+				Elem:        &schema.Schema{Type: schema.TypeInt},
+			},
+			"brokers": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The live brokers, sorted by ID.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "The ID of the broker.",
+						},
+						"host": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The advertised host of the broker.",
+						},
+						"port": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "The advertised port of the broker.",
+						},
+						"rack": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The rack of the broker, empty if it has none.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceBrokersRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*LazyClient)
+	brokers, controllerID, err := client.DescribeBrokers()
+	if err != nil {
+		log.Printf("[ERROR] Error describing brokers from Kafka: %s", err)
+		return err
+	}
+
+	ids := make([]int, len(brokers))
+	flattened := make([]map[string]interface{}, len(brokers))
+	for i, b := range brokers {
+		ids[i] = int(b.ID)
+		flattened[i] = map[string]interface{}{
+			"id":   int(b.ID),
+			"host": b.Host,
+			"port": b.Port,
+			"rack": b.Rack,
+		}
+	}
+
+	log.Printf("[DEBUG] Found %d brokers, controller is %d", len(brokers), controllerID)
+	errSet := errSetter{d: d}
+	errSet.Set("controller_id", int(controllerID))
+	errSet.Set("broker_ids", ids)
+	errSet.Set("brokers", flattened)
+
+	d.SetId("brokers")
+	return errSet.err
+}
@@ -0,0 +1,34 @@
+package kafka
+
+import (
+	"testing"
+
+	r "github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAcc_BrokersData(t *testing.T) {
+	t.Parallel()
+	bs := testBootstrapServers[0]
+
+	r.Test(t, r.TestCase{
+		ProviderFactories: overrideProviderFactory(),
+		PreCheck:          func() { testAccPreCheck(t) },
+		Steps: []r.TestStep{
+			{
+				Config: cfg(t, bs, testDataSourceBrokers),
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckResourceAttr("data.kafka_brokers.test", "broker_ids.#", "3"),
+					r.TestCheckResourceAttr("data.kafka_brokers.test", "broker_ids.0", "1"),
+					r.TestCheckResourceAttr("data.kafka_brokers.test", "brokers.0.id", "1"),
+					r.TestCheckResourceAttrSet("data.kafka_brokers.test", "brokers.0.host"),
+					r.TestCheckResourceAttrSet("data.kafka_brokers.test", "brokers.0.port"),
+					r.TestCheckResourceAttrSet("data.kafka_brokers.test", "controller_id"),
+				),
+			},
+		},
+	})
+}
+
+const testDataSourceBrokers = `
+data "kafka_brokers" "test" {}
+`
@@ -0,0 +1,71 @@
+package kafka
+
+import (
+	"log"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func kafkaClusterDataSource() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceClusterRead,
+		Schema: map[string]*schema.Schema{
+			"cluster_id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The ID of the cluster, empty if the brokers are older than Kafka 0.10.1.",
+			},
+			"controller_id": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The ID of the controller broker, or -1 if the cluster currently has no controller.",
+			},
+			"broker_count": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The number of live brokers.",
+			},
+			"kafka_version": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The lowest Kafka version consistent with the APIs every broker supports, detected on a best-effort basis, e.g. 2.8.0 for a cluster of 2.8 brokers. Versions since 3.0.0 are reported as 3.0.0.",
+			},
+			"api_versions": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Description: "The highest version of each API supported by every broker, keyed by API key, e.g. 44 for IncrementalAlterConfigs.",
+				Elem:        &schema.Schema{Type: schema.TypeInt},
+			},
+		},
+	}
+}
+
+func dataSourceClusterRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*LazyClient)
+	info, err := client.DescribeCluster()
+	if err != nil {
+		log.Printf("[ERROR] Error describing cluster from Kafka: %s", err)
+		return err
+	}
+
+	apiVersions := make(map[string]int, len(info.APIVersions))
+	for apiKey, version := range info.APIVersions {
+		apiVersions[strconv.Itoa(apiKey)] = version
+	}
+
+	log.Printf("[DEBUG] Found cluster %s of %d brokers, detected version %s", info.ID, info.BrokerCount, info.KafkaVersion)
+	errSet := errSetter{d: d}
+	errSet.Set("cluster_id", info.ID)
+	errSet.Set("controller_id", int(info.ControllerID))
+	errSet.Set("broker_count", info.BrokerCount)
+	errSet.Set("kafka_version", info.KafkaVersion)
+	errSet.Set("api_versions", apiVersions)
+
+	id := info.ID
+	if id == "" {
+		id = "cluster"
+	}
+	d.SetId(id)
+	return errSet.err
+}
@@ -0,0 +1,34 @@
+package kafka
+
+import (
+	"regexp"
+	"testing"
+
+	r "github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAcc_ClusterData(t *testing.T) {
+	t.Parallel()
+	bs := testBootstrapServers[0]
+
+	r.Test(t, r.TestCase{
+		ProviderFactories: overrideProviderFactory(),
+		PreCheck:          func() { testAccPreCheck(t) },
+		Steps: []r.TestStep{
+			{
+				Config: cfg(t, bs, testDataSourceCluster),
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckResourceAttrSet("data.kafka_cluster.test", "cluster_id"),
+					r.TestCheckResourceAttrSet("data.kafka_cluster.test", "controller_id"),
+					r.TestCheckResourceAttr("data.kafka_cluster.test", "broker_count", "3"),
+					r.TestCheckResourceAttr("data.kafka_cluster.test", "kafka_version", "3.0.0"),
+					r.TestMatchResourceAttr("data.kafka_cluster.test", "api_versions.44", regexp.MustCompile(`^\d+$`)),
+				),
+			},
+		},
+	})
+}
+
+const testDataSourceCluster = `
+data "kafka_cluster" "test" {}
+`
@@ -0,0 +1,132 @@
+package kafka
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func kafkaConsumerGroupsDataSource() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceConsumerGroupsRead,
+		Schema: map[string]*schema.Schema{
+			"prefix": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return consumer groups whose ID starts with this prefix.",
+			},
+			"regex": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringIsValidRegExp,
+				Description:  "Only return consumer groups whose ID matches this regular expression.",
+			},
+			"detailed": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Describe each consumer group to populate its state and member count.",
+			},
+			"group_ids": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The IDs of the matching consumer groups.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"groups": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The matching consumer groups.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"group_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The ID of the consumer group.",
+						},
+						"protocol_type": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The protocol type of the consumer group.",
+						},
+						"state": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The state of the consumer group, e.g. Empty or Stable. Only set when `detailed` is true.",
+						},
+						"members": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "The number of members of the consumer group. Only set when `detailed` is true.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceConsumerGroupsRead(d *schema.ResourceData, meta interface{}) error {
+	prefix := d.Get("prefix").(string)
+	pattern := d.Get("regex").(string)
+	detailed := d.Get("detailed").(bool)
+
+	var re *regexp.Regexp
+	if pattern != "" {
+		var err error
+		re, err = regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid regex '%s': %w", pattern, err)
+		}
+	}
+
+	client := meta.(*LazyClient)
+	all, err := client.ListConsumerGroups()
+	if err != nil {
+		log.Printf("[ERROR] Error listing consumer groups from Kafka: %s", err)
+		return err
+	}
+
+	groups := make([]ConsumerGroup, 0, len(all))
+	for _, g := range all {
+		if !strings.HasPrefix(g.ID, prefix) {
+			continue
+		}
+		if re != nil && !re.MatchString(g.ID) {
+			continue
+		}
+		groups = append(groups, g)
+	}
+
+	if detailed {
+		groups, err = client.DescribeConsumerGroups(groups)
+		if err != nil {
+			log.Printf("[ERROR] Error describing consumer groups from Kafka: %s", err)
+			return err
+		}
+	}
+
+	ids := make([]string, len(groups))
+	flattened := make([]map[string]interface{}, len(groups))
+	for i, g := range groups {
+		ids[i] = g.ID
+		flattened[i] = map[string]interface{}{
+			"group_id":      g.ID,
+			"protocol_type": g.ProtocolType,
+			"state":         g.State,
+			"members":       g.Members,
+		}
+	}
+
+	log.Printf("[DEBUG] Found %d consumer groups matching prefix '%s' and regex '%s'", len(groups), prefix, pattern)
+	errSet := errSetter{d: d}
+	errSet.Set("group_ids", ids)
+	errSet.Set("groups", flattened)
+
+	d.SetId(strings.Join([]string{prefix, pattern}, "|"))
+	return errSet.err
+}
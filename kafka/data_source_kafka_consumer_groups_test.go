@@ -0,0 +1,55 @@
+package kafka
+
+import (
+	"fmt"
+	"testing"
+
+	uuid "github.com/hashicorp/go-uuid"
+	r "github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAcc_ConsumerGroupsData(t *testing.T) {
+	t.Parallel()
+	u, err := uuid.GenerateUUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	topicName := fmt.Sprintf("syslog-%s", u)
+	groupID := fmt.Sprintf("group-%s", u)
+	bs := testBootstrapServers[0]
+
+	r.Test(t, r.TestCase{
+		ProviderFactories: overrideProviderFactory(),
+		PreCheck:          func() { testAccPreCheck(t) },
+		Steps: []r.TestStep{
+			{
+				Config: cfg(t, bs, fmt.Sprintf(testDataSourceConsumerGroups, topicName, groupID)),
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckResourceAttr("data.kafka_consumer_groups.test", "group_ids.#", "1"),
+					r.TestCheckResourceAttr("data.kafka_consumer_groups.test", "group_ids.0", groupID),
+					r.TestCheckResourceAttr("data.kafka_consumer_groups.test", "groups.0.state", "Empty"),
+					r.TestCheckResourceAttr("data.kafka_consumer_groups.test", "groups.0.members", "0"),
+				),
+			},
+		},
+	})
+}
+
+const testDataSourceConsumerGroups = `
+resource "kafka_topic" "test" {
+  name               = "%[1]s"
+  replication_factor = 1
+  partitions         = 1
+}
+
+resource "kafka_consumer_group" "test" {
+  group_id = "%[2]s"
+  topic    = kafka_topic.test.name
+  reset_to = "earliest"
+}
+
+data "kafka_consumer_groups" "test" {
+  prefix   = kafka_consumer_group.test.group_id
+  detailed = true
+}
+`
@@ -0,0 +1,57 @@
+package kafka
+
+import (
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func kafkaSASLMechanismsDataSource() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceSASLMechanismsRead,
+		Schema: map[string]*schema.Schema{
+			"mechanisms": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The SASL mechanisms the brokers advertise, e.g. SCRAM-SHA-512.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"configured_mechanism": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The SASL mechanism the provider authenticates with, as brokers name it, e.g. OAUTHBEARER for aws-iam. Empty if SASL is disabled.",
+			},
+			"configured_mechanism_enabled": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the brokers advertise configured_mechanism.",
+			},
+		},
+	}
+}
+
+func dataSourceSASLMechanismsRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*LazyClient)
+	mechanisms, err := client.SASLMechanisms()
+	if err != nil {
+		log.Printf("[ERROR] Error listing SASL mechanisms from Kafka: %s", err)
+		return err
+	}
+
+	configured := client.configuredSASLMechanism()
+	enabled := false
+	for _, m := range mechanisms {
+		if m == configured {
+			enabled = true
+		}
+	}
+
+	log.Printf("[DEBUG] Brokers advertise SASL mechanisms %v", mechanisms)
+	errSet := errSetter{d: d}
+	errSet.Set("mechanisms", mechanisms)
+	errSet.Set("configured_mechanism", configured)
+	errSet.Set("configured_mechanism_enabled", enabled)
+
+	d.SetId("sasl_mechanisms")
+	return errSet.err
+}
@@ -0,0 +1,82 @@
+package kafka
+
+import (
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func kafkaScramCredentialDataSource() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceScramCredentialRead,
+		Schema: map[string]*schema.Schema{
+			"username": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The name of the user.",
+			},
+			"exists": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the user has SCRAM credentials of any mechanism.",
+			},
+			"scram_mechanisms": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The SCRAM mechanisms the user has credentials for, sorted.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"credentials": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The credentials of the user, sorted by mechanism. Kafka does not return their passwords.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"scram_mechanism": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The SCRAM mechanism of the credential.",
+						},
+						"scram_iterations": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "The number of SCRAM iterations of the credential.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceScramCredentialRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*LazyClient)
+	username := d.Get("username").(string)
+
+	credentials, err := client.DescribeUserScramCredentials(username)
+	if err != nil {
+		if _, ok := err.(UserScramCredentialMissingError); !ok {
+			log.Printf("[ERROR] Error describing scram credentials of %s from Kafka: %s", username, err)
+			return err
+		}
+	}
+
+	mechanisms := make([]string, len(credentials))
+	flattened := make([]map[string]interface{}, len(credentials))
+	for i, c := range credentials {
+		mechanisms[i] = c.Mechanism.String()
+		flattened[i] = map[string]interface{}{
+			"scram_mechanism":  c.Mechanism.String(),
+			"scram_iterations": int(c.Iterations),
+		}
+	}
+
+	log.Printf("[DEBUG] Found scram credentials of %s for %v", username, mechanisms)
+	errSet := errSetter{d: d}
+	errSet.Set("exists", len(credentials) > 0)
+	errSet.Set("scram_mechanisms", mechanisms)
+	errSet.Set("credentials", flattened)
+
+	d.SetId(username)
+	return errSet.err
+}
@@ -0,0 +1,78 @@
+package kafka
+
+import (
+	"fmt"
+	"testing"
+
+	uuid "github.com/hashicorp/go-uuid"
+	r "github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAcc_ScramCredentialData(t *testing.T) {
+	t.Parallel()
+	u, err := uuid.GenerateUUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	username := fmt.Sprintf("test-%s", u)
+	bs := testBootstrapServers[0]
+
+	r.Test(t, r.TestCase{
+		ProviderFactories: overrideProviderFactory(),
+		PreCheck:          func() { testAccPreCheck(t) },
+		Steps: []r.TestStep{
+			{
+				Config: cfgs(t, bs, fmt.Sprintf(testDataSourceScramCredential_missing, username)),
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckResourceAttr("data.kafka_scram_credential.test", "exists", "false"),
+					r.TestCheckResourceAttr("data.kafka_scram_credential.test", "scram_mechanisms.#", "0"),
+					r.TestCheckResourceAttr("data.kafka_scram_credential.test", "credentials.#", "0"),
+				),
+			},
+			{
+				Config: cfgs(t, bs, fmt.Sprintf(testDataSourceScramCredential, username)),
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckResourceAttr("data.kafka_scram_credential.test", "id", username),
+					r.TestCheckResourceAttr("data.kafka_scram_credential.test", "exists", "true"),
+					r.TestCheckResourceAttr("data.kafka_scram_credential.test", "scram_mechanisms.#", "2"),
+					r.TestCheckResourceAttr("data.kafka_scram_credential.test", "scram_mechanisms.0", "SCRAM-SHA-256"),
+					r.TestCheckResourceAttr("data.kafka_scram_credential.test", "scram_mechanisms.1", "SCRAM-SHA-512"),
+					r.TestCheckResourceAttr("data.kafka_scram_credential.test", "credentials.0.scram_iterations", "4096"),
+					r.TestCheckResourceAttr("data.kafka_scram_credential.test", "credentials.1.scram_mechanism", "SCRAM-SHA-512"),
+					r.TestCheckResourceAttr("data.kafka_scram_credential.test", "credentials.1.scram_iterations", "8192"),
+				),
+			},
+		},
+	})
+}
+
+const testDataSourceScramCredential_missing = `
+data "kafka_scram_credential" "test" {
+  username = "%s"
+}
+`
+
+const testDataSourceScramCredential = `
+resource "kafka_user_scram_credential" "sha256" {
+  username        = "%[1]s"
+  scram_mechanism = "SCRAM-SHA-256"
+  password        = "test"
+}
+
+resource "kafka_user_scram_credential" "sha512" {
+  username         = "%[1]s"
+  scram_mechanism  = "SCRAM-SHA-512"
+  scram_iterations = 8192
+  password         = "test"
+}
+
+data "kafka_scram_credential" "test" {
+  username = "%[1]s"
+
+  depends_on = [
+    kafka_user_scram_credential.sha256,
+    kafka_user_scram_credential.sha512,
+  ]
+}
+`
@@ -26,6 +26,63 @@ func kafkaTopicDataSource() *schema.Resource {
 				Computed:    true,
 				Description: "Number of replicas.",
 			},
+			"replica_assignment": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The brokers the replicas of each partition are placed on.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"partition": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "The partition number.",
+						},
+						"replicas": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Description: "The ordered list of broker IDs holding the partition's replicas.",
+							Elem:        &schema.Schema{Type: schema.TypeInt},
+						},
+					},
+				},
+			},
+			"partition_states": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The live state of each partition, from the brokers' metadata.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"partition": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "The partition number.",
+						},
+						"leader": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "The ID of the leader broker, or -1 if the partition is offline.",
+						},
+						"replicas": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Description: "The ordered list of broker IDs holding the partition's replicas.",
+							Elem:        &schema.Schema{Type: schema.TypeInt},
+						},
+						"in_sync_replicas": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Description: "The broker IDs of the replicas in sync with the leader.",
+							Elem:        &schema.Schema{Type: schema.TypeInt},
+						},
+						"offline_replicas": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Description: "The broker IDs of the replicas that are offline, e.g. on a failed disk.",
+							Elem:        &schema.Schema{Type: schema.TypeInt},
+						},
+					},
+				},
+			},
 			"config": {
 				Type:        schema.TypeMap,
 				Computed:    true,
@@ -55,12 +112,20 @@ func dataSourceTopicRead(d *schema.ResourceData, meta interface{}) error {
 		return err
 	}
 
+	states, err := client.TopicPartitionStates(name)
+	if err != nil {
+		log.Printf("[ERROR] Error getting the partitions of topic %s from Kafka: %s", name, err)
+		return err
+	}
+
 	log.Printf("[DEBUG] Setting the state from Kafka %v", topic)
 	errSet := errSetter{d: d}
 	errSet.Set("name", topic.Name)
 	errSet.Set("partitions", topic.Partitions)
 	errSet.Set("replication_factor", topic.ReplicationFactor)
-	errSet.Set("config", topic.Config)
+	errSet.Set("replica_assignment", flattenReplicaAssignment(topic.ReplicaAssignment))
+	errSet.Set("partition_states", flattenPartitionStates(states))
+	errSet.Set("config", strPtrMapToStrMap(topic.Config))
 
 	// Set the id to the name
 	d.SetId(name)
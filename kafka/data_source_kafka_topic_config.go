@@ -0,0 +1,92 @@
+package kafka
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/IBM/sarama"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// configSourceNames are the names Kafka's tools give the sources of config
+// values
+var configSourceNames = map[sarama.ConfigSource]string{
+	sarama.SourceUnknown:              "UNKNOWN",
+	sarama.SourceTopic:                "DYNAMIC_TOPIC_CONFIG",
+	sarama.SourceDynamicBroker:        "DYNAMIC_BROKER_CONFIG",
+	sarama.SourceDynamicDefaultBroker: "DYNAMIC_DEFAULT_BROKER_CONFIG",
+	sarama.SourceStaticBroker:         "STATIC_BROKER_CONFIG",
+	sarama.SourceDefault:              "DEFAULT_CONFIG",
+}
+
+func kafkaTopicConfigDataSource() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceTopicConfigRead,
+		Schema: map[string]*schema.Schema{
+			"topic": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The name of the topic.",
+			},
+			"config": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Description: "The effective value of every config entry of the topic, including those inherited from the brokers. Sensitive entries are left out, as Kafka never returns their values.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"sources": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Description: "Where the value of each config entry comes from, e.g. DYNAMIC_TOPIC_CONFIG when set on the topic, STATIC_BROKER_CONFIG when set in server.properties, or DEFAULT_CONFIG.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func dataSourceTopicConfigRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*LazyClient)
+	topic := d.Get("topic").(string)
+
+	entries, err := client.DescribeTopicConfig(topic)
+	if err != nil {
+		log.Printf("[ERROR] Error describing the config of topic %s from Kafka: %s", topic, err)
+		if _, ok := err.(TopicMissingError); ok {
+			return fmt.Errorf("could not find topic '%s'", topic)
+		}
+		return err
+	}
+
+	config, sources := topicConfigSources(entries)
+
+	log.Printf("[DEBUG] Topic %s has %d config entries", topic, len(sources))
+	errSet := errSetter{d: d}
+	errSet.Set("config", config)
+	errSet.Set("sources", sources)
+
+	d.SetId(topic)
+	return errSet.err
+}
+
+// topicConfigSources returns the value and the source of each config entry.
+// Brokers older than Kafka 1.1.0 only tell whether a value is the default.
+func topicConfigSources(entries []sarama.ConfigEntry) (map[string]string, map[string]string) {
+	config := map[string]string{}
+	sources := map[string]string{}
+	for _, entry := range entries {
+		source := entry.Source
+		if source == sarama.SourceUnknown && entry.Default {
+			source = sarama.SourceDefault
+		}
+		name, ok := configSourceNames[source]
+		if !ok {
+			name = configSourceNames[sarama.SourceUnknown]
+		}
+		sources[entry.Name] = name
+
+		if !entry.Sensitive {
+			config[entry.Name] = entry.Value
+		}
+	}
+	return config, sources
+}
@@ -0,0 +1,104 @@
+package kafka
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/IBM/sarama"
+	uuid "github.com/hashicorp/go-uuid"
+	r "github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func Test_topicConfigSources(t *testing.T) {
+	config, sources := topicConfigSources([]sarama.ConfigEntry{
+		{Name: "retention.ms", Value: "1000", Source: sarama.SourceTopic},
+		{Name: "segment.ms", Value: "604800000", Source: sarama.SourceDefault},
+		{Name: "cleanup.policy", Value: "compact", Source: sarama.SourceDynamicBroker},
+		{Name: "min.insync.replicas", Value: "2", Source: sarama.SourceDynamicDefaultBroker},
+		{Name: "max.message.bytes", Value: "1048588", Source: sarama.SourceStaticBroker},
+		{Name: "custom.password", Sensitive: true, Source: sarama.SourceTopic},
+		// as returned by brokers older than Kafka 1.1.0
+		{Name: "flush.ms", Value: "1000", Default: true},
+		{Name: "flush.messages", Value: "10"},
+	})
+
+	expectedConfig := map[string]string{
+		"retention.ms":        "1000",
+		"segment.ms":          "604800000",
+		"cleanup.policy":      "compact",
+		"min.insync.replicas": "2",
+		"max.message.bytes":   "1048588",
+		"flush.ms":            "1000",
+		"flush.messages":      "10",
+	}
+	if !reflect.DeepEqual(expectedConfig, config) {
+		t.Errorf("expected config %v, got %v", expectedConfig, config)
+	}
+
+	expectedSources := map[string]string{
+		"retention.ms":        "DYNAMIC_TOPIC_CONFIG",
+		"segment.ms":          "DEFAULT_CONFIG",
+		"cleanup.policy":      "DYNAMIC_BROKER_CONFIG",
+		"min.insync.replicas": "DYNAMIC_DEFAULT_BROKER_CONFIG",
+		"max.message.bytes":   "STATIC_BROKER_CONFIG",
+		"custom.password":     "DYNAMIC_TOPIC_CONFIG",
+		"flush.ms":            "DEFAULT_CONFIG",
+		"flush.messages":      "UNKNOWN",
+	}
+	if !reflect.DeepEqual(expectedSources, sources) {
+		t.Errorf("expected sources %v, got %v", expectedSources, sources)
+	}
+}
+
+func TestAcc_TopicConfigData(t *testing.T) {
+	t.Parallel()
+	u, err := uuid.GenerateUUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	topicName := fmt.Sprintf("syslog-%s", u)
+	bs := testBootstrapServers[0]
+
+	r.Test(t, r.TestCase{
+		ProviderFactories: overrideProviderFactory(),
+		PreCheck:          func() { testAccPreCheck(t) },
+		Steps: []r.TestStep{
+			{
+				Config:      cfg(t, bs, fmt.Sprintf(testDataSourceTopicConfig_readMissingTopic, topicName)),
+				ExpectError: regexp.MustCompile(fmt.Sprintf("could not find topic '%s'", topicName)),
+			},
+			{
+				Config: cfg(t, bs, fmt.Sprintf(testDataSourceTopicConfig_readExistingTopic, topicName)),
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckResourceAttr("data.kafka_topic_config.test", "id", topicName),
+					r.TestCheckResourceAttr("data.kafka_topic_config.test", "config.segment.ms", "22222"),
+					r.TestCheckResourceAttr("data.kafka_topic_config.test", "sources.segment.ms", "DYNAMIC_TOPIC_CONFIG"),
+					r.TestCheckResourceAttr("data.kafka_topic_config.test", "sources.max.compaction.lag.ms", "DEFAULT_CONFIG"),
+				),
+			},
+		},
+	})
+}
+
+const testDataSourceTopicConfig_readExistingTopic = `
+resource "kafka_topic" "test" {
+  name               = "%[1]s"
+  replication_factor = 1
+  partitions         = 1
+  config = {
+    "segment.ms" = "22222"
+  }
+}
+
+data "kafka_topic_config" "test" {
+  topic = kafka_topic.test.name
+}
+`
+
+const testDataSourceTopicConfig_readMissingTopic = `
+data "kafka_topic_config" "test" {
+  topic = "%[1]s"
+}
+`
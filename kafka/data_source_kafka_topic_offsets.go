@@ -0,0 +1,85 @@
+package kafka
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func kafkaTopicOffsetsDataSource() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceTopicOffsetsRead,
+		Schema: map[string]*schema.Schema{
+			"topic": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The name of the topic.",
+			},
+			"timestamp": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "A time, in milliseconds since the epoch, to look up the offset of the first record at or after in each partition. Requires Kafka 0.10.1.0 or later.",
+			},
+			"partitions": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The offsets of each partition, sorted by partition.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"partition": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "The partition number.",
+						},
+						"earliest_offset": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "The offset of the first record still in the partition, or -1 while the partition is empty.",
+						},
+						"latest_offset": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "The offset the next record is written at, the high watermark, or -1 while the partition is empty.",
+						},
+						"timestamp_offset": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "The offset of the first record at or after `timestamp`, or -1 if `timestamp` is not set or no record is at or after it, e.g. in an empty partition.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceTopicOffsetsRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*LazyClient)
+	topic := d.Get("topic").(string)
+
+	// a timestamp of 0, the epoch, is looked up too
+	var timestamp *int64
+	if !d.GetRawConfig().GetAttr("timestamp").IsNull() {
+		t := int64(d.Get("timestamp").(int))
+		timestamp = &t
+	}
+
+	offsets, err := client.TopicOffsets(topic, timestamp)
+	if err != nil {
+		log.Printf("[ERROR] Error listing the offsets of topic %s from Kafka: %s", topic, err)
+		if _, ok := err.(TopicMissingError); ok {
+			return fmt.Errorf("could not find topic '%s'", topic)
+		}
+		return err
+	}
+
+	log.Printf("[DEBUG] Found the offsets of %d partitions of topic %s", len(offsets), topic)
+	errSet := errSetter{d: d}
+	errSet.Set("partitions", flattenPartitionOffsets(offsets))
+
+	d.SetId(topic)
+	return errSet.err
+}
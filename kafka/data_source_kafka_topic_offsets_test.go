@@ -0,0 +1,61 @@
+package kafka
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	uuid "github.com/hashicorp/go-uuid"
+	r "github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAcc_TopicOffsetsData(t *testing.T) {
+	t.Parallel()
+	u, err := uuid.GenerateUUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	topicName := fmt.Sprintf("syslog-%s", u)
+	bs := testBootstrapServers[0]
+
+	r.Test(t, r.TestCase{
+		ProviderFactories: overrideProviderFactory(),
+		PreCheck:          func() { testAccPreCheck(t) },
+		Steps: []r.TestStep{
+			{
+				Config:      cfg(t, bs, fmt.Sprintf(testDataSourceTopicOffsets_readMissingTopic, topicName)),
+				ExpectError: regexp.MustCompile(fmt.Sprintf("could not find topic '%s'", topicName)),
+			},
+			{
+				Config: cfg(t, bs, fmt.Sprintf(testDataSourceTopicOffsets_readExistingTopic, topicName)),
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckResourceAttr("data.kafka_topic_offsets.test", "id", topicName),
+					r.TestCheckResourceAttr("data.kafka_topic_offsets.test", "partitions.#", "2"),
+					r.TestCheckResourceAttr("data.kafka_topic_offsets.test", "partitions.1.partition", "1"),
+					r.TestCheckResourceAttr("data.kafka_topic_offsets.test", "partitions.1.earliest_offset", "-1"),
+					r.TestCheckResourceAttr("data.kafka_topic_offsets.test", "partitions.1.latest_offset", "-1"),
+					r.TestCheckResourceAttr("data.kafka_topic_offsets.test", "partitions.1.timestamp_offset", "-1"),
+				),
+			},
+		},
+	})
+}
+
+const testDataSourceTopicOffsets_readExistingTopic = `
+resource "kafka_topic" "test" {
+  name               = "%[1]s"
+  replication_factor = 1
+  partitions         = 2
+}
+
+data "kafka_topic_offsets" "test" {
+  topic     = kafka_topic.test.name
+  timestamp = 0
+}
+`
+
+const testDataSourceTopicOffsets_readMissingTopic = `
+data "kafka_topic_offsets" "test" {
+  topic = "%[1]s"
+}
+`
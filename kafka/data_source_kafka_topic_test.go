@@ -34,6 +34,10 @@ func TestAcc_TopicData(t *testing.T) {
 					r.TestCheckResourceAttr("data.kafka_topic.test", "replication_factor", "1"),
 					r.TestCheckResourceAttr("data.kafka_topic.test", "partitions", "1"),
 					r.TestCheckResourceAttr("data.kafka_topic.test", "config.segment.ms", "22222"),
+					r.TestCheckResourceAttr("data.kafka_topic.test", "partition_states.#", "1"),
+					r.TestCheckResourceAttr("data.kafka_topic.test", "partition_states.0.partition", "0"),
+					r.TestCheckResourceAttrSet("data.kafka_topic.test", "partition_states.0.leader"),
+					r.TestCheckResourceAttr("data.kafka_topic.test", "partition_states.0.in_sync_replicas.#", "1"),
 				),
 			},
 		},
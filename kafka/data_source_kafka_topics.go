@@ -0,0 +1,129 @@
+package kafka
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func kafkaTopicsDataSource() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceTopicsRead,
+		Schema: map[string]*schema.Schema{
+			"prefix": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return topics whose name starts with this prefix.",
+			},
+			"regex": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringIsValidRegExp,
+				Description:  "Only return topics whose name matches this regular expression.",
+			},
+			"include_internal": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Also return internal topics, those Kafka marks as internal, e.g. __consumer_offsets, and those whose name starts with __.",
+			},
+			"include_partitions": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Populate the partition count of each topic.",
+			},
+			"names": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The names of the matching topics, sorted.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"topics": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The matching topics, sorted by name.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The name of the topic.",
+						},
+						"partitions": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "The number of partitions of the topic. Only set when `include_partitions` is true.",
+						},
+						"internal": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "Whether the topic is internal.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceTopicsRead(d *schema.ResourceData, meta interface{}) error {
+	prefix := d.Get("prefix").(string)
+	pattern := d.Get("regex").(string)
+	includeInternal := d.Get("include_internal").(bool)
+	includePartitions := d.Get("include_partitions").(bool)
+
+	var re *regexp.Regexp
+	if pattern != "" {
+		var err error
+		re, err = regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid regex '%s': %w", pattern, err)
+		}
+	}
+
+	client := meta.(*LazyClient)
+	all, err := client.ListTopics()
+	if err != nil {
+		log.Printf("[ERROR] Error listing topics from Kafka: %s", err)
+		return err
+	}
+
+	names := make([]string, 0, len(all))
+	flattened := make([]map[string]interface{}, 0, len(all))
+	for _, t := range all {
+		if t.Internal && !includeInternal {
+			continue
+		}
+		if !strings.HasPrefix(t.Name, prefix) {
+			continue
+		}
+		if re != nil && !re.MatchString(t.Name) {
+			continue
+		}
+
+		partitions := 0
+		if includePartitions {
+			partitions = t.Partitions
+		}
+		names = append(names, t.Name)
+		flattened = append(flattened, map[string]interface{}{
+			"name":       t.Name,
+			"partitions": partitions,
+			"internal":   t.Internal,
+		})
+	}
+
+	log.Printf("[DEBUG] Found %d topics matching prefix '%s' and regex '%s'", len(names), prefix, pattern)
+	errSet := errSetter{d: d}
+	errSet.Set("names", names)
+	errSet.Set("topics", flattened)
+
+	d.SetId(strings.Join([]string{prefix, pattern, strconv.FormatBool(includeInternal)}, "|"))
+	return errSet.err
+}
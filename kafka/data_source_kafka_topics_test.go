@@ -0,0 +1,75 @@
+package kafka
+
+import (
+	"fmt"
+	"testing"
+
+	uuid "github.com/hashicorp/go-uuid"
+	r "github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAcc_TopicsData(t *testing.T) {
+	t.Parallel()
+	u, err := uuid.GenerateUUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	prefix := fmt.Sprintf("inventory-%s-", u)
+	bs := testBootstrapServers[0]
+
+	r.Test(t, r.TestCase{
+		ProviderFactories: overrideProviderFactory(),
+		PreCheck:          func() { testAccPreCheck(t) },
+		CheckDestroy:      testAccCheckTopicDestroy,
+		Steps: []r.TestStep{
+			{
+				Config: cfg(t, bs, fmt.Sprintf(testDataSourceTopics, prefix)),
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckResourceAttr("data.kafka_topics.prefix", "names.#", "2"),
+					r.TestCheckResourceAttr("data.kafka_topics.prefix", "names.0", prefix+"events"),
+					r.TestCheckResourceAttr("data.kafka_topics.prefix", "names.1", prefix+"orders"),
+					r.TestCheckResourceAttr("data.kafka_topics.prefix", "topics.1.partitions", "3"),
+					r.TestCheckResourceAttr("data.kafka_topics.prefix", "topics.1.internal", "false"),
+					r.TestCheckResourceAttr("data.kafka_topics.regex", "names.#", "1"),
+					r.TestCheckResourceAttr("data.kafka_topics.regex", "names.0", prefix+"orders"),
+					r.TestCheckResourceAttr("data.kafka_topics.regex", "topics.0.partitions", "0"),
+					// internal topics, e.g. __consumer_offsets, are left out
+					r.TestCheckResourceAttr("data.kafka_topics.internal", "names.#", "0"),
+				),
+			},
+		},
+	})
+}
+
+const testDataSourceTopics = `
+resource "kafka_topic" "events" {
+  name               = "%[1]sevents"
+  replication_factor = 1
+  partitions         = 1
+}
+
+resource "kafka_topic" "orders" {
+  name               = "%[1]sorders"
+  replication_factor = 1
+  partitions         = 3
+}
+
+data "kafka_topics" "prefix" {
+  prefix             = "%[1]s"
+  include_partitions = true
+
+  depends_on = [kafka_topic.events, kafka_topic.orders]
+}
+
+data "kafka_topics" "regex" {
+  regex = "^%[1]s(orders|payments)$"
+
+  depends_on = [kafka_topic.events, kafka_topic.orders]
+}
+
+data "kafka_topics" "internal" {
+  prefix = "__"
+
+  depends_on = [kafka_topic.events, kafka_topic.orders]
+}
+`
@@ -1,9 +1,12 @@
 package kafka
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/IBM/sarama"
@@ -157,11 +160,7 @@ func (c *Client) enqueueDeleteACL(broker *sarama.Broker, filter *sarama.AclFilte
 
 		c.InvalidateACLCache()
 		for i, r := range res.FilterResponses {
-			if r.Err != sarama.ErrNoError {
-				c.aclDeletionQueue.waitChans[i] <- r.Err
-			} else {
-				c.aclDeletionQueue.waitChans[i] <- nil
-			}
+			c.aclDeletionQueue.waitChans[i] <- newBrokerError("deleting", "ACLs", r.Err, r.ErrMsg)
 		}
 	})
 
@@ -170,10 +169,11 @@ func (c *Client) enqueueDeleteACL(broker *sarama.Broker, filter *sarama.AclFilte
 }
 
 func (c *Client) DeleteACL(s StringlyTypedACL) error {
-	broker, err := c.client.Controller()
+	broker, release, err := c.controller()
 	if err != nil {
 		return err
 	}
+	defer release()
 
 	filter, err := tfToAclFilter(s)
 	if err != nil {
@@ -230,11 +230,7 @@ func (c *Client) enqueueCreateACL(broker *sarama.Broker, create *sarama.AclCreat
 		c.InvalidateACLCache()
 
 		for i, r := range res.AclCreationResponses {
-			if r.Err != sarama.ErrNoError {
-				c.aclCreationQueue.waitChans[i] <- r.Err
-			} else {
-				c.aclCreationQueue.waitChans[i] <- nil
-			}
+			c.aclCreationQueue.waitChans[i] <- newBrokerError("creating", "ACL", r.Err, r.ErrMsg)
 		}
 
 	})
@@ -244,10 +240,11 @@ func (c *Client) enqueueCreateACL(broker *sarama.Broker, create *sarama.AclCreat
 }
 
 func (c *Client) CreateACL(s StringlyTypedACL) error {
-	broker, err := c.client.Controller()
+	broker, release, err := c.controller()
 	if err != nil {
 		return err
 	}
+	defer release()
 
 	ac, err := tfToAclCreation(s)
 	if err != nil {
@@ -264,6 +261,32 @@ func (c *Client) CreateACL(s StringlyTypedACL) error {
 	return nil
 }
 
+// CreateACLs creates each of the ACLs, sent to the controller together
+func (c *Client) CreateACLs(acls []StringlyTypedACL) error {
+	return eachACL(acls, c.CreateACL)
+}
+
+// DeleteACLs deletes each of the ACLs, sent to the controller together
+func (c *Client) DeleteACLs(acls []StringlyTypedACL) error {
+	return eachACL(acls, c.DeleteACL)
+}
+
+// eachACL calls f for each of the ACLs at the same time, so that the ACL
+// queues batch them into one request, and returns their errors joined
+func eachACL(acls []StringlyTypedACL, f func(StringlyTypedACL) error) error {
+	errs := make([]error, len(acls))
+	var wg sync.WaitGroup
+	for i, a := range acls {
+		wg.Add(1)
+		go func(i int, a StringlyTypedACL) {
+			defer wg.Done()
+			errs[i] = f(a)
+		}(i, a)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
 func stringToACLResource(in string) sarama.AclResourceType {
 	switch in {
 	case "Unknown":
@@ -278,6 +301,8 @@ func stringToACLResource(in string) sarama.AclResourceType {
 		return sarama.AclResourceCluster
 	case "TransactionalID":
 		return sarama.AclResourceTransactionalID
+	case "DelegationToken":
+		return sarama.AclResourceDelegationToken
 	}
 	return unknownConversion
 }
@@ -296,6 +321,8 @@ func ACLResourceToString(in sarama.AclResourceType) string {
 		return "Cluster"
 	case sarama.AclResourceTransactionalID:
 		return "TransactionalID"
+	case sarama.AclResourceDelegationToken:
+		return "DelegationToken"
 	}
 	return "unknownConversion"
 }
@@ -396,16 +423,19 @@ func ACLPermissionTypeToString(in sarama.AclPermissionType) string {
 
 // DescribeACLs get ResourceAcls for a specific resource
 func (c *Client) DescribeACLs(s StringlyTypedACL) ([]*sarama.ResourceAcls, error) {
+	sc, release := c.acquireClient()
+	defer release()
 	aclFilter, err := tfToAclFilter(s)
 	if err != nil {
 		return nil, err
 	}
 
-	broker, err := c.client.Controller()
+	broker, releaseController, err := c.controller()
 	if err != nil {
 		return nil, err
 	}
-	err = c.client.RefreshMetadata()
+	defer releaseController()
+	err = sc.RefreshMetadata()
 	if err != nil {
 		return nil, err
 	}
@@ -419,13 +449,86 @@ func (c *Client) DescribeACLs(s StringlyTypedACL) ([]*sarama.ResourceAcls, error
 		return nil, err
 	}
 
-	if err == nil {
-		if aclsR.Err != sarama.ErrNoError {
-			return nil, fmt.Errorf("%s", aclsR.Err)
+	if err := newBrokerError("describing", "ACLs", aclsR.Err, aclsR.ErrMsg); err != nil {
+		return nil, err
+	}
+
+	return aclsR.ResourceAcls, nil
+}
+
+// FindACLs returns the ACLs matching s, sorted by their string form. Empty
+// fields of s match any value.
+func (c *Client) FindACLs(s StringlyTypedACL) ([]StringlyTypedACL, error) {
+	aclFilter, err := tfToAclFilter(aclFilterDefaults(s))
+	if err != nil {
+		return nil, err
+	}
+	if s.ACL.Principal == "" {
+		aclFilter.Principal = nil
+	}
+	if s.ACL.Host == "" {
+		aclFilter.Host = nil
+	}
+	if s.Resource.Name == "" {
+		aclFilter.ResourceName = nil
+	}
+
+	broker, release, err := c.controller()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	log.Printf("[INFO] Describing ACLs matching %s", s)
+	aclsR, err := broker.DescribeAcls(&sarama.DescribeAclsRequest{
+		Version:   int(c.getDescribeAclsRequestAPIVersion()),
+		AclFilter: aclFilter,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := newBrokerError("describing ACLs matching", s.String(), aclsR.Err, aclsR.ErrMsg); err != nil {
+		return nil, err
+	}
+
+	res := []StringlyTypedACL{}
+	for _, resourceACLs := range aclsR.ResourceAcls {
+		for _, acl := range resourceACLs.Acls {
+			res = append(res, StringlyTypedACL{
+				ACL: ACL{
+					Principal:      acl.Principal,
+					Host:           acl.Host,
+					Operation:      ACLOperationToString(acl.Operation),
+					PermissionType: ACLPermissionTypeToString(acl.PermissionType),
+				},
+				Resource: Resource{
+					Type:              ACLResourceToString(resourceACLs.ResourceType),
+					Name:              resourceACLs.ResourceName,
+					PatternTypeFilter: resourceACLs.ResourcePatternType.String(),
+				},
+			})
 		}
 	}
+	sort.Slice(res, func(i, j int) bool { return res[i].String() < res[j].String() })
+
+	return res, nil
+}
 
-	return aclsR.ResourceAcls, err
+// aclFilterDefaults replaces the empty enum fields of s with Any
+func aclFilterDefaults(s StringlyTypedACL) StringlyTypedACL {
+	if s.ACL.Operation == "" {
+		s.ACL.Operation = "Any"
+	}
+	if s.ACL.PermissionType == "" {
+		s.ACL.PermissionType = "Any"
+	}
+	if s.Resource.Type == "" {
+		s.Resource.Type = "Any"
+	}
+	if s.Resource.PatternTypeFilter == "" {
+		s.Resource.PatternTypeFilter = "Any"
+	}
+	return s
 }
 
 func (c *Client) InvalidateACLCache() {
@@ -447,10 +550,11 @@ func (c *Client) ListACLs() ([]*sarama.ResourceAcls, error) {
 	c.aclCache.mutex.Lock()
 	defer c.aclCache.mutex.Unlock()
 	log.Printf("[INFO] Listing all ACLS")
-	broker, err := c.client.Controller()
+	broker, release, err := c.controller()
 	if err != nil {
 		return nil, err
 	}
+	defer release()
 
 	allResources := []*sarama.DescribeAclsRequest{
 		&sarama.DescribeAclsRequest{
@@ -489,6 +593,15 @@ func (c *Client) ListACLs() ([]*sarama.ResourceAcls, error) {
 				Operation:                 sarama.AclOperationAny,
 			},
 		},
+		&sarama.DescribeAclsRequest{
+			Version: int(c.getDescribeAclsRequestAPIVersion()),
+			AclFilter: sarama.AclFilter{
+				ResourceType:              sarama.AclResourceDelegationToken,
+				ResourcePatternTypeFilter: sarama.AclPatternAny,
+				PermissionType:            sarama.AclPermissionAny,
+				Operation:                 sarama.AclOperationAny,
+			},
+		},
 	}
 	res := []*sarama.ResourceAcls{}
 
@@ -502,10 +615,8 @@ func (c *Client) ListACLs() ([]*sarama.ResourceAcls, error) {
 
 		log.Printf("[TRACE] ThrottleTime: %d", aclsR.ThrottleTime)
 
-		if err == nil {
-			if aclsR.Err != sarama.ErrNoError {
-				return nil, fmt.Errorf("%s", aclsR.Err)
-			}
+		if err := newBrokerError("describing", "ACLs", aclsR.Err, aclsR.ErrMsg); err != nil {
+			return nil, err
 		}
 
 		res = append(res, aclsR.ResourceAcls...)
@@ -0,0 +1,59 @@
+package kafka
+
+import (
+	"fmt"
+)
+
+// Keys of the APIs features are gated on, see
+// https://kafka.apache.org/protocol#protocol_api_keys
+const (
+	apiKeyIncrementalAlterConfigs      = 44
+	apiKeyAlterPartitionReassignments  = 45
+	apiKeyListPartitionReassignments   = 46
+	apiKeyDescribeClientQuotas         = 48
+	apiKeyAlterClientQuotas            = 49
+	apiKeyDescribeUserScramCredentials = 50
+	apiKeyAlterUserScramCredentials    = 51
+)
+
+// apiNames names the gated APIs in errors
+var apiNames = map[int]string{
+	apiKeyIncrementalAlterConfigs:      "IncrementalAlterConfigs",
+	apiKeyAlterPartitionReassignments:  "AlterPartitionReassignments",
+	apiKeyListPartitionReassignments:   "ListPartitionReassignments",
+	apiKeyDescribeClientQuotas:         "DescribeClientQuotas",
+	apiKeyAlterClientQuotas:            "AlterClientQuotas",
+	apiKeyDescribeUserScramCredentials: "DescribeUserScramCredentials",
+	apiKeyAlterUserScramCredentials:    "AlterUserScramCredentials",
+}
+
+// APIVersions returns the highest version of each API every broker supports,
+// keyed by API key, as negotiated with the brokers rather than assumed from
+// kafka_version
+func (c *Client) APIVersions() map[int]int {
+	versions := make(map[int]int, len(c.supportedAPIs))
+	for apiKey, version := range c.supportedAPIs {
+		versions[apiKey] = version
+	}
+	return versions
+}
+
+// SupportsAPI reports whether every broker supports at least the version of
+// the API
+func (c *Client) SupportsAPI(apiKey, version int) bool {
+	maxVersion, ok := c.supportedAPIs[apiKey]
+	return ok && maxVersion >= version
+}
+
+// requireAPI fails when the brokers do not support the API that the feature
+// needs, rather than sending them a request they would reject
+func (c *Client) requireAPI(feature string, apiKey int) error {
+	if c.SupportsAPI(apiKey, 0) {
+		return nil
+	}
+	name, ok := apiNames[apiKey]
+	if !ok {
+		name = fmt.Sprintf("API %d", apiKey)
+	}
+	return fmt.Errorf("%s requires the %s API, which the brokers do not support", feature, name)
+}
@@ -0,0 +1,27 @@
+package kafka
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_requireAPI(t *testing.T) {
+	c := &Client{supportedAPIs: map[int]int{apiKeyIncrementalAlterConfigs: 1}}
+	assertEquals(t, true, c.SupportsAPI(apiKeyIncrementalAlterConfigs, 1))
+	assertEquals(t, false, c.SupportsAPI(apiKeyIncrementalAlterConfigs, 2))
+	assertEquals(t, false, c.SupportsAPI(apiKeyAlterClientQuotas, 0))
+	assertNil(t, c.requireAPI("altering topic configs", apiKeyIncrementalAlterConfigs))
+
+	err := c.requireAPI("altering quotas", apiKeyAlterClientQuotas)
+	if err == nil || !strings.Contains(err.Error(), "requires the AlterClientQuotas API") {
+		t.Errorf("expected an error naming AlterClientQuotas, got %v", err)
+	}
+	if _, err := c.DescribeQuota("user", "alice"); err == nil {
+		t.Error("expected an error describing quotas without DescribeClientQuotas")
+	}
+
+	// the versions are a copy
+	versions := c.APIVersions()
+	versions[apiKeyAlterClientQuotas] = 0
+	assertEquals(t, false, c.SupportsAPI(apiKeyAlterClientQuotas, 0))
+}
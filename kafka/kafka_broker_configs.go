@@ -0,0 +1,139 @@
+package kafka
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/IBM/sarama"
+)
+
+// BrokerConfig returns the dynamic config set on a broker, or on every
+// broker as the cluster-wide default when broker is empty. Broker loggers
+// have no notion of a default, so each of their levels is returned.
+// Sensitive entries are returned with a nil value, as Kafka never returns
+// them.
+func (c *Client) BrokerConfig(resourceType sarama.ConfigResourceType, broker string) (map[string]*string, error) {
+	sc, release := c.acquireClient()
+	defer release()
+	if err := c.checkBrokerLoggers(resourceType); err != nil {
+		return nil, err
+	}
+
+	admin, err := sarama.NewClusterAdminFromClient(sc)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := admin.DescribeConfig(sarama.ConfigResource{Type: resourceType, Name: broker})
+	if err != nil {
+		return nil, err
+	}
+
+	return brokerConfigFromEntries(entries, resourceType, broker), nil
+}
+
+// brokerConfigFromEntries keeps the entries set at the scope of the broker
+// config: dynamically on the broker, or as the dynamic cluster default
+func brokerConfigFromEntries(entries []sarama.ConfigEntry, resourceType sarama.ConfigResourceType, broker string) map[string]*string {
+	source := sarama.SourceDynamicBroker
+	if broker == "" {
+		source = sarama.SourceDynamicDefaultBroker
+	}
+
+	conf := make(map[string]*string)
+	for _, entry := range entries {
+		if resourceType == sarama.BrokerResource && entry.Source != source {
+			continue
+		}
+		if entry.Sensitive {
+			conf[entry.Name] = nil
+			continue
+		}
+		value := entry.Value
+		conf[entry.Name] = &value
+	}
+	return conf
+}
+
+// AlterBrokerConfig sets and removes dynamic config entries of a broker, or
+// of the cluster-wide default when broker is empty. Removed entries revert
+// to the next level, e.g. the cluster-wide default or server.properties.
+func (c *Client) AlterBrokerConfig(resourceType sarama.ConfigResourceType, broker string, set map[string]*string, remove []string) error {
+	sc, release := c.acquireClient()
+	defer release()
+	if len(set) == 0 && len(remove) == 0 {
+		return nil
+	}
+	if err := c.checkBrokerLoggers(resourceType); err != nil {
+		return err
+	}
+
+	admin, err := sarama.NewClusterAdminFromClient(sc)
+	if err != nil {
+		return err
+	}
+
+	// only the keys are logged, as the values may be sensitive
+	log.Printf("[INFO] Altering config of %s: setting %v, removing %v", brokerConfigScope(resourceType, broker), sortedKeys(strPtrMapToStrMap(set)), remove)
+	if c.canIncrementalAlterConfigs() {
+		entries := make(map[string]sarama.IncrementalAlterConfigsEntry, len(set)+len(remove))
+		for key, value := range set {
+			entries[key] = sarama.IncrementalAlterConfigsEntry{
+				Operation: sarama.IncrementalAlterConfigsOperationSet,
+				Value:     value,
+			}
+		}
+		for _, key := range remove {
+			entries[key] = sarama.IncrementalAlterConfigsEntry{
+				Operation: sarama.IncrementalAlterConfigsOperationDelete,
+			}
+		}
+		return admin.IncrementalAlterConfig(resourceType, broker, entries, false)
+	}
+
+	// without incremental alters the whole dynamic config is replaced, so the
+	// entries not managed here are read and sent back as they are
+	log.Printf("[WARN] IncrementalAlterConfigs is not supported by the cluster, replacing the whole config of %s", brokerConfigScope(resourceType, broker))
+	current, err := c.BrokerConfig(resourceType, broker)
+	if err != nil {
+		return err
+	}
+	for key, value := range current {
+		if value == nil {
+			if _, ok := set[key]; !ok {
+				return fmt.Errorf("the sensitive entry %s of %s would be lost replacing its whole config", key, brokerConfigScope(resourceType, broker))
+			}
+		}
+	}
+	for _, key := range remove {
+		delete(current, key)
+	}
+	for key, value := range set {
+		current[key] = value
+	}
+
+	return admin.AlterConfig(resourceType, broker, current, false)
+}
+
+// checkBrokerLoggers fails for broker loggers on clusters older than Kafka
+// 2.4.0, which added them along with AlterPartitionReassignments
+func (c *Client) checkBrokerLoggers(resourceType sarama.ConfigResourceType) error {
+	if resourceType != sarama.BrokerLoggerResource {
+		return nil
+	}
+	if !c.SupportsAPI(apiKeyAlterPartitionReassignments, 0) {
+		return fmt.Errorf("broker loggers require Kafka 2.4.0 or later")
+	}
+	return nil
+}
+
+func brokerConfigScope(resourceType sarama.ConfigResourceType, broker string) string {
+	switch {
+	case resourceType == sarama.BrokerLoggerResource:
+		return fmt.Sprintf("the loggers of broker %s", broker)
+	case broker == "":
+		return "the cluster-wide broker default"
+	default:
+		return fmt.Sprintf("broker %s", broker)
+	}
+}
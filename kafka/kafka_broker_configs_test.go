@@ -0,0 +1,48 @@
+package kafka
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/IBM/sarama"
+)
+
+func Test_brokerConfigFromEntries(t *testing.T) {
+	entries := []sarama.ConfigEntry{
+		{Name: "background.threads", Value: "12", Source: sarama.SourceDynamicBroker},
+		{Name: "log.cleaner.threads", Value: "2", Source: sarama.SourceDynamicDefaultBroker},
+		{Name: "num.io.threads", Value: "8", Source: sarama.SourceStaticBroker},
+		{Name: "ssl.keystore.password", Source: sarama.SourceDynamicBroker, Sensitive: true},
+	}
+
+	conf := brokerConfigFromEntries(entries, sarama.BrokerResource, "1")
+	assertEquals(t, 2, len(conf))
+	assertEquals(t, "12", *conf["background.threads"])
+	if v, ok := conf["ssl.keystore.password"]; !ok || v != nil {
+		t.Errorf("expected the sensitive entry without its value, got %v", v)
+	}
+
+	conf = brokerConfigFromEntries(entries, sarama.BrokerResource, "")
+	if !reflect.DeepEqual(map[string]string{"log.cleaner.threads": "2"}, strPtrMapToStrMap(conf)) {
+		t.Errorf("expected only the cluster-wide defaults, got %v", strPtrMapToStrMap(conf))
+	}
+
+	// loggers report no source, so every level is returned
+	loggers := []sarama.ConfigEntry{
+		{Name: "kafka.controller", Value: "INFO"},
+		{Name: "kafka.log.LogCleaner", Value: "DEBUG"},
+	}
+	conf = brokerConfigFromEntries(loggers, sarama.BrokerLoggerResource, "1")
+	assertEquals(t, 2, len(conf))
+}
+
+func Test_checkBrokerLoggers(t *testing.T) {
+	c := &Client{supportedAPIs: map[int]int{44: 1}}
+	assertNil(t, c.checkBrokerLoggers(sarama.BrokerResource))
+	if err := c.checkBrokerLoggers(sarama.BrokerLoggerResource); err == nil {
+		t.Error("expected an error for broker loggers before Kafka 2.4.0")
+	}
+
+	c.supportedAPIs[45] = 0
+	assertNil(t, c.checkBrokerLoggers(sarama.BrokerLoggerResource))
+}
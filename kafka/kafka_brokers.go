@@ -0,0 +1,82 @@
+package kafka
+
+import (
+	"log"
+	"net"
+	"sort"
+	"strconv"
+
+	"github.com/IBM/sarama"
+)
+
+// BrokerInfo describes a live broker of the cluster
+type BrokerInfo struct {
+	ID   int32
+	Host string
+	Port int
+	Rack string
+}
+
+// noController is reported as the controller ID while the cluster has no
+// active controller, e.g. during an election
+const noController = -1
+
+// DescribeBrokers returns the live brokers sorted by ID, and the ID of the
+// controller. The metadata is requested from any broker, so it works while
+// the controller is unknown.
+func (c *Client) DescribeBrokers() ([]BrokerInfo, int32, error) {
+	log.Printf("[INFO] Describing brokers")
+	res, controllerID, err := c.clusterMetadata()
+	if err != nil {
+		return nil, noController, err
+	}
+
+	brokers := make([]BrokerInfo, 0, len(res.Brokers))
+	for _, b := range res.Brokers {
+		info, err := brokerInfo(b.ID(), b.Addr(), b.Rack())
+		if err != nil {
+			return nil, noController, err
+		}
+		brokers = append(brokers, info)
+	}
+	sort.Slice(brokers, func(i, j int) bool { return brokers[i].ID < brokers[j].ID })
+
+	return brokers, controllerID, nil
+}
+
+// clusterMetadata requests the metadata of the cluster from any broker,
+// returning it with the ID of the controller
+func (c *Client) clusterMetadata() (*sarama.MetadataResponse, int32, error) {
+	sc, release := c.acquireClient()
+	defer release()
+	broker := sc.LeastLoadedBroker()
+	if broker == nil {
+		return nil, noController, sarama.ErrOutOfBrokers
+	}
+
+	req := sarama.NewMetadataRequest(c.kafkaConfig.Version, nil)
+	res, err := broker.GetMetadata(req)
+	if err != nil {
+		return nil, noController, err
+	}
+
+	controllerID := res.ControllerID
+	if req.Version < 1 {
+		// the controller is only part of the response from v1 on
+		controllerID = noController
+	}
+	return res, controllerID, nil
+}
+
+func brokerInfo(id int32, addr string, rack string) (BrokerInfo, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return BrokerInfo{}, err
+	}
+	p, err := strconv.Atoi(port)
+	if err != nil {
+		return BrokerInfo{}, err
+	}
+
+	return BrokerInfo{ID: id, Host: host, Port: p, Rack: rack}, nil
+}
@@ -0,0 +1,28 @@
+package kafka
+
+import (
+	"testing"
+)
+
+func Test_brokerInfo(t *testing.T) {
+	info, err := brokerInfo(2, "kafka2.example.com:9093", "us-east-1a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := BrokerInfo{ID: 2, Host: "kafka2.example.com", Port: 9093, Rack: "us-east-1a"}
+	if info != expected {
+		t.Fatalf("expected %+v, got %+v", expected, info)
+	}
+
+	info, err = brokerInfo(3, "[::1]:9092", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Host != "::1" || info.Port != 9092 {
+		t.Fatalf("expected ::1 port 9092, got %+v", info)
+	}
+
+	if _, err := brokerInfo(4, "kafka4.example.com", ""); err == nil {
+		t.Fatal("expected an error for an address without a port")
+	}
+}
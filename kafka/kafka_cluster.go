@@ -0,0 +1,116 @@
+package kafka
+
+import (
+	"log"
+
+	"github.com/IBM/sarama"
+)
+
+// ClusterInfo describes the cluster the provider is connected to
+type ClusterInfo struct {
+	ID           string
+	ControllerID int32
+	BrokerCount  int
+	// KafkaVersion is the lowest Kafka release supporting every API the
+	// brokers support, empty if it cannot be told
+	KafkaVersion string
+	// APIVersions maps the key of each API supported by every broker to the
+	// highest version they all support
+	APIVersions map[int]int
+}
+
+// kafkaVersionMarkers lists, newest first, an API version that each Kafka
+// release was the first to support. Releases since 3.0 add no API that is
+// always enabled, so they cannot be told apart.
+var kafkaVersionMarkers = []struct {
+	version    sarama.KafkaVersion
+	apiKey     int
+	apiVersion int
+}{
+	{sarama.V3_0_0_0, 61, 0},  // DescribeProducers
+	{sarama.V2_8_0_0, 60, 0},  // DescribeCluster
+	{sarama.V2_7_0_0, 50, 0},  // DescribeUserScramCredentials
+	{sarama.V2_6_0_0, 48, 0},  // DescribeClientQuotas
+	{sarama.V2_4_0_0, 45, 0},  // AlterPartitionReassignments
+	{sarama.V2_3_0_0, 44, 0},  // IncrementalAlterConfigs
+	{sarama.V2_2_0_0, 43, 0},  // ElectLeaders
+	{sarama.V2_1_0_0, 0, 7},   // Produce v7, with zstd
+	{sarama.V2_0_0_0, 0, 6},   // Produce v6
+	{sarama.V1_1_0_0, 42, 0},  // DeleteGroups
+	{sarama.V1_0_0_0, 37, 0},  // CreatePartitions
+	{sarama.V0_11_0_0, 30, 0}, // CreateAcls
+	{sarama.V0_10_1_0, 19, 0}, // CreateTopics
+	{sarama.V0_10_0_0, 18, 0}, // ApiVersions
+}
+
+// kafkaVersionAuto is the kafka_version detecting the version from the APIs
+// the brokers support
+const kafkaVersionAuto = "auto"
+
+// detectKafkaVersion returns the newest release whose marker API the brokers
+// support, as a lower bound of their version
+func detectKafkaVersion(supportedAPIs map[int]int) string {
+	for _, m := range kafkaVersionMarkers {
+		if v, ok := supportedAPIs[m.apiKey]; ok && v >= m.apiVersion {
+			return m.version.String()
+		}
+	}
+	return ""
+}
+
+// useDetectedKafkaVersion connects again with the Kafka version detected from
+// the APIs the brokers support, for the lifetime of the client. If it cannot
+// be told, or connecting with it fails, the client keeps 2.7.0.
+func (c *Client) useDetectedKafkaVersion() {
+	detected := detectKafkaVersion(c.supportedAPIs)
+	if detected == "" {
+		log.Printf("[WARN] Could not detect the Kafka version of the brokers, using %s", c.kafkaConfig.Version)
+		return
+	}
+	version, err := sarama.ParseKafkaVersion(detected)
+	if err != nil {
+		log.Printf("[WARN] Could not parse the detected Kafka version %s, using %s: %s", detected, c.kafkaConfig.Version, err)
+		return
+	}
+	if version == c.kafkaConfig.Version {
+		log.Printf("[INFO] Detected Kafka version %s", version)
+		return
+	}
+
+	// the sarama client keeps the config it was created with, so it is
+	// replaced along with a copy of the config
+	previous := c.kafkaConfig
+	kc := *previous
+	kc.Version = version
+	c.kafkaConfig = &kc
+	if err := c.Rebootstrap(); err != nil {
+		log.Printf("[WARN] Could not connect with the detected Kafka version %s, using %s: %s", version, previous.Version, err)
+		c.kafkaConfig = previous
+		return
+	}
+	log.Printf("[INFO] Detected Kafka version %s", version)
+}
+
+// DescribeCluster returns the ID, controller and size of the cluster, read
+// from its metadata as sarama does not implement the DescribeCluster API,
+// and the version detected from the APIs the brokers support
+func (c *Client) DescribeCluster() (ClusterInfo, error) {
+	log.Printf("[INFO] Describing cluster")
+	res, controllerID, err := c.clusterMetadata()
+	if err != nil {
+		return ClusterInfo{}, err
+	}
+
+	info := ClusterInfo{
+		ControllerID: controllerID,
+		BrokerCount:  len(res.Brokers),
+		KafkaVersion: detectKafkaVersion(c.supportedAPIs),
+		APIVersions:  c.APIVersions(),
+	}
+	// the cluster ID is only part of the response from v2 on
+	if res.ClusterID != nil {
+		info.ID = *res.ClusterID
+	}
+
+	return info, nil
+}
@@ -0,0 +1,54 @@
+package kafka
+
+import (
+	"testing"
+
+	"github.com/IBM/sarama"
+)
+
+func Test_detectKafkaVersion(t *testing.T) {
+	for _, tc := range []struct {
+		apis     map[int]int
+		expected string
+	}{
+		{apis: map[int]int{}, expected: ""},
+		{apis: map[int]int{0: 5, 18: 1, 19: 2, 30: 0, 37: 0, 42: 0}, expected: "1.1.0"},
+		{apis: map[int]int{0: 7, 18: 2, 19: 3, 30: 1, 37: 1, 42: 1}, expected: "2.1.0"},
+		{apis: map[int]int{0: 8, 18: 3, 44: 1, 45: 0, 48: 0, 50: 0, 60: 0}, expected: "2.8.0"},
+		{apis: map[int]int{0: 11, 18: 4, 60: 0, 61: 0, 68: 0}, expected: "3.0.0"},
+	} {
+		assertEquals(t, tc.expected, detectKafkaVersion(tc.apis))
+	}
+}
+
+func Test_useDetectedKafkaVersion(t *testing.T) {
+	for _, tc := range []struct {
+		apiKeys  []sarama.ApiVersionsResponseKey
+		expected sarama.KafkaVersion
+	}{
+		// DescribeCluster was added in 2.8.0
+		{apiKeys: []sarama.ApiVersionsResponseKey{{ApiKey: 3, MaxVersion: 9}, {ApiKey: 18, MaxVersion: 3}, {ApiKey: 60, MaxVersion: 0}}, expected: sarama.V2_8_0_0},
+		// without a known marker API, 2.7.0 is kept
+		{apiKeys: []sarama.ApiVersionsResponseKey{{ApiKey: 3, MaxVersion: 9}}, expected: sarama.V2_7_0_0},
+	} {
+		mb := sarama.NewMockBroker(t, 1)
+		mb.SetHandlerByMap(map[string]sarama.MockResponse{
+			"ApiVersionsRequest": sarama.NewMockApiVersionsResponse(t).SetApiKeys(tc.apiKeys),
+			"MetadataRequest": sarama.NewMockMetadataResponse(t).
+				SetBroker(mb.Addr(), mb.BrokerID()).
+				SetController(mb.BrokerID()),
+		})
+
+		client, err := NewClient(&Config{
+			BootstrapServers: &[]string{mb.Addr()},
+			KafkaVersion:     kafkaVersionAuto,
+			Timeout:          5,
+		})
+		assertNil(t, err)
+		assertEquals(t, tc.expected, client.kafkaConfig.Version)
+		assertEquals(t, tc.expected, client.client.Config().Version)
+
+		client.client.Close()
+		mb.Close()
+	}
+}
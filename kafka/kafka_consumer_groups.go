@@ -0,0 +1,314 @@
+package kafka
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/IBM/sarama"
+)
+
+type ConsumerGroupOffsetsMissingError struct {
+	msg string
+}
+
+func (e ConsumerGroupOffsetsMissingError) Error() string { return e.msg }
+
+// ConsumerGroupOffsets describes the offsets a consumer group should have
+// committed for the partitions of a topic
+type ConsumerGroupOffsets struct {
+	Group   string
+	Topic   string
+	ResetTo string
+	Offsets map[int32]int64
+	Force   bool
+}
+
+func (o ConsumerGroupOffsets) ID() string {
+	return strings.Join([]string{o.Group, o.Topic}, "|")
+}
+
+const (
+	offsetResetEarliest = "earliest"
+	offsetResetLatest   = "latest"
+)
+
+// describeConsumerGroupsBatchSize limits how many groups are described in a
+// single request
+const describeConsumerGroupsBatchSize = 100
+
+type ConsumerGroup struct {
+	ID           string
+	ProtocolType string
+	State        string
+	Members      int
+}
+
+// ListConsumerGroups returns the ID and protocol type of every consumer group
+// in the cluster, sorted by ID
+func (c *Client) ListConsumerGroups() ([]ConsumerGroup, error) {
+	sc, release := c.acquireClient()
+	defer release()
+	log.Printf("[INFO] Listing consumer groups")
+	admin, err := sarama.NewClusterAdminFromClient(sc)
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := admin.ListConsumerGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]ConsumerGroup, 0, len(groups))
+	for id, protocolType := range groups {
+		res = append(res, ConsumerGroup{
+			ID:           id,
+			ProtocolType: protocolType,
+		})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].ID < res[j].ID })
+
+	return res, nil
+}
+
+// DescribeConsumerGroups fills in the state and member count of each group,
+// describing them in batches of describeConsumerGroupsBatchSize
+func (c *Client) DescribeConsumerGroups(groups []ConsumerGroup) ([]ConsumerGroup, error) {
+	sc, release := c.acquireClient()
+	defer release()
+	log.Printf("[INFO] Describing %d consumer groups", len(groups))
+	admin, err := sarama.NewClusterAdminFromClient(sc)
+	if err != nil {
+		return nil, err
+	}
+
+	descriptions := make(map[string]*sarama.GroupDescription, len(groups))
+	for start := 0; start < len(groups); start += describeConsumerGroupsBatchSize {
+		end := start + describeConsumerGroupsBatchSize
+		if end > len(groups) {
+			end = len(groups)
+		}
+
+		ids := make([]string, 0, end-start)
+		for _, g := range groups[start:end] {
+			ids = append(ids, g.ID)
+		}
+
+		res, err := admin.DescribeConsumerGroups(ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range res {
+			if err := newBrokerError("describing consumer group", d.GroupId, d.Err, nil); err != nil {
+				return nil, err
+			}
+			descriptions[d.GroupId] = d
+		}
+	}
+
+	res := make([]ConsumerGroup, len(groups))
+	for i, g := range groups {
+		res[i] = g
+		if d, ok := descriptions[g.ID]; ok {
+			res[i].State = d.State
+			res[i].Members = len(d.Members)
+		}
+	}
+
+	return res, nil
+}
+
+// SetConsumerGroupOffsets commits the offsets described by o for the group.
+// Partitions without an explicit offset are reset to the earliest or latest
+// offset when ResetTo is set.
+func (c *Client) SetConsumerGroupOffsets(o ConsumerGroupOffsets) error {
+	sc, release := c.acquireClient()
+	defer release()
+	log.Printf("[INFO] Setting offsets for consumer group %s on topic %s", o.Group, o.Topic)
+	if !o.Force {
+		members, err := c.consumerGroupMemberCount(o.Group)
+		if err != nil {
+			return err
+		}
+		if members > 0 {
+			return fmt.Errorf("consumer group %s has %d active members; stop its consumers or set force to apply offsets anyway", o.Group, members)
+		}
+	}
+
+	partitions, err := sc.Partitions(o.Topic)
+	if err != nil {
+		return err
+	}
+
+	var base map[int32]int64
+	switch o.ResetTo {
+	case offsetResetEarliest:
+		base, err = c.topicOffsets(o.Topic, partitions, sarama.OffsetOldest)
+	case offsetResetLatest:
+		base, err = c.topicOffsets(o.Topic, partitions, sarama.OffsetNewest)
+	case "":
+	default:
+		err = fmt.Errorf("unknown offset reset mode: %s", o.ResetTo)
+	}
+	if err != nil {
+		return err
+	}
+
+	offsets, err := mergeOffsets(partitions, base, o.Offsets)
+	if err != nil {
+		return err
+	}
+
+	return c.commitOffsets(o.Group, o.Topic, offsets)
+}
+
+// ListConsumerGroupOffsets returns the committed offsets of a group for each
+// partition of topic. Partitions without a committed offset are omitted.
+func (c *Client) ListConsumerGroupOffsets(group string, topic string) (map[int32]int64, error) {
+	sc, release := c.acquireClient()
+	defer release()
+	log.Printf("[INFO] Listing offsets for consumer group %s on topic %s", group, topic)
+	partitions, err := sc.Partitions(topic)
+	if err != nil {
+		return nil, err
+	}
+
+	admin, err := sarama.NewClusterAdminFromClient(sc)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := admin.ListConsumerGroupOffsets(group, map[string][]int32{topic: partitions})
+	if err != nil {
+		return nil, err
+	}
+
+	offsets := map[int32]int64{}
+	for partition, block := range res.Blocks[topic] {
+		if err := newBrokerError("listing offsets of consumer group "+group+" on", fmt.Sprintf("%s/%d", topic, partition), block.Err, nil); err != nil {
+			return nil, err
+		}
+		if block.Offset >= 0 {
+			offsets[partition] = block.Offset
+		}
+	}
+
+	if len(offsets) == 0 {
+		msg := fmt.Sprintf("consumer group %s has no committed offsets for topic %s", group, topic)
+		return nil, ConsumerGroupOffsetsMissingError{msg: msg}
+	}
+
+	return offsets, nil
+}
+
+func (c *Client) consumerGroupMemberCount(group string) (int, error) {
+	sc, release := c.acquireClient()
+	defer release()
+	admin, err := sarama.NewClusterAdminFromClient(sc)
+	if err != nil {
+		return 0, err
+	}
+
+	groups, err := admin.DescribeConsumerGroups([]string{group})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, g := range groups {
+		if err := newBrokerError("describing consumer group", group, g.Err, nil); err != nil {
+			return 0, err
+		}
+		if g.GroupId == group {
+			return len(g.Members), nil
+		}
+	}
+
+	return 0, nil
+}
+
+func (c *Client) topicOffsets(topic string, partitions []int32, time int64) (map[int32]int64, error) {
+	sc, release := c.acquireClient()
+	defer release()
+	offsets := make(map[int32]int64, len(partitions))
+	for _, p := range partitions {
+		offset, err := sc.GetOffset(topic, p, time)
+		if err != nil {
+			return nil, err
+		}
+		offsets[p] = offset
+	}
+	return offsets, nil
+}
+
+func (c *Client) commitOffsets(group string, topic string, offsets map[int32]int64) error {
+	sc, release := c.acquireClient()
+	defer release()
+	coordinator, err := sc.Coordinator(group)
+	if err != nil {
+		return err
+	}
+
+	req := &sarama.OffsetCommitRequest{
+		ConsumerGroup:           group,
+		ConsumerGroupGeneration: sarama.GroupGenerationUndefined,
+	}
+	if c.kafkaConfig.Version.IsAtLeast(sarama.V2_0_0_0) {
+		req.Version = 4
+		req.RetentionTime = -1
+	} else if c.kafkaConfig.Version.IsAtLeast(sarama.V0_11_0_0) {
+		req.Version = 3
+		req.RetentionTime = -1
+	} else if c.kafkaConfig.Version.IsAtLeast(sarama.V0_9_0_0) {
+		req.Version = 2
+		req.RetentionTime = -1
+	} else {
+		req.Version = 1
+	}
+
+	for p, offset := range offsets {
+		req.AddBlock(topic, p, offset, sarama.ReceiveTime, "")
+	}
+
+	log.Printf("[DEBUG] Committing offsets %v for consumer group %s on topic %s", offsets, group, topic)
+	res, err := coordinator.CommitOffset(req)
+	if err != nil {
+		return err
+	}
+
+	for p, e := range res.Errors[topic] {
+		if e != sarama.ErrNoError {
+			return fmt.Errorf("error committing offset for consumer group %s on %s/%d: %w", group, topic, p, e)
+		}
+	}
+
+	return nil
+}
+
+// mergeOffsets combines the offsets from a reset with explicitly configured
+// per-partition offsets, which take precedence. It returns an error if an
+// explicit offset refers to a partition the topic does not have.
+func mergeOffsets(partitions []int32, base map[int32]int64, explicit map[int32]int64) (map[int32]int64, error) {
+	known := make(map[int32]bool, len(partitions))
+	for _, p := range partitions {
+		known[p] = true
+	}
+
+	merged := make(map[int32]int64, len(partitions))
+	for p, offset := range base {
+		merged[p] = offset
+	}
+	for p, offset := range explicit {
+		if !known[p] {
+			return nil, fmt.Errorf("partition %d does not exist", p)
+		}
+		merged[p] = offset
+	}
+
+	if len(merged) == 0 {
+		return nil, fmt.Errorf("no offsets to commit")
+	}
+
+	return merged, nil
+}
@@ -0,0 +1,36 @@
+package kafka
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_mergeOffsets(t *testing.T) {
+	partitions := []int32{0, 1, 2}
+
+	merged, err := mergeOffsets(partitions, map[int32]int64{0: 10, 1: 20, 2: 30}, map[int32]int64{1: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[int32]int64{0: 10, 1: 5, 2: 30}
+	if !reflect.DeepEqual(merged, expected) {
+		t.Errorf("%v != %v", merged, expected)
+	}
+
+	merged, err = mergeOffsets(partitions, nil, map[int32]int64{2: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = map[int32]int64{2: 7}
+	if !reflect.DeepEqual(merged, expected) {
+		t.Errorf("%v != %v", merged, expected)
+	}
+
+	if _, err := mergeOffsets(partitions, nil, map[int32]int64{3: 1}); err == nil {
+		t.Error("expected an error for an unknown partition")
+	}
+
+	if _, err := mergeOffsets(partitions, nil, nil); err == nil {
+		t.Error("expected an error when there are no offsets")
+	}
+}
@@ -0,0 +1,134 @@
+package kafka
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/IBM/sarama"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+// BrokerError is an error code a broker answered a request about a resource
+// with. It unwraps to the code, so that e.g.
+// errors.Is(err, sarama.ErrTopicAlreadyExists) matches it.
+type BrokerError struct {
+	// Op is what the request did, e.g. "creating topic"
+	Op       string
+	Resource string
+	Code     sarama.KError
+	// Msg is the broker's own message, if it sent one
+	Msg string
+}
+
+func (e BrokerError) Error() string {
+	msg := "error " + e.Op
+	if e.Resource != "" {
+		msg += " " + e.Resource
+	}
+	if e.Msg != "" && e.Msg != e.Code.Error() {
+		msg += ": " + e.Msg
+	}
+	return msg + ": " + e.Code.Error()
+}
+
+func (e BrokerError) Unwrap() error { return e.Code }
+
+// newBrokerError returns a BrokerError for the code, or nil if the code is
+// not an error
+func newBrokerError(op, resource string, code sarama.KError, msg *string) error {
+	if code == sarama.ErrNoError {
+		return nil
+	}
+	err := BrokerError{Op: op, Resource: resource, Code: code}
+	if msg != nil {
+		err.Msg = *msg
+	}
+	return err
+}
+
+var authorizationKafkaErrors = []sarama.KError{
+	sarama.ErrTopicAuthorizationFailed,
+	sarama.ErrGroupAuthorizationFailed,
+	sarama.ErrClusterAuthorizationFailed,
+	sarama.ErrTransactionalIDAuthorizationFailed,
+	sarama.ErrDelegationTokenAuthorizationFailed,
+}
+
+var authenticationKafkaErrors = []sarama.KError{
+	sarama.ErrSASLAuthenticationFailed,
+	sarama.ErrIllegalSASLState,
+	sarama.ErrUnsupportedSASLMechanism,
+}
+
+func isAnyKafkaError(err error, kerrs []sarama.KError) bool {
+	for _, kerr := range kerrs {
+		if errors.Is(err, kerr) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAuthorizationError reports whether the brokers refused the request for
+// lack of ACLs
+func IsAuthorizationError(err error) bool {
+	return isAnyKafkaError(err, authorizationKafkaErrors)
+}
+
+// IsAuthenticationError reports whether the brokers rejected the provider's
+// credentials
+func IsAuthenticationError(err error) bool {
+	return isAnyKafkaError(err, authenticationKafkaErrors)
+}
+
+// IsNotControllerError reports whether the request went to a broker that
+// is no longer the controller, e.g. during an election
+func IsNotControllerError(err error) bool {
+	return errors.Is(err, sarama.ErrNotController)
+}
+
+// IsTopicExistsError reports whether the topic to create already exists
+func IsTopicExistsError(err error) bool {
+	return errors.Is(err, sarama.ErrTopicAlreadyExists)
+}
+
+// IsUnknownConfigError reports whether the brokers rejected a topic config
+// key they do not know. The provider passes every key through, so that keys
+// only some brokers accept, e.g. Confluent Server's confluent.*, can be set.
+func IsUnknownConfigError(err error) bool {
+	var brokerErr BrokerError
+	return errors.As(err, &brokerErr) && brokerErr.Code == sarama.ErrInvalidConfig &&
+		strings.Contains(brokerErr.Msg, "Unknown topic config name")
+}
+
+// kafkaErrorDetail explains the common Kafka errors, and what to do about
+// them, for the detail of their diagnostic
+func kafkaErrorDetail(err error) string {
+	switch {
+	case IsAuthorizationError(err):
+		return "The principal the provider authenticates as is not allowed to do this. Grant it the ACLs the operation needs, e.g. Alter on the topic or Alter on the cluster."
+	case IsAuthenticationError(err):
+		return "The brokers rejected the provider's credentials. Check sasl_mechanism and the credentials configured for it."
+	case IsTopicExistsError(err):
+		return "Import the existing topic, or set adopt_existing to manage it without recreating it."
+	case IsUnknownConfigError(err):
+		return "The brokers only accept the topic config keys they know. Apache Kafka has no custom keys, so metadata such as owners cannot be stored in a topic's config unless the brokers, e.g. Confluent Server, define keys for it."
+	case errors.Is(err, sarama.ErrPolicyViolation):
+		return "A create or alter policy configured on the brokers rejected the change."
+	case errors.Is(err, sarama.ErrInvalidReplicationFactor):
+		return "The replication_factor is higher than the number of live brokers."
+	case isRetriableKafkaError(err):
+		return "Leadership in the cluster was moving, e.g. during a rolling restart. The provider's retry block sets how long such errors are retried for."
+	}
+	return ""
+}
+
+// diagFromErr is diag.FromErr with the detail of kafkaErrorDetail added for
+// the common Kafka errors
+func diagFromErr(err error) diag.Diagnostics {
+	diags := diag.FromErr(err)
+	if len(diags) > 0 {
+		diags[0].Detail = kafkaErrorDetail(err)
+	}
+	return diags
+}
@@ -0,0 +1,65 @@
+package kafka
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/IBM/sarama"
+)
+
+func Test_newBrokerError(t *testing.T) {
+	assertNil(t, newBrokerError("creating topic", "syslog", sarama.ErrNoError, nil))
+
+	msg := "Topic 'syslog' already exists."
+	err := newBrokerError("creating topic", "syslog", sarama.ErrTopicAlreadyExists, &msg)
+	assertEquals(t, "error creating topic syslog: Topic 'syslog' already exists.: "+sarama.ErrTopicAlreadyExists.Error(), err.Error())
+
+	// the error keeps matching once wrapped further
+	wrapped := fmt.Errorf("apply failed: %w", err)
+	assertEquals(t, true, errors.Is(wrapped, sarama.ErrTopicAlreadyExists))
+	assertEquals(t, true, IsTopicExistsError(wrapped))
+	var brokerErr BrokerError
+	if !errors.As(wrapped, &brokerErr) {
+		t.Fatalf("expected a BrokerError, got %T", wrapped)
+	}
+	assertEquals(t, "syslog", brokerErr.Resource)
+	assertEquals(t, sarama.ErrTopicAlreadyExists, brokerErr.Code)
+
+	// a message repeating the code is not repeated
+	msg = sarama.ErrNotController.Error()
+	err = newBrokerError("deleting topic", "syslog", sarama.ErrNotController, &msg)
+	assertEquals(t, "error deleting topic syslog: "+sarama.ErrNotController.Error(), err.Error())
+	assertEquals(t, true, IsNotControllerError(err))
+	assertEquals(t, true, isRetriableKafkaError(err))
+}
+
+func Test_diagFromErr(t *testing.T) {
+	unknownConfigMsg := "Unknown topic config name: tf.owner"
+	for _, tc := range []struct {
+		err    error
+		detail bool
+	}{
+		{newBrokerError("creating topic", "syslog", sarama.ErrTopicAuthorizationFailed, nil), true},
+		{newBrokerError("altering", "ACLs", sarama.ErrClusterAuthorizationFailed, nil), true},
+		{sarama.ErrSASLAuthenticationFailed, true},
+		{newBrokerError("creating topic", "syslog", sarama.ErrNotController, nil), true},
+		{newBrokerError("creating topic", "syslog", sarama.ErrInvalidConfig, nil), false},
+		{newBrokerError("creating topic", "syslog", sarama.ErrInvalidConfig, &unknownConfigMsg), true},
+		{errors.New("unexpected"), false},
+	} {
+		diags := diagFromErr(tc.err)
+		assertEquals(t, 1, len(diags))
+		assertEquals(t, tc.err.Error(), diags[0].Summary)
+		if got := diags[0].Detail != ""; got != tc.detail {
+			t.Errorf("expected a detail for %q: %v, got %q", tc.err, tc.detail, diags[0].Detail)
+		}
+	}
+
+	assertEquals(t, true, IsAuthorizationError(newBrokerError("describing consumer group", "g", sarama.ErrGroupAuthorizationFailed, nil)))
+	assertEquals(t, false, IsAuthenticationError(newBrokerError("describing consumer group", "g", sarama.ErrGroupAuthorizationFailed, nil)))
+	assertEquals(t, 0, len(diagFromErr(nil)))
+
+	assertEquals(t, true, IsUnknownConfigError(fmt.Errorf("apply failed: %w", newBrokerError("altering config of topic", "syslog", sarama.ErrInvalidConfig, &unknownConfigMsg))))
+	assertEquals(t, false, IsUnknownConfigError(newBrokerError("altering config of topic", "syslog", sarama.ErrInvalidConfig, nil)))
+}
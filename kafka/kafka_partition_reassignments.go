@@ -0,0 +1,415 @@
+package kafka
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+type PartitionMissingError struct {
+	msg string
+}
+
+func (e PartitionMissingError) Error() string { return e.msg }
+
+// PartitionReassignment describes the replicas a single partition of a topic
+// should be moved to
+type PartitionReassignment struct {
+	Topic     string
+	Partition int32
+	Replicas  []int32
+}
+
+func (r PartitionReassignment) ID() string {
+	return strings.Join([]string{r.Topic, strconv.Itoa(int(r.Partition))}, "|")
+}
+
+// PartitionReassignmentStatus is the progress of an ongoing reassignment
+type PartitionReassignmentStatus struct {
+	Replicas         []int32
+	AddingReplicas   []int32
+	RemovingReplicas []int32
+}
+
+func (s PartitionReassignmentStatus) String() string {
+	return fmt.Sprintf("replicas %v, adding %v, removing %v", s.Replicas, s.AddingReplicas, s.RemovingReplicas)
+}
+
+// ReassignPartition starts moving the partition to r.Replicas. Unlike the
+// admin client, only the given partition is part of the request, so ongoing
+// reassignments of the topic's other partitions are left alone.
+func (c *Client) ReassignPartition(r PartitionReassignment) error {
+	if _, err := c.PartitionReplicas(r.Topic, r.Partition); err != nil {
+		return err
+	}
+
+	if err := c.checkReassignmentBrokers(r); err != nil {
+		return err
+	}
+
+	log.Printf("[INFO] Reassigning %s to replicas %v", r.ID(), r.Replicas)
+	return c.alterPartitionReassignment(r.Topic, r.Partition, r.Replicas)
+}
+
+func (c *Client) checkReassignmentBrokers(r PartitionReassignment) error {
+	if missing := missingBrokers(*c.allReplicas(), r.Replicas); len(missing) > 0 {
+		return fmt.Errorf("cannot reassign %s: brokers %v are not part of the cluster (known brokers are %v); they either do not exist or are down", r.ID(), missing, *c.allReplicas())
+	}
+	return nil
+}
+
+// CancelPartitionReassignment cancels the ongoing reassignment of the
+// partition, if any, reverting it to its original replicas
+func (c *Client) CancelPartitionReassignment(topic string, partition int32) error {
+	log.Printf("[INFO] Cancelling reassignment of %s-%d", topic, partition)
+	return c.alterPartitionReassignment(topic, partition, nil)
+}
+
+func (c *Client) alterPartitionReassignment(topic string, partition int32, replicas []int32) error {
+	if err := c.requireAPI("reassigning partitions", apiKeyAlterPartitionReassignments); err != nil {
+		return err
+	}
+	broker, release, err := c.controller()
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	req := &sarama.AlterPartitionReassignmentsRequest{
+		TimeoutMs: int32(time.Duration(c.config.Timeout) * time.Second / time.Millisecond),
+	}
+	req.AddBlock(topic, partition, replicas)
+
+	res, err := broker.AlterPartitionReassignments(req)
+	if err != nil {
+		return err
+	}
+
+	return newBrokerError("reassigning", fmt.Sprintf("%s-%d", topic, partition), res.ErrorCode, res.ErrorMessage)
+}
+
+// PartitionReassignmentStatus returns the status of the ongoing reassignment
+// of the partition, or nil if there is none
+func (c *Client) PartitionReassignmentStatus(topic string, partition int32) (*PartitionReassignmentStatus, error) {
+	sc, release := c.acquireClient()
+	defer release()
+	if err := c.requireAPI("listing partition reassignments", apiKeyListPartitionReassignments); err != nil {
+		return nil, err
+	}
+	admin, err := sarama.NewClusterAdminFromClient(sc)
+	if err != nil {
+		return nil, err
+	}
+
+	statusMap, err := admin.ListPartitionReassignments(topic, []int32{partition})
+	if err != nil {
+		return nil, err
+	}
+
+	status, ok := statusMap[topic][partition]
+	if !ok || status == nil || !isPartitionRFChanging(status) {
+		return nil, nil
+	}
+
+	return &PartitionReassignmentStatus{
+		Replicas:         status.Replicas,
+		AddingReplicas:   status.AddingReplicas,
+		RemovingReplicas: status.RemovingReplicas,
+	}, nil
+}
+
+// PartitionReplicas returns the current replicas of the partition, refreshing
+// the topic metadata first
+func (c *Client) PartitionReplicas(topic string, partition int32) ([]int32, error) {
+	sc, release := c.acquireClient()
+	defer release()
+	log.Printf("[DEBUG] Refreshing metadata for topic '%s'", topic)
+	err := sc.RefreshMetadata(topic)
+	if errors.Is(err, sarama.ErrUnknownTopicOrPartition) {
+		return nil, PartitionMissingError{msg: fmt.Sprintf("topic %s could not be found", topic)}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	partitions, err := sc.Partitions(topic)
+	if err != nil {
+		return nil, err
+	}
+	if !containsPartition(partitions, partition) {
+		return nil, PartitionMissingError{msg: fmt.Sprintf("partition %d of topic %s could not be found", partition, topic)}
+	}
+
+	return sc.Replicas(topic, partition)
+}
+
+func containsPartition(partitions []int32, partition int32) bool {
+	for _, p := range partitions {
+		if p == partition {
+			return true
+		}
+	}
+	return false
+}
+
+// missingBrokers returns the sorted IDs in wanted that are not in known
+func missingBrokers(known []int32, wanted []int32) []int32 {
+	knownMap := make(map[int32]bool, len(known))
+	for _, id := range known {
+		knownMap[id] = true
+	}
+
+	missing := []int32{}
+	for _, id := range wanted {
+		if !knownMap[id] {
+			missing = append(missing, id)
+		}
+	}
+	sort.Slice(missing, func(i, j int) bool { return missing[i] < missing[j] })
+
+	return missing
+}
+
+const (
+	leaderThrottledRateConfig   = "leader.replication.throttled.rate"
+	followerThrottledRateConfig = "follower.replication.throttled.rate"
+)
+
+// ReassignmentThrottle records what throttling a reassignment changed, so
+// that removing the throttle restores the topic and brokers as they were
+type ReassignmentThrottle struct {
+	// Replicas are the pairs added to each throttled replicas config of the
+	// topic
+	Replicas map[string][]string
+	// Rates are the throttle rates each broker had before, empty when a rate
+	// was not set
+	Rates map[int32]map[string]string
+}
+
+// merge returns t with what newer recorded in addition. The rates t recorded
+// are kept, as newer may have read them back from t's own throttle.
+func (t ReassignmentThrottle) merge(newer ReassignmentThrottle) ReassignmentThrottle {
+	merged := ReassignmentThrottle{Replicas: map[string][]string{}, Rates: map[int32]map[string]string{}}
+	for _, from := range []ReassignmentThrottle{t, newer} {
+		for key, pairs := range from.Replicas {
+			merged.Replicas[key] = uniqueStrings(append(merged.Replicas[key], pairs...))
+		}
+		for broker, rates := range from.Rates {
+			if _, ok := merged.Rates[broker]; !ok {
+				merged.Rates[broker] = rates
+			}
+		}
+	}
+	return merged
+}
+
+// ThrottleReassignment limits the replication of the reassignment from
+// current to r.Replicas to rate bytes per second on each broker involved, as
+// kafka-reassign-partitions --throttle does: the current replicas are
+// throttled as leaders, the added ones as followers. It returns what it
+// changed, or nil when no replica is added and nothing is throttled.
+func (c *Client) ThrottleReassignment(r PartitionReassignment, current []int32, rate int) (*ReassignmentThrottle, error) {
+	leaders := throttledReplicaPairs(r.Partition, current)
+	followers := throttledReplicaPairs(r.Partition, missingBrokers(current, r.Replicas))
+	if len(followers) == 0 {
+		return nil, nil
+	}
+	if err := c.checkReassignmentBrokers(r); err != nil {
+		return nil, err
+	}
+
+	c.reassignmentThrottles.mutex.Lock()
+	defer c.reassignmentThrottles.mutex.Unlock()
+
+	log.Printf("[INFO] Throttling reassignment of %s to %d bytes per second", r.ID(), rate)
+	conf, err := c.TopicConfig(r.Topic)
+	if err != nil {
+		return nil, err
+	}
+	throttle := &ReassignmentThrottle{Replicas: map[string][]string{}, Rates: map[int32]map[string]string{}}
+	set := map[string]*string{}
+	for key, pairs := range map[string][]string{leaderThrottledReplicasConfig: leaders, followerThrottledReplicasConfig: followers} {
+		if value, added := addThrottledReplicas(conf[key], pairs); len(added) > 0 {
+			set[key] = &value
+			throttle.Replicas[key] = added
+		}
+	}
+	if err := c.AlterTopicConfig(r.Topic, set, nil); err != nil {
+		return nil, err
+	}
+
+	value := strconv.Itoa(rate)
+	rates := map[string]*string{leaderThrottledRateConfig: &value, followerThrottledRateConfig: &value}
+	for _, broker := range reassignmentBrokers(current, r.Replicas) {
+		bt := c.reassignmentThrottles.brokers[broker]
+		if bt == nil {
+			original, err := c.throttleRates(broker)
+			if err != nil {
+				return nil, err
+			}
+			bt = &brokerThrottle{rates: original}
+		}
+		if err := c.AlterBrokerConfig(sarama.BrokerResource, strconv.Itoa(int(broker)), rates, nil); err != nil {
+			return nil, err
+		}
+		bt.count++
+		c.reassignmentThrottles.brokers[broker] = bt
+		throttle.Rates[broker] = bt.rates
+	}
+	return throttle, nil
+}
+
+// RemoveReassignmentThrottle removes the throttle of the partition's
+// reassignment once it is done: the throttled replicas it added to the
+// topic, and the throttle rates of the brokers no other reassignment still
+// throttles, which are restored to what they were before
+func (c *Client) RemoveReassignmentThrottle(topic string, partition int32, throttle ReassignmentThrottle) error {
+	c.reassignmentThrottles.mutex.Lock()
+	defer c.reassignmentThrottles.mutex.Unlock()
+
+	log.Printf("[INFO] Removing the throttle of reassignment of %s-%d", topic, partition)
+	conf, err := c.TopicConfig(topic)
+	if err != nil {
+		return err
+	}
+	set := map[string]*string{}
+	remove := []string{}
+	for key, pairs := range throttle.Replicas {
+		value, changed := removeThrottledReplicas(conf[key], pairs)
+		switch {
+		case !changed:
+		case value == "":
+			remove = append(remove, key)
+		default:
+			set[key] = &value
+		}
+	}
+	if err := c.AlterTopicConfig(topic, set, remove); err != nil {
+		return err
+	}
+
+	brokers := make([]int32, 0, len(throttle.Rates))
+	for broker := range throttle.Rates {
+		brokers = append(brokers, broker)
+	}
+	sort.Slice(brokers, func(i, j int) bool { return brokers[i] < brokers[j] })
+
+	for _, broker := range brokers {
+		if bt := c.reassignmentThrottles.brokers[broker]; bt != nil && bt.count > 1 {
+			bt.count--
+			continue
+		}
+		delete(c.reassignmentThrottles.brokers, broker)
+		set, remove := restoredThrottleRates(throttle.Rates[broker])
+		if err := c.AlterBrokerConfig(sarama.BrokerResource, strconv.Itoa(int(broker)), set, remove); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// throttleRates returns the throttle rates set on the broker, empty when a
+// rate is not set
+func (c *Client) throttleRates(broker int32) (map[string]string, error) {
+	conf, err := c.BrokerConfig(sarama.BrokerResource, strconv.Itoa(int(broker)))
+	if err != nil {
+		return nil, err
+	}
+
+	rates := map[string]string{}
+	for _, key := range []string{leaderThrottledRateConfig, followerThrottledRateConfig} {
+		rates[key] = ""
+		if value := conf[key]; value != nil {
+			rates[key] = *value
+		}
+	}
+	return rates, nil
+}
+
+// restoredThrottleRates returns the throttle rates to set and to remove to
+// restore a broker's original rates
+func restoredThrottleRates(original map[string]string) (map[string]*string, []string) {
+	set := map[string]*string{}
+	remove := []string{}
+	for _, key := range []string{leaderThrottledRateConfig, followerThrottledRateConfig} {
+		if value := original[key]; value != "" {
+			set[key] = &value
+			continue
+		}
+		remove = append(remove, key)
+	}
+	return set, remove
+}
+
+// throttledReplicaPairs returns the partition:broker pairs of the replicas,
+// as the throttled replicas configs list them
+func throttledReplicaPairs(partition int32, replicas []int32) []string {
+	pairs := make([]string, 0, len(replicas))
+	for _, broker := range replicas {
+		pairs = append(pairs, fmt.Sprintf("%d:%d", partition, broker))
+	}
+	return pairs
+}
+
+// addThrottledReplicas adds the pairs to the throttled replicas config value,
+// returning those it did not hold yet. A value of * already throttles every
+// replica.
+func addThrottledReplicas(value *string, pairs []string) (string, []string) {
+	current := []string{}
+	if value != nil {
+		if *value == "*" {
+			return *value, nil
+		}
+		current = parseThrottledReplicas(*value)
+	}
+
+	held := make(map[string]bool, len(current))
+	for _, pair := range current {
+		held[pair] = true
+	}
+	added := []string{}
+	for _, pair := range uniqueStrings(pairs) {
+		if !held[pair] {
+			added = append(added, pair)
+		}
+	}
+	return formatThrottledReplicas(append(current, added...)), added
+}
+
+// removeThrottledReplicas removes the pairs from the throttled replicas
+// config value, reporting whether it changed
+func removeThrottledReplicas(value *string, pairs []string) (string, bool) {
+	if value == nil || *value == "*" {
+		return "", false
+	}
+
+	removed := make(map[string]bool, len(pairs))
+	for _, pair := range pairs {
+		removed[pair] = true
+	}
+	kept := []string{}
+	changed := false
+	for _, pair := range parseThrottledReplicas(*value) {
+		if removed[pair] {
+			changed = true
+			continue
+		}
+		kept = append(kept, pair)
+	}
+	return formatThrottledReplicas(kept), changed
+}
+
+// reassignmentBrokers returns the sorted brokers a reassignment moves
+// replicas on or off, every one of them replicating its data
+func reassignmentBrokers(current, target []int32) []int32 {
+	brokers := append([]int32{}, current...)
+	brokers = append(brokers, missingBrokers(current, target)...)
+	sort.Slice(brokers, func(i, j int) bool { return brokers[i] < brokers[j] })
+	return brokers
+}
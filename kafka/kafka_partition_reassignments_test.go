@@ -0,0 +1,98 @@
+package kafka
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_missingBrokers(t *testing.T) {
+	known := []int32{1, 2, 3}
+
+	if missing := missingBrokers(known, []int32{3, 1}); len(missing) != 0 {
+		t.Fatalf("expected no missing brokers, got %v", missing)
+	}
+
+	missing := missingBrokers(known, []int32{7, 2, 5})
+	if !reflect.DeepEqual(missing, []int32{5, 7}) {
+		t.Fatalf("expected brokers [5 7] to be missing, got %v", missing)
+	}
+}
+
+func Test_reassignmentThrottledReplicas(t *testing.T) {
+	value := "0:1,1:2"
+	added, pairs := addThrottledReplicas(&value, throttledReplicaPairs(1, []int32{2, 3}))
+	assertEquals(t, "0:1,1:2,1:3", added)
+	if !reflect.DeepEqual(pairs, []string{"1:3"}) {
+		t.Fatalf("expected %v, got %v", []string{"1:3"}, pairs)
+	}
+
+	_, pairs = addThrottledReplicas(&added, []string{"1:3"})
+	assertEquals(t, 0, len(pairs))
+
+	added, pairs = addThrottledReplicas(nil, []string{"1:3"})
+	assertEquals(t, "1:3", added)
+	if !reflect.DeepEqual(pairs, []string{"1:3"}) {
+		t.Fatalf("expected %v, got %v", []string{"1:3"}, pairs)
+	}
+
+	// * already throttles every replica, and is left alone
+	all := "*"
+	_, pairs = addThrottledReplicas(&all, []string{"1:3"})
+	assertEquals(t, 0, len(pairs))
+	_, changed := removeThrottledReplicas(&all, []string{"1:3"})
+	assertEquals(t, false, changed)
+
+	// only the added pairs are removed, not 1:2 that was there before
+	value = "0:1,1:2,1:3,11:1"
+	removed, changed := removeThrottledReplicas(&value, []string{"1:3"})
+	assertEquals(t, "0:1,11:1,1:2", removed)
+	assertEquals(t, true, changed)
+
+	value = "1:2"
+	removed, changed = removeThrottledReplicas(&value, []string{"1:2"})
+	assertEquals(t, "", removed)
+	assertEquals(t, true, changed)
+
+	_, changed = removeThrottledReplicas(&value, []string{"1:3"})
+	assertEquals(t, false, changed)
+	_, changed = removeThrottledReplicas(nil, []string{"1:2"})
+	assertEquals(t, false, changed)
+}
+
+func Test_restoredThrottleRates(t *testing.T) {
+	set, remove := restoredThrottleRates(map[string]string{leaderThrottledRateConfig: "1000", followerThrottledRateConfig: ""})
+	assertEquals(t, 1, len(set))
+	assertEquals(t, "1000", *set[leaderThrottledRateConfig])
+	if !reflect.DeepEqual(remove, []string{followerThrottledRateConfig}) {
+		t.Fatalf("expected %v, got %v", []string{followerThrottledRateConfig}, remove)
+	}
+}
+
+func Test_ReassignmentThrottleMerge(t *testing.T) {
+	prior := ReassignmentThrottle{
+		Replicas: map[string][]string{followerThrottledReplicasConfig: {"0:2"}},
+		Rates:    map[int32]map[string]string{1: {leaderThrottledRateConfig: "1000"}},
+	}
+	newer := ReassignmentThrottle{
+		Replicas: map[string][]string{followerThrottledReplicasConfig: {"0:2", "0:3"}, leaderThrottledReplicasConfig: {"0:1"}},
+		Rates:    map[int32]map[string]string{1: {leaderThrottledRateConfig: "2000"}, 3: {leaderThrottledRateConfig: ""}},
+	}
+
+	merged := prior.merge(newer)
+	if !reflect.DeepEqual(merged.Replicas[followerThrottledReplicasConfig], []string{"0:2", "0:3"}) {
+		t.Fatalf("expected %v, got %v", []string{"0:2", "0:3"}, merged.Replicas[followerThrottledReplicasConfig])
+	}
+	if !reflect.DeepEqual(merged.Replicas[leaderThrottledReplicasConfig], []string{"0:1"}) {
+		t.Fatalf("expected %v, got %v", []string{"0:1"}, merged.Replicas[leaderThrottledReplicasConfig])
+	}
+	// the rate of broker 1 read by newer is prior's throttle, not the original
+	assertEquals(t, "1000", merged.Rates[1][leaderThrottledRateConfig])
+	assertEquals(t, "", merged.Rates[3][leaderThrottledRateConfig])
+}
+
+func Test_reassignmentBrokers(t *testing.T) {
+	brokers := reassignmentBrokers([]int32{3, 1}, []int32{2, 3})
+	if !reflect.DeepEqual(brokers, []int32{1, 2, 3}) {
+		t.Fatalf("expected brokers [1 2 3], got %v", brokers)
+	}
+}
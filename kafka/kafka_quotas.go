@@ -23,7 +23,18 @@ type QuotaOp struct {
 type Quota struct {
 	EntityType string
 	EntityName string
-	Ops        []QuotaOp
+	// EntityDefault selects the default entity of EntityType rather than the
+	// entity named EntityName, which may be the empty string
+	EntityDefault bool
+	// ClientID is the client-id of a combined (user, client-id) quota
+	ClientID *QuotaEntity
+	Ops      []QuotaOp
+}
+
+// QuotaEntity is a component of a quota's entity
+type QuotaEntity struct {
+	Name    string
+	Default bool
 }
 
 func (a Quota) String() string {
@@ -32,34 +43,94 @@ func (a Quota) String() string {
 
 const entityDefault = "entity-default"
 
+func (e QuotaEntity) idPart(entityType string) string {
+	if e.Default {
+		return strings.Join([]string{entityDefault, entityType}, "|")
+	}
+	return strings.Join([]string{e.Name, entityType}, "|")
+}
+
 func (a Quota) ID() string {
-	if a.EntityName == "" {
-		return strings.Join([]string{entityDefault, a.EntityType}, "|")
+	id := QuotaEntity{Name: a.EntityName, Default: a.EntityDefault}.idPart(a.EntityType)
+	if a.ClientID != nil {
+		id = strings.Join([]string{id, a.ClientID.idPart(string(sarama.QuotaEntityClientID))}, "|")
 	}
-	return strings.Join([]string{a.EntityName, a.EntityType}, "|")
+	return id
 }
 
-func (c *Client) AlterQuota(quota Quota, validateOnly bool) error {
-	log.Printf("[INFO] Alter quota")
-	broker, err := c.client.Controller()
-	if err != nil {
-		return err
+// components returns the entity components of the quota, in the order Kafka
+// reports them
+func (a Quota) components() []sarama.QuotaEntityComponent {
+	components := []sarama.QuotaEntityComponent{
+		quotaEntityComponent(a.EntityType, QuotaEntity{Name: a.EntityName, Default: a.EntityDefault}),
 	}
+	if a.ClientID != nil {
+		components = append(components, quotaEntityComponent(string(sarama.QuotaEntityClientID), *a.ClientID))
+	}
+	return components
+}
 
-	var entity sarama.QuotaEntityComponent
-	if quota.EntityName == "" {
-		entity = sarama.QuotaEntityComponent{
-			EntityType: sarama.QuotaEntityType(quota.EntityType),
+func quotaEntityComponent(entityType string, e QuotaEntity) sarama.QuotaEntityComponent {
+	if e.Default {
+		return sarama.QuotaEntityComponent{
+			EntityType: sarama.QuotaEntityType(entityType),
 			MatchType:  sarama.QuotaMatchDefault,
 		}
-	} else {
-		entity = sarama.QuotaEntityComponent{
-			EntityType: sarama.QuotaEntityType(quota.EntityType),
-			MatchType:  sarama.QuotaMatchExact,
-			Name:       quota.EntityName,
+	}
+	return sarama.QuotaEntityComponent{
+		EntityType: sarama.QuotaEntityType(entityType),
+		MatchType:  sarama.QuotaMatchExact,
+		Name:       e.Name,
+	}
+}
+
+// quotaFromEntity builds a quota from the entity components of a describe
+// response, returning false if they do not form an entity the provider
+// manages
+func quotaFromEntity(entity []sarama.QuotaEntityComponent, values map[string]float64) (Quota, bool) {
+	q := Quota{}
+	for _, e := range entity {
+		qe := QuotaEntity{Name: e.Name, Default: e.MatchType == sarama.QuotaMatchDefault}
+		switch {
+		case q.EntityType == "":
+			q.EntityType = string(e.EntityType)
+			q.EntityName = qe.Name
+			q.EntityDefault = qe.Default
+		case e.EntityType == sarama.QuotaEntityClientID && q.EntityType == string(sarama.QuotaEntityUser) && q.ClientID == nil:
+			q.ClientID = &qe
+		case e.EntityType == sarama.QuotaEntityUser && q.EntityType == string(sarama.QuotaEntityClientID) && q.ClientID == nil:
+			// Kafka does not guarantee the order of the components
+			q.ClientID = &QuotaEntity{Name: q.EntityName, Default: q.EntityDefault}
+			q.EntityType = string(e.EntityType)
+			q.EntityName = qe.Name
+			q.EntityDefault = qe.Default
+		default:
+			return q, false
 		}
 	}
 
+	for k, v := range values {
+		q.Ops = append(q.Ops, QuotaOp{
+			Key:    k,
+			Value:  v,
+			Remove: false,
+		})
+	}
+
+	return q, q.EntityType != ""
+}
+
+func (c *Client) AlterQuota(quota Quota, validateOnly bool) error {
+	log.Printf("[INFO] Alter quota")
+	if err := c.requireAPI("altering quotas", apiKeyAlterClientQuotas); err != nil {
+		return err
+	}
+	broker, release, err := c.controller()
+	if err != nil {
+		return err
+	}
+	defer release()
+
 	configs := quota.Ops
 
 	ops := []sarama.ClientQuotasOp{}
@@ -72,7 +143,7 @@ func (c *Client) AlterQuota(quota Quota, validateOnly bool) error {
 	}
 
 	entry := sarama.AlterClientQuotasEntry{
-		Entity: []sarama.QuotaEntityComponent{entity},
+		Entity: quota.components(),
 		Ops:    ops,
 	}
 
@@ -90,37 +161,47 @@ func (c *Client) AlterQuota(quota Quota, validateOnly bool) error {
 	log.Printf("[TRACE] ThrottleTime: %d", quotaR.ThrottleTime)
 
 	for _, entry := range quotaR.Entries {
-		if entry.ErrorCode != sarama.ErrNoError {
-			return entry.ErrorCode
+		if err := newBrokerError("altering quota", quota.ID(), entry.ErrorCode, entry.ErrorMsg); err != nil {
+			return err
 		}
 	}
 
 	return nil
 }
 
+// DescribeQuota describes the quota of a single entity, which is the default
+// entity of entityType if entityName is empty
 func (c *Client) DescribeQuota(entityType string, entityName string) (*Quota, error) {
+	return c.DescribeQuotaEntity(Quota{
+		EntityType:    entityType,
+		EntityName:    entityName,
+		EntityDefault: entityName == "",
+	})
+}
+
+// DescribeQuotaEntity describes the quota of the entity of q
+func (c *Client) DescribeQuotaEntity(q Quota) (*Quota, error) {
 	log.Printf("[INFO] Describing Quota")
-	broker, err := c.client.Controller()
+	if err := c.requireAPI("describing quotas", apiKeyDescribeClientQuotas); err != nil {
+		return nil, err
+	}
+	broker, release, err := c.controller()
 	if err != nil {
 		return nil, err
 	}
-
-	var entity sarama.QuotaFilterComponent
-	if entityName == "" {
-		entity = sarama.QuotaFilterComponent{
-			EntityType: sarama.QuotaEntityType(entityType),
-			MatchType:  sarama.QuotaMatchDefault,
-		}
-	} else {
-		entity = sarama.QuotaFilterComponent{
-			EntityType: sarama.QuotaEntityType(entityType),
-			MatchType:  sarama.QuotaMatchExact,
-			Match:      entityName,
-		}
+	defer release()
+
+	components := []sarama.QuotaFilterComponent{}
+	for _, e := range q.components() {
+		components = append(components, sarama.QuotaFilterComponent{
+			EntityType: e.EntityType,
+			MatchType:  e.MatchType,
+			Match:      e.Name,
+		})
 	}
 
 	request := &sarama.DescribeClientQuotasRequest{
-		Components: []sarama.QuotaFilterComponent{entity},
+		Components: components,
 		Strict:     true,
 	}
 
@@ -132,35 +213,16 @@ func (c *Client) DescribeQuota(entityType string, entityName string) (*Quota, er
 
 	log.Printf("[TRACE] ThrottleTime: %d", quotaR.ThrottleTime)
 
-	if err == nil {
-		if quotaR.ErrorCode != sarama.ErrNoError {
-			return nil, fmt.Errorf("error describing quota %s", quotaR.ErrorCode)
-		}
-	}
-
-	if len(quotaR.Entries) < 1 {
-		return nil, QuotaMissingError{msg: fmt.Sprintf("%s could not be found", entityName)}
+	if err := newBrokerError("describing quota", q.ID(), quotaR.ErrorCode, quotaR.ErrorMsg); err != nil {
+		return nil, err
 	}
 
-	res := []Quota{}
 	for _, e := range quotaR.Entries {
-		ops := []QuotaOp{}
-		for k, v := range e.Values {
-			ops = append(ops, QuotaOp{
-				Key:    k,
-				Value:  v,
-				Remove: false,
-			})
-		}
-		for _, entry := range e.Entity {
-			q := Quota{
-				EntityType: string(entry.EntityType),
-				EntityName: entry.Name,
-				Ops:        ops,
-			}
-			res = append(res, q)
+		found, ok := quotaFromEntity(e.Entity, e.Values)
+		if ok && found.ID() == q.ID() {
+			return &found, nil
 		}
 	}
 
-	return &res[0], err
+	return nil, QuotaMissingError{msg: fmt.Sprintf("%s could not be found", q.ID())}
 }
@@ -0,0 +1,60 @@
+package kafka
+
+import (
+	"testing"
+
+	"github.com/IBM/sarama"
+)
+
+func TestQuota_ID(t *testing.T) {
+	for _, tc := range []struct {
+		quota Quota
+		id    string
+	}{
+		{Quota{EntityType: "client-id", EntityName: "app"}, "app|client-id"},
+		{Quota{EntityType: "client-id", EntityDefault: true}, "entity-default|client-id"},
+		{Quota{EntityType: "user"}, "|user"},
+		{Quota{EntityType: "user", EntityName: "alice", ClientID: &QuotaEntity{Name: "app"}}, "alice|user|app|client-id"},
+		{Quota{EntityType: "user", EntityDefault: true, ClientID: &QuotaEntity{Default: true}}, "entity-default|user|entity-default|client-id"},
+	} {
+		if id := tc.quota.ID(); id != tc.id {
+			t.Errorf("expected ID %q, got %q", tc.id, id)
+		}
+	}
+}
+
+func Test_quotaFromEntity(t *testing.T) {
+	user := sarama.QuotaEntityComponent{EntityType: sarama.QuotaEntityUser, MatchType: sarama.QuotaMatchExact, Name: "alice"}
+	defaultClient := sarama.QuotaEntityComponent{EntityType: sarama.QuotaEntityClientID, MatchType: sarama.QuotaMatchDefault}
+	ip := sarama.QuotaEntityComponent{EntityType: sarama.QuotaEntityIP, MatchType: sarama.QuotaMatchExact, Name: "10.0.0.1"}
+
+	for _, tc := range []struct {
+		name   string
+		entity []sarama.QuotaEntityComponent
+		id     string
+		ok     bool
+	}{
+		{"user", []sarama.QuotaEntityComponent{user}, "alice|user", true},
+		{"default client-id", []sarama.QuotaEntityComponent{defaultClient}, "entity-default|client-id", true},
+		{"user and client-id", []sarama.QuotaEntityComponent{user, defaultClient}, "alice|user|entity-default|client-id", true},
+		{"client-id and user", []sarama.QuotaEntityComponent{defaultClient, user}, "alice|user|entity-default|client-id", true},
+		{"ip and user", []sarama.QuotaEntityComponent{ip, user}, "", false},
+		{"empty", nil, "", false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			q, ok := quotaFromEntity(tc.entity, map[string]float64{"consumer_byte_rate": 100})
+			if ok != tc.ok {
+				t.Fatalf("expected ok %v, got %v", tc.ok, ok)
+			}
+			if !ok {
+				return
+			}
+			if q.ID() != tc.id {
+				t.Errorf("expected ID %q, got %q", tc.id, q.ID())
+			}
+			if len(q.Ops) != 1 || q.Ops[0].Key != "consumer_byte_rate" || q.Ops[0].Value != 100 {
+				t.Errorf("expected the consumer_byte_rate op, got %v", q.Ops)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package kafka
+
+import (
+	"errors"
+	"log"
+
+	"github.com/IBM/sarama"
+)
+
+// TopicConfig returns the config set on the topic itself, read from Kafka
+// rather than the cache
+func (c *Client) TopicConfig(topic string) (map[string]*string, error) {
+	conf, err := c.topicConfig(topic)
+	if err != nil {
+		return nil, topicReadError(topic, err)
+	}
+	return conf, nil
+}
+
+// DescribeTopicConfig returns every config entry of the topic, including
+// those it inherits, each with the source of its value
+func (c *Client) DescribeTopicConfig(topic string) ([]sarama.ConfigEntry, error) {
+	sc, release := c.acquireClient()
+	defer release()
+	admin, err := sarama.NewClusterAdminFromClient(sc)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := admin.DescribeConfig(sarama.ConfigResource{Type: sarama.TopicResource, Name: topic})
+	if err != nil {
+		var describeErr *sarama.DescribeConfigError
+		if errors.As(err, &describeErr) {
+			return nil, topicReadError(topic, describeErr.Err)
+		}
+		return nil, err
+	}
+	return entries, nil
+}
+
+// AlterTopicConfig sets and removes config entries of the topic, leaving
+// its other entries as they are. Removed entries revert to the broker's
+// default.
+func (c *Client) AlterTopicConfig(topic string, set map[string]*string, remove []string) error {
+	c.InvalidateTopicConfigCache(topic)
+	if len(set) == 0 && len(remove) == 0 {
+		return nil
+	}
+
+	log.Printf("[INFO] Altering config of topic %s: setting %v, removing %v", topic, strPtrMapToStrMap(set), remove)
+	if c.canIncrementalAlterConfigs() {
+		return c.incrementalAlterTopicConfig(topic, set, remove)
+	}
+
+	// without incremental alters the topic's whole config is replaced, so the
+	// entries not managed here are read and sent back as they are
+	log.Printf("[WARN] IncrementalAlterConfigs is not supported by the cluster, replacing the whole config of topic %s", topic)
+	current, err := c.TopicConfig(topic)
+	if err != nil {
+		return err
+	}
+	for _, key := range remove {
+		delete(current, key)
+	}
+	for key, value := range set {
+		current[key] = value
+	}
+
+	return c.UpdateTopic(Topic{Name: topic, Config: current})
+}
+
+func (c *Client) canIncrementalAlterConfigs() bool {
+	return c.SupportsAPI(apiKeyIncrementalAlterConfigs, 0)
+}
+
+func (c *Client) incrementalAlterTopicConfig(topic string, set map[string]*string, remove []string) error {
+	broker, release, err := c.controller()
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	entries := make(map[string]sarama.IncrementalAlterConfigsEntry, len(set)+len(remove))
+	for key, value := range set {
+		entries[key] = sarama.IncrementalAlterConfigsEntry{
+			Operation: sarama.IncrementalAlterConfigsOperationSet,
+			Value:     value,
+		}
+	}
+	for _, key := range remove {
+		entries[key] = sarama.IncrementalAlterConfigsEntry{
+			Operation: sarama.IncrementalAlterConfigsOperationDelete,
+		}
+	}
+
+	res, err := broker.IncrementalAlterConfigs(&sarama.IncrementalAlterConfigsRequest{
+		Resources: []*sarama.IncrementalAlterConfigsResource{
+			{
+				Type:          sarama.TopicResource,
+				Name:          topic,
+				ConfigEntries: entries,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, r := range res.Resources {
+		if err := newBrokerError("altering config of topic", topic, sarama.KError(r.ErrorCode), &r.ErrorMsg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
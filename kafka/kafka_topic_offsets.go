@@ -0,0 +1,94 @@
+package kafka
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/IBM/sarama"
+)
+
+// PartitionOffsets are the offsets of a partition's log, as its leader
+// reports them
+type PartitionOffsets struct {
+	Partition int32
+	// Earliest is the offset of the first record still in the log, or -1
+	// while the partition is empty
+	Earliest int64
+	// Latest is the offset the next record is written at, the high
+	// watermark, or -1 while the partition is empty
+	Latest int64
+	// ForTimestamp is the offset of the first record at or after the
+	// timestamp looked up, or -1 if there is none or none was looked up
+	ForTimestamp int64
+}
+
+// TopicOffsets returns the offsets of each partition of the topic, sorted by
+// partition. When timestamp is set, in milliseconds since the epoch, the
+// offset of the first record at or after it is looked up too. Every offset of
+// an empty partition is -1.
+func (c *Client) TopicOffsets(topic string, timestamp *int64) ([]PartitionOffsets, error) {
+	sc, release := c.acquireClient()
+	defer release()
+	if timestamp != nil && !c.kafkaConfig.Version.IsAtLeast(sarama.V0_10_1_0) {
+		return nil, fmt.Errorf("looking up offsets by timestamp requires Kafka 0.10.1.0 or later, but kafka_version is %s", c.kafkaConfig.Version)
+	}
+
+	partitions, err := sc.Partitions(topic)
+	if errors.Is(err, sarama.ErrUnknownTopicOrPartition) {
+		return nil, TopicMissingError{msg: fmt.Sprintf("%s could not be found", topic)}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	earliest, err := c.topicOffsets(topic, partitions, sarama.OffsetOldest)
+	if err != nil {
+		return nil, err
+	}
+	latest, err := c.topicOffsets(topic, partitions, sarama.OffsetNewest)
+	if err != nil {
+		return nil, err
+	}
+	var forTimestamp map[int32]int64
+	if timestamp != nil {
+		forTimestamp, err = c.topicOffsets(topic, partitions, *timestamp)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	offsets := make([]PartitionOffsets, 0, len(partitions))
+	for _, p := range partitions {
+		o := PartitionOffsets{
+			Partition:    p,
+			Earliest:     earliest[p],
+			Latest:       latest[p],
+			ForTimestamp: -1,
+		}
+		// brokers answer a timestamp after the last record with -1, or with
+		// the high watermark, depending on their version
+		if offset, ok := forTimestamp[p]; ok && offset >= 0 && offset < o.Latest {
+			o.ForTimestamp = offset
+		}
+		if o.Earliest == o.Latest {
+			o.Earliest, o.Latest = -1, -1
+		}
+		offsets = append(offsets, o)
+	}
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i].Partition < offsets[j].Partition })
+	return offsets, nil
+}
+
+func flattenPartitionOffsets(offsets []PartitionOffsets) []interface{} {
+	res := make([]interface{}, 0, len(offsets))
+	for _, o := range offsets {
+		res = append(res, map[string]interface{}{
+			"partition":        int(o.Partition),
+			"earliest_offset":  int(o.Earliest),
+			"latest_offset":    int(o.Latest),
+			"timestamp_offset": int(o.ForTimestamp),
+		})
+	}
+	return res
+}
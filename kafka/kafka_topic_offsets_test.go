@@ -0,0 +1,62 @@
+package kafka
+
+import (
+	"testing"
+
+	"github.com/IBM/sarama"
+)
+
+func Test_TopicOffsets(t *testing.T) {
+	mb := sarama.NewMockBroker(t, 1)
+	defer mb.Close()
+	mb.SetHandlerByMap(map[string]sarama.MockResponse{
+		"ApiVersionsRequest": sarama.NewMockApiVersionsResponse(t),
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(mb.Addr(), mb.BrokerID()).
+			SetController(mb.BrokerID()).
+			SetLeader("syslog", 1, mb.BrokerID()).
+			SetLeader("syslog", 0, mb.BrokerID()),
+		"OffsetRequest": sarama.NewMockOffsetResponse(t).
+			SetOffset("syslog", 0, sarama.OffsetOldest, 10).
+			SetOffset("syslog", 0, sarama.OffsetNewest, 20).
+			SetOffset("syslog", 0, 1000, 15).
+			// an empty partition
+			SetOffset("syslog", 1, sarama.OffsetOldest, 5).
+			SetOffset("syslog", 1, sarama.OffsetNewest, 5).
+			SetOffset("syslog", 1, 1000, 5),
+	})
+
+	client, err := NewClient(&Config{
+		BootstrapServers: &[]string{mb.Addr()},
+		KafkaVersion:     "2.8.0",
+		Timeout:          5,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.client.Close()
+
+	offsets, err := client.TopicOffsets("syslog", nil)
+	assertNil(t, err)
+	assertEquals(t, 2, len(offsets))
+	assertEquals(t, PartitionOffsets{Partition: 0, Earliest: 10, Latest: 20, ForTimestamp: -1}, offsets[0])
+	assertEquals(t, PartitionOffsets{Partition: 1, Earliest: -1, Latest: -1, ForTimestamp: -1}, offsets[1])
+
+	// the high watermark a broker answers for an empty partition is -1 too
+	timestamp := int64(1000)
+	offsets, err = client.TopicOffsets("syslog", &timestamp)
+	assertNil(t, err)
+	assertEquals(t, int64(15), offsets[0].ForTimestamp)
+	assertEquals(t, int64(-1), offsets[1].ForTimestamp)
+
+	flat := flattenPartitionOffsets(offsets)
+	assertEquals(t, 15, flat[0].(map[string]interface{})["timestamp_offset"])
+	assertEquals(t, -1, flat[1].(map[string]interface{})["timestamp_offset"])
+	assertEquals(t, -1, flat[1].(map[string]interface{})["earliest_offset"])
+	assertEquals(t, -1, flat[1].(map[string]interface{})["latest_offset"])
+
+	_, err = client.TopicOffsets("missing", nil)
+	if _, ok := err.(TopicMissingError); !ok {
+		t.Errorf("expected a TopicMissingError, got %v", err)
+	}
+}
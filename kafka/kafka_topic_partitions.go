@@ -0,0 +1,91 @@
+package kafka
+
+import (
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/IBM/sarama"
+)
+
+// PartitionState is the live state of a partition, as the brokers report it
+// in their metadata
+type PartitionState struct {
+	Partition int32
+	// Leader is the ID of the leader broker, or -1 while the partition is
+	// offline
+	Leader          int32
+	Replicas        []int32
+	InSyncReplicas  []int32
+	OfflineReplicas []int32
+}
+
+// TopicPartitionStates returns the state of each partition of the topic,
+// sorted by partition, read from fresh metadata rather than the cache
+func (c *Client) TopicPartitionStates(name string) ([]PartitionState, error) {
+	sc, release := c.acquireClient()
+	defer release()
+	broker := sc.LeastLoadedBroker()
+	if broker == nil {
+		return nil, sarama.ErrOutOfBrokers
+	}
+
+	res, err := broker.GetMetadata(sarama.NewMetadataRequest(c.kafkaConfig.Version, []string{name}))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, topic := range res.Topics {
+		if topic.Name != name {
+			continue
+		}
+		if topic.Err == sarama.ErrUnknownTopicOrPartition {
+			return nil, TopicMissingError{msg: fmt.Sprintf("%s could not be found", name)}
+		}
+		if err := newBrokerError("reading partitions of topic", name, topic.Err, nil); err != nil {
+			return nil, err
+		}
+
+		states := make([]PartitionState, 0, len(topic.Partitions))
+		for _, p := range topic.Partitions {
+			// an offline partition comes with an error code, and is reported
+			// as it is rather than failing the whole topic
+			if p.Err != sarama.ErrNoError {
+				log.Printf("[WARN] Partition %d of topic %s: %s", p.ID, name, p.Err)
+			}
+			states = append(states, PartitionState{
+				Partition:       p.ID,
+				Leader:          p.Leader,
+				Replicas:        p.Replicas,
+				InSyncReplicas:  p.Isr,
+				OfflineReplicas: p.OfflineReplicas,
+			})
+		}
+		sort.Slice(states, func(i, j int) bool { return states[i].Partition < states[j].Partition })
+		return states, nil
+	}
+
+	return nil, TopicMissingError{msg: fmt.Sprintf("%s could not be found", name)}
+}
+
+func flattenPartitionStates(states []PartitionState) []interface{} {
+	res := make([]interface{}, 0, len(states))
+	for _, s := range states {
+		res = append(res, map[string]interface{}{
+			"partition":        int(s.Partition),
+			"leader":           int(s.Leader),
+			"replicas":         flattenBrokerIDs(s.Replicas),
+			"in_sync_replicas": flattenBrokerIDs(s.InSyncReplicas),
+			"offline_replicas": flattenBrokerIDs(s.OfflineReplicas),
+		})
+	}
+	return res
+}
+
+func flattenBrokerIDs(ids []int32) []interface{} {
+	res := make([]interface{}, 0, len(ids))
+	for _, id := range ids {
+		res = append(res, int(id))
+	}
+	return res
+}
@@ -0,0 +1,47 @@
+package kafka
+
+import (
+	"testing"
+
+	"github.com/IBM/sarama"
+)
+
+func Test_TopicPartitionStates(t *testing.T) {
+	mb := sarama.NewMockBroker(t, 1)
+	defer mb.Close()
+	mb.SetHandlerByMap(map[string]sarama.MockResponse{
+		"ApiVersionsRequest": sarama.NewMockApiVersionsResponse(t),
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(mb.Addr(), mb.BrokerID()).
+			SetController(mb.BrokerID()).
+			SetLeader("syslog", 1, -1).
+			SetLeader("syslog", 0, mb.BrokerID()),
+	})
+
+	client, err := NewClient(&Config{
+		BootstrapServers: &[]string{mb.Addr()},
+		Timeout:          5,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.client.Close()
+
+	states, err := client.TopicPartitionStates("syslog")
+	assertNil(t, err)
+	assertEquals(t, 2, len(states))
+	assertEquals(t, int32(0), states[0].Partition)
+	assertEquals(t, mb.BrokerID(), states[0].Leader)
+	assertEquals(t, 1, len(states[0].InSyncReplicas))
+	// the offline partition is reported rather than failing the topic
+	assertEquals(t, int32(1), states[1].Partition)
+	assertEquals(t, int32(-1), states[1].Leader)
+
+	flat := flattenPartitionStates(states)
+	assertEquals(t, -1, flat[1].(map[string]interface{})["leader"])
+
+	_, err = client.TopicPartitionStates("missing")
+	if _, ok := err.(TopicMissingError); !ok {
+		t.Errorf("expected a TopicMissingError, got %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+package kafka
+
+import (
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/IBM/sarama"
+)
+
+// TopicSummary is a topic as listed in the brokers' metadata
+type TopicSummary struct {
+	Name       string
+	Partitions int
+	// Internal is set for the topics Kafka uses itself, e.g.
+	// __consumer_offsets
+	Internal bool
+}
+
+// ListTopics returns every topic of the cluster, sorted by name, read from
+// fresh metadata rather than the cache, which only holds the topics used so
+// far unless metadata_full is set. Topics the metadata has an error for, e.g.
+// LEADER_NOT_AVAILABLE while one is being created, are left out rather than
+// failing the whole listing.
+func (c *Client) ListTopics() ([]TopicSummary, error) {
+	sc, release := c.acquireClient()
+	defer release()
+	broker := sc.LeastLoadedBroker()
+	if broker == nil {
+		return nil, sarama.ErrOutOfBrokers
+	}
+
+	// no topics asks for all of them
+	res, err := broker.GetMetadata(sarama.NewMetadataRequest(c.kafkaConfig.Version, nil))
+	if err != nil {
+		return nil, err
+	}
+
+	topics := make([]TopicSummary, 0, len(res.Topics))
+	for _, topic := range res.Topics {
+		if err := newBrokerError("listing topic", topic.Name, topic.Err, nil); err != nil {
+			log.Printf("[WARN] Leaving out topic %s: %s", topic.Name, err)
+			continue
+		}
+		topics = append(topics, TopicSummary{
+			Name:       topic.Name,
+			Partitions: len(topic.Partitions),
+			Internal:   isInternalTopic(topic),
+		})
+	}
+	sort.Slice(topics, func(i, j int) bool { return topics[i].Name < topics[j].Name })
+	return topics, nil
+}
+
+// isInternalTopic reports whether Kafka marks the topic as internal, or its
+// name starts with the __ that Kafka and managed services reserve for their
+// own topics, e.g. __amazon_msk_canary, which are not marked
+func isInternalTopic(topic *sarama.TopicMetadata) bool {
+	return topic.IsInternal || strings.HasPrefix(topic.Name, "__")
+}
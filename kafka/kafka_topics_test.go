@@ -0,0 +1,75 @@
+package kafka
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/IBM/sarama"
+)
+
+func Test_ListTopics(t *testing.T) {
+	mb := sarama.NewMockBroker(t, 1)
+	defer mb.Close()
+	mb.SetHandlerByMap(map[string]sarama.MockResponse{
+		"ApiVersionsRequest": sarama.NewMockApiVersionsResponse(t),
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(mb.Addr(), mb.BrokerID()).
+			SetController(mb.BrokerID()).
+			SetLeader("syslog", 0, mb.BrokerID()).
+			SetLeader("syslog", 1, mb.BrokerID()).
+			SetLeader("__consumer_offsets", 0, mb.BrokerID()).
+			SetLeader("audit", 0, mb.BrokerID()),
+	})
+
+	client, err := NewClient(&Config{
+		BootstrapServers: &[]string{mb.Addr()},
+		Timeout:          5,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.client.Close()
+
+	topics, err := client.ListTopics()
+	assertNil(t, err)
+	expected := []TopicSummary{
+		{Name: "__consumer_offsets", Partitions: 1, Internal: true},
+		{Name: "audit", Partitions: 1},
+		{Name: "syslog", Partitions: 2},
+	}
+	if !reflect.DeepEqual(expected, topics) {
+		t.Errorf("expected %v, got %v", expected, topics)
+	}
+}
+
+func Test_ListTopics_TopicError(t *testing.T) {
+	mb := sarama.NewMockBroker(t, 1)
+	defer mb.Close()
+	mb.SetHandlerByMap(map[string]sarama.MockResponse{
+		"ApiVersionsRequest": sarama.NewMockApiVersionsResponse(t),
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(mb.Addr(), mb.BrokerID()).
+			SetController(mb.BrokerID()).
+			SetLeader("syslog", 0, mb.BrokerID()).
+			SetError("creating", sarama.ErrLeaderNotAvailable),
+	})
+
+	client, err := NewClient(&Config{
+		BootstrapServers: &[]string{mb.Addr()},
+		Timeout:          5,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.client.Close()
+
+	// a topic being created does not fail the listing of the others
+	topics, err := client.ListTopics()
+	assertNil(t, err)
+	expected := []TopicSummary{
+		{Name: "syslog", Partitions: 1},
+	}
+	if !reflect.DeepEqual(expected, topics) {
+		t.Errorf("expected %v, got %v", expected, topics)
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"crypto/rand"
 	"fmt"
 	"log"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/IBM/sarama"
 )
@@ -14,6 +16,10 @@ type UserScramCredential struct {
 	Mechanism  sarama.ScramMechanismType
 	Iterations int32
 	Password   []byte
+	// Salt is used as given, or else a random salt of SaltLength bytes, or
+	// saltSize if unset, is generated
+	Salt       []byte
+	SaltLength int
 }
 
 func (usc UserScramCredential) String() string {
@@ -32,37 +38,62 @@ func (e UserScramCredentialMissingError) Error() string { return e.msg }
 
 const (
 	saltSize = 64
+	// Kafka rejects empty salts; the maximum keeps the credential small
+	scramSaltMinLength = 1
+	scramSaltMaxLength = 1024
 )
 
-func (c *Client) UpsertUserScramCredential(userScramCredential UserScramCredential) error {
-	log.Printf("[INFO] Upserting user scram credential %v", userScramCredential)
-	admin, err := sarama.NewClusterAdminFromClient(c.client)
-	if err != nil {
-		return err
+// scramCredentialAlteration is an upsert or a deletion of a credential
+// queued to be sent along with others in one AlterUserScramCredentials
+// request
+type scramCredentialAlteration struct {
+	upsert *sarama.AlterUserScramCredentialsUpsert
+	delete *sarama.AlterUserScramCredentialsDelete
+}
+
+func (a scramCredentialAlteration) user() string {
+	if a.upsert != nil {
+		return a.upsert.Name
 	}
+	return a.delete.Name
+}
 
+func (c *Client) UpsertUserScramCredential(userScramCredential UserScramCredential) error {
+	log.Printf("[INFO] Upserting user scram credential %v", userScramCredential)
 	upsert, err := prepareUpsert(userScramCredential)
 	if err != nil {
 		return err
 	}
 
-	results, err := admin.UpsertUserScramCredentials([]sarama.AlterUserScramCredentialsUpsert{upsert})
+	return c.enqueueAlterUserScramCredential(scramCredentialAlteration{upsert: &upsert})
+}
+
+func (c *Client) DescribeUserScramCredential(username string, mechanism string) (*UserScramCredential, error) {
+	credentials, err := c.DescribeUserScramCredentials(username)
 	if err != nil {
-		return err
+		return nil, err
 	}
-
-	for _, res := range results {
-		if res.ErrorCode != sarama.ErrNoError {
-			return res.ErrorCode
+	for _, credential := range credentials {
+		if credential.Mechanism.String() == mechanism {
+			r := credential
+			return &r, nil
 		}
 	}
 
-	return nil
+	msg := fmt.Sprintf("User scram credential %s with mechanism %s could not be found", username, mechanism)
+	return nil, UserScramCredentialMissingError{msg: msg}
 }
 
-func (c *Client) DescribeUserScramCredential(username string, mechanism string) (*UserScramCredential, error) {
+// DescribeUserScramCredentials returns the credentials of every mechanism
+// the user has, sorted by mechanism. Kafka never returns their passwords.
+func (c *Client) DescribeUserScramCredentials(username string) ([]UserScramCredential, error) {
+	sc, release := c.acquireClient()
+	defer release()
 	log.Printf("[INFO] Describing user scram credential %s", username)
-	admin, err := sarama.NewClusterAdminFromClient(c.client)
+	if err := c.requireAPI("describing user scram credentials", apiKeyDescribeUserScramCredentials); err != nil {
+		return nil, err
+	}
+	admin, err := sarama.NewClusterAdminFromClient(sc)
 	if err != nil {
 		return nil, err
 	}
@@ -82,44 +113,125 @@ func (c *Client) DescribeUserScramCredential(username string, mechanism string)
 		msg := fmt.Sprintf("User scram credential %s could not be found", username)
 		return nil, UserScramCredentialMissingError{msg: msg}
 	}
-	if res.ErrorCode != sarama.ErrNoError {
-		return nil, fmt.Errorf("error describing user scram credential %s: %s", username, res.ErrorCode)
+	if err := newBrokerError("describing user scram credential", username, res.ErrorCode, res.ErrorMessage); err != nil {
+		return nil, err
 	}
+
+	credentials := make([]UserScramCredential, 0, len(res.CredentialInfos))
 	for _, info := range res.CredentialInfos {
-		if info.Mechanism.String() == mechanism {
-			r := UserScramCredential{
-				Name:       username,
-				Mechanism:  info.Mechanism,
-				Iterations: info.Iterations,
-			}
-			return &r, nil
-		}
+		credentials = append(credentials, UserScramCredential{
+			Name:       username,
+			Mechanism:  info.Mechanism,
+			Iterations: info.Iterations,
+		})
 	}
+	sort.Slice(credentials, func(i, j int) bool {
+		return credentials[i].Mechanism < credentials[j].Mechanism
+	})
 
-	msg := fmt.Sprintf("User scram credential %s with mechanism %s could not be found", username, mechanism)
-	return nil, UserScramCredentialMissingError{msg: msg}
+	return credentials, nil
 }
 
 func (c *Client) DeleteUserScramCredential(userScramCredential UserScramCredential) error {
 	log.Printf("[INFO] Deleting user scram credential %v", userScramCredential)
-	admin, err := sarama.NewClusterAdminFromClient(c.client)
+
+	// only the credential of this mechanism is deleted, so a user can have
+	// one of each mechanism managed independently, e.g. while rotating
+	delete := prepareDelete(userScramCredential)
+	return c.enqueueAlterUserScramCredential(scramCredentialAlteration{delete: &delete})
+}
+
+// enqueueAlterUserScramCredential queues the upsert or deletion, sending
+// every alteration queued within the queue's window in a single
+// AlterUserScramCredentials request, e.g. when many users are provisioned
+// in one apply
+func (c *Client) enqueueAlterUserScramCredential(alteration scramCredentialAlteration) error {
+	c.scramCredentialQueue.mutex.Lock()
+	log.Printf("[DEBUG] Enqueueing user scram credential alteration of %s", alteration.user())
+	if c.scramCredentialQueue.timer != nil {
+		c.scramCredentialQueue.timer.Stop()
+	}
+	c.scramCredentialQueue.alterations = append(c.scramCredentialQueue.alterations, alteration)
+	c.scramCredentialQueue.waitChans = append(c.scramCredentialQueue.waitChans, make(chan error))
+	var waitChan = c.scramCredentialQueue.waitChans[len(c.scramCredentialQueue.waitChans)-1]
+
+	c.scramCredentialQueue.timer = time.AfterFunc(c.scramCredentialQueue.after, func() {
+		c.scramCredentialQueue.mutex.Lock()
+		defer c.scramCredentialQueue.mutex.Unlock()
+		log.Printf("[INFO] Altering %d user scram credentials", len(c.scramCredentialQueue.alterations))
+		defer func() {
+			c.scramCredentialQueue.timer = nil
+			c.scramCredentialQueue.alterations = nil
+			c.scramCredentialQueue.waitChans = nil
+		}()
+
+		errs := c.alterUserScramCredentials(c.scramCredentialQueue.alterations)
+		for i, wc := range c.scramCredentialQueue.waitChans {
+			wc <- errs[i]
+		}
+	})
+
+	c.scramCredentialQueue.mutex.Unlock()
+	return <-waitChan
+}
+
+// alterUserScramCredentials sends the alterations in one request and returns
+// the error of each. Kafka reports a single result per user, so each
+// alteration gets the result of its user.
+func (c *Client) alterUserScramCredentials(alterations []scramCredentialAlteration) []error {
+	errs := make([]error, len(alterations))
+	fail := func(err error) []error {
+		for i := range errs {
+			errs[i] = err
+		}
+		return errs
+	}
+
+	if err := c.requireAPI("altering user scram credentials", apiKeyAlterUserScramCredentials); err != nil {
+		return fail(err)
+	}
+	broker, release, err := c.controller()
 	if err != nil {
-		return err
+		return fail(err)
 	}
+	defer release()
 
-	delete := prepareDelete(userScramCredential)
-	results, err := admin.DeleteUserScramCredentials([]sarama.AlterUserScramCredentialsDelete{delete})
+	var upserts []sarama.AlterUserScramCredentialsUpsert
+	var deletions []sarama.AlterUserScramCredentialsDelete
+	for _, a := range alterations {
+		if a.upsert != nil {
+			upserts = append(upserts, *a.upsert)
+		} else {
+			deletions = append(deletions, *a.delete)
+		}
+	}
+
+	res, err := broker.AlterUserScramCredentials(&sarama.AlterUserScramCredentialsRequest{
+		Upsertions: upserts,
+		Deletions:  deletions,
+	})
 	if err != nil {
-		return err
+		return fail(err)
+	}
+
+	userResults := make(map[string]*sarama.AlterUserScramCredentialsResult, len(res.Results))
+	for _, res := range res.Results {
+		userResults[res.User] = res
 	}
 
-	for _, res := range results {
-		if res.ErrorCode != sarama.ErrNoError {
-			return res.ErrorCode
+	for i, a := range alterations {
+		res, ok := userResults[a.user()]
+		switch {
+		case !ok:
+			errs[i] = fmt.Errorf("no result returned for user scram credential of %s", a.user())
+		case res.ErrorCode == 91 && a.delete != nil: // RESOURCE_NOT_FOUND
+			log.Printf("[WARN] User scram credential %s|%s was already deleted", a.delete.Name, a.delete.Mechanism)
+		default:
+			errs[i] = newBrokerError("altering user scram credential of", a.user(), res.ErrorCode, res.ErrorMessage)
 		}
 	}
 
-	return nil
+	return errs
 }
 
 func prepareUpsert(userScramCredential UserScramCredential) (sarama.AlterUserScramCredentialsUpsert, error) {
@@ -128,7 +240,16 @@ func prepareUpsert(userScramCredential UserScramCredential) (sarama.AlterUserScr
 	ret.Mechanism = userScramCredential.Mechanism
 	ret.Iterations = userScramCredential.Iterations
 	ret.Password = userScramCredential.Password
-	salt, err := generateRandomBytes(saltSize)
+	if len(userScramCredential.Salt) > 0 {
+		ret.Salt = append(ret.Salt, userScramCredential.Salt...)
+		return ret, nil
+	}
+
+	saltLength := userScramCredential.SaltLength
+	if saltLength == 0 {
+		saltLength = saltSize
+	}
+	salt, err := generateRandomBytes(saltLength)
 	ret.Salt = append(ret.Salt, salt...)
 	return ret, err
 }
@@ -0,0 +1,115 @@
+package kafka
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+func Test_alterUserScramCredentialsBatched(t *testing.T) {
+	mb := sarama.NewMockBroker(t, 1)
+	defer mb.Close()
+	mb.SetHandlerByMap(map[string]sarama.MockResponse{
+		"ApiVersionsRequest": sarama.NewMockApiVersionsResponse(t).SetApiKeys([]sarama.ApiVersionsResponseKey{
+			{ApiKey: apiKeyAlterUserScramCredentials, MinVersion: 0, MaxVersion: 0},
+		}),
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(mb.Addr(), mb.BrokerID()).
+			SetController(mb.BrokerID()),
+		"AlterUserScramCredentialsRequest": sarama.NewMockWrapper(&sarama.AlterUserScramCredentialsResponse{
+			Results: []*sarama.AlterUserScramCredentialsResult{
+				{User: "user-0", ErrorCode: sarama.ErrNoError},
+				{User: "user-1", ErrorCode: sarama.ErrNoError},
+				{User: "user-2", ErrorCode: sarama.ErrUnsupportedSASLMechanism},
+				{User: "gone", ErrorCode: 91}, // RESOURCE_NOT_FOUND
+			},
+		}),
+	})
+
+	client, err := NewClient(&Config{
+		BootstrapServers: &[]string{mb.Addr()},
+		Timeout:          5,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.client.Close()
+	client.scramCredentialQueue.after = 50 * time.Millisecond
+
+	errs := make([]error, 4)
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = client.UpsertUserScramCredential(UserScramCredential{
+				Name:       fmt.Sprintf("user-%d", i),
+				Mechanism:  sarama.SCRAM_MECHANISM_SHA_512,
+				Iterations: 4096,
+				Password:   []byte("password"),
+			})
+		}(i)
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		errs[3] = client.DeleteUserScramCredential(UserScramCredential{Name: "gone", Mechanism: sarama.SCRAM_MECHANISM_SHA_256})
+	}()
+	wg.Wait()
+
+	assertNil(t, errs[0])
+	assertNil(t, errs[1])
+	if !errors.Is(errs[2], sarama.ErrUnsupportedSASLMechanism) {
+		t.Errorf("expected %v for user-2, got %v", sarama.ErrUnsupportedSASLMechanism, errs[2])
+	}
+	// an already deleted credential is not an error
+	assertNil(t, errs[3])
+
+	var requests []*sarama.AlterUserScramCredentialsRequest
+	for _, rr := range mb.History() {
+		if req, ok := rr.Request.(*sarama.AlterUserScramCredentialsRequest); ok {
+			requests = append(requests, req)
+		}
+	}
+	assertEquals(t, 1, len(requests))
+	assertEquals(t, 3, len(requests[0].Upsertions))
+	assertEquals(t, 1, len(requests[0].Deletions))
+}
+
+func Test_prepareUpsertSalt(t *testing.T) {
+	usc := UserScramCredential{Name: "alice", Mechanism: sarama.SCRAM_MECHANISM_SHA_256, Iterations: 4096, Password: []byte("password")}
+
+	upsert, err := prepareUpsert(usc)
+	assertNil(t, err)
+	assertEquals(t, saltSize, len(upsert.Salt))
+
+	usc.SaltLength = 16
+	upsert, err = prepareUpsert(usc)
+	assertNil(t, err)
+	assertEquals(t, 16, len(upsert.Salt))
+
+	usc.Salt = []byte("fixed-salt")
+	upsert, err = prepareUpsert(usc)
+	assertNil(t, err)
+	assertEquals(t, "fixed-salt", string(upsert.Salt))
+}
+
+func Test_validateScramSalt(t *testing.T) {
+	for salt, valid := range map[string]bool{
+		base64.StdEncoding.EncodeToString([]byte("fixed-salt")):               true,
+		base64.StdEncoding.EncodeToString(make([]byte, scramSaltMaxLength)):   true,
+		base64.StdEncoding.EncodeToString(make([]byte, scramSaltMaxLength+1)): false,
+		"":           false,
+		"not base64": false,
+	} {
+		_, errs := validateScramSalt(salt, "salt")
+		if valid != (len(errs) == 0) {
+			t.Errorf("expected salt %.20q to be valid: %t, got %v", salt, valid, errs)
+		}
+	}
+}
@@ -1,25 +1,50 @@
 package kafka
 
 import (
+	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"log"
+	"net"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/IBM/sarama"
 )
 
+// pingTimeout bounds how long Ping waits for a bootstrap server
+const pingTimeout = 10 * time.Second
+
 type LazyClient struct {
 	once    sync.Once
 	initErr error
 	inner   *Client
 	Config  *Config
+
+	semOnce sync.Once
+	sem     chan void
 }
 
 func (c *LazyClient) init() error {
 	var err error
 
 	c.once.Do(func() {
+		// a quick probe fails fast with the reason for each bootstrap
+		// server, rather than after sarama's retries with a generic error
+		if c.Config != nil {
+			servers, err := c.reachableBootstrapServers()
+			if err != nil {
+				c.initErr = err
+				return
+			}
+			c.Config.BootstrapServers = &servers
+			if err := c.checkSASLMechanism(); err != nil {
+				c.initErr = err
+				return
+			}
+		}
 		c.inner, err = NewClient(c.Config)
 		c.initErr = err
 	})
@@ -29,7 +54,7 @@ func (c *LazyClient) init() error {
 	} else {
 		log.Printf("[TRACE] lazy client init %s", c.initErr)
 	}
-	if c.initErr == sarama.ErrBrokerNotAvailable || c.initErr == sarama.ErrOutOfBrokers {
+	if errors.Is(c.initErr, sarama.ErrBrokerNotAvailable) || errors.Is(c.initErr, sarama.ErrOutOfBrokers) {
 		if c.Config.TLSEnabled {
 			tlsError := c.checkTLSConfig()
 			if tlsError != nil {
@@ -41,6 +66,160 @@ func (c *LazyClient) init() error {
 	return c.initErr
 }
 
+// retry runs an admin operation, retrying it on transient Kafka errors as
+// configured in the provider's retry block. Each attempt waits for a slot
+// when max_concurrency is set, which is released while backing off. An
+// attempt failing with NOT_CONTROLLER refreshes the controller before the
+// next one, so that it goes to the newly elected controller.
+func (c *LazyClient) retry(ctx context.Context, op string, f func() error) error {
+	return c.retryWith(ctx, newRetryPolicy(c.Config), op, f)
+}
+
+// retryCreate retries a create like retry, except when it times out: it may
+// still have been applied, and is then not sent again
+func (c *LazyClient) retryCreate(ctx context.Context, op string, f func() error) error {
+	p := newRetryPolicy(c.Config)
+	p.retriable = isRetriableCreateError
+	return c.retryWith(ctx, p, op, f)
+}
+
+func (c *LazyClient) retryWith(ctx context.Context, p retryPolicy, op string, f func() error) error {
+	refresh := false
+	return p.do(ctx, op, func() error {
+		release := c.acquire(op)
+		defer release()
+		if refresh {
+			if _, err := c.RefreshController(); err != nil {
+				log.Printf("[WARN] Failed to refresh the controller for %s: %s", op, err)
+			}
+		}
+		err := f()
+		refresh = IsNotControllerError(err)
+		return err
+	})
+}
+
+// RefreshController discovers the controller again, so that the admin
+// requests that follow go to it, and returns its ID
+func (c *LazyClient) RefreshController() (int32, error) {
+	err := c.init()
+	if err != nil {
+		return noController, err
+	}
+	broker, err := c.inner.RefreshController()
+	if err != nil {
+		return noController, err
+	}
+	return broker.ID(), nil
+}
+
+// acquire waits until fewer than max_concurrency admin operations are
+// running, returning the func releasing the slot taken
+func (c *LazyClient) acquire(op string) func() {
+	c.semOnce.Do(func() {
+		if c.Config != nil && c.Config.MaxConcurrency > 0 {
+			c.sem = make(chan void, c.Config.MaxConcurrency)
+		}
+	})
+	if c.sem == nil {
+		return func() {}
+	}
+
+	select {
+	case c.sem <- member:
+	default:
+		log.Printf("[DEBUG] %s waiting for one of the %d concurrent admin operations to finish", op, cap(c.sem))
+		c.sem <- member
+	}
+	return func() { <-c.sem }
+}
+
+// Ping checks that at least one bootstrap server answers an ApiVersions
+// request, which brokers accept before authentication. The servers are tried
+// at the same time, each waiting at most pingTimeout.
+func (c *LazyClient) Ping() error {
+	kafkaConfig, err := c.probeKafkaConfig()
+	if err != nil {
+		return err
+	}
+	return pingBootstrapServers(*c.Config.BootstrapServers, kafkaConfig)
+}
+
+// reachableBootstrapServers returns the bootstrap servers when one of them
+// can be reached, or else the first group of failover_bootstrap_servers
+// that can be. Only when none can be is it an error, listing why for each
+// group.
+func (c *LazyClient) reachableBootstrapServers() ([]string, error) {
+	err := c.Ping()
+	if err == nil || len(c.Config.FailoverBootstrapServers) == 0 {
+		return *c.Config.BootstrapServers, err
+	}
+
+	kafkaConfig, probeErr := c.probeKafkaConfig()
+	if probeErr != nil {
+		return nil, probeErr
+	}
+	errs := []error{err}
+	for i, servers := range c.Config.FailoverBootstrapServers {
+		failoverErr := pingBootstrapServers(servers, kafkaConfig)
+		if failoverErr == nil {
+			log.Printf("[WARN] Failing over to failover_bootstrap_servers[%d] %v, as %s", i, servers, err)
+			return servers, nil
+		}
+		errs = append(errs, fmt.Errorf("failover_bootstrap_servers[%d]: %w", i, failoverErr))
+	}
+	return nil, errors.Join(errs...)
+}
+
+// pingBootstrapServers sends an ApiVersions request to each of the servers
+// at once, returning nil if any of them answered
+func pingBootstrapServers(servers []string, kafkaConfig *sarama.Config) error {
+	errs := make([]error, len(servers))
+	var wg sync.WaitGroup
+	for i, addr := range servers {
+		wg.Add(1)
+		go func(i int, addr string) {
+			defer wg.Done()
+			_, errs[i] = rawApiVersionsRequest(sarama.NewBroker(addr), kafkaConfig)
+		}(i, addr)
+	}
+	wg.Wait()
+
+	unreachable := make([]string, 0, len(servers))
+	for i, err := range errs {
+		if err == nil {
+			return nil
+		}
+		unreachable = append(unreachable, fmt.Sprintf("%s (%s)", servers[i], pingErrorReason(err)))
+	}
+
+	return fmt.Errorf("%w: could not reach any bootstrap server: %s", sarama.ErrOutOfBrokers, strings.Join(unreachable, ", "))
+}
+
+// probeKafkaConfig returns the config to probe the bootstrap servers with
+// before the client connects: without SASL, and waiting at most pingTimeout
+func (c *LazyClient) probeKafkaConfig() (*sarama.Config, error) {
+	kafkaConfig, err := c.Config.newKafkaConfig()
+	if err != nil {
+		return nil, err
+	}
+	kafkaConfig.Net.SASL.Enable = false
+	for _, t := range []*time.Duration{&kafkaConfig.Net.DialTimeout, &kafkaConfig.Net.ReadTimeout, &kafkaConfig.Net.WriteTimeout} {
+		if *t > pingTimeout {
+			*t = pingTimeout
+		}
+	}
+	return kafkaConfig, nil
+}
+
+func pingErrorReason(err error) string {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return "timeout"
+	}
+	return err.Error()
+}
+
 func (c *LazyClient) checkTLSConfig() error {
 	kafkaConfig, err := c.Config.newKafkaConfig()
 	if err != nil {
@@ -58,12 +237,31 @@ func (c *LazyClient) checkTLSConfig() error {
 	return conn.Handshake()
 }
 
-func (c *LazyClient) CreateTopic(t Topic) error {
+func (c *LazyClient) Rebootstrap() error {
 	err := c.init()
 	if err != nil {
 		return err
 	}
-	return c.inner.CreateTopic(t)
+	return c.inner.Rebootstrap()
+}
+
+func (c *LazyClient) CreateTopic(ctx context.Context, t Topic) error {
+	err := c.init()
+	if err != nil {
+		return err
+	}
+	// a create that timed out may still have been applied, in which case
+	// its retry finds the topic it created
+	timedOut := false
+	return c.retry(ctx, "CreateTopic", func() error {
+		err := c.inner.CreateTopic(t)
+		if timedOut && IsTopicExistsError(err) {
+			log.Printf("[WARN] Topic %s already exists after its creation timed out, assuming the timed out request created it", t.Name)
+			return nil
+		}
+		timedOut = timedOut || errors.Is(err, sarama.ErrRequestTimedOut)
+		return err
+	})
 }
 
 func (c *LazyClient) ReadTopic(name string, refresh_metadata bool) (Topic, error) {
@@ -74,28 +272,116 @@ func (c *LazyClient) ReadTopic(name string, refresh_metadata bool) (Topic, error
 	return c.inner.ReadTopic(name, refresh_metadata)
 }
 
-func (c *LazyClient) UpdateTopic(t Topic) error {
+func (c *LazyClient) UpdateTopic(ctx context.Context, t Topic) error {
+	err := c.init()
+	if err != nil {
+		return err
+	}
+	return c.retry(ctx, "UpdateTopic", func() error { return c.inner.UpdateTopic(t) })
+}
+
+func (c *LazyClient) TopicConfig(topic string) (map[string]*string, error) {
+	err := c.init()
+	if err != nil {
+		return nil, err
+	}
+	return c.inner.TopicConfig(topic)
+}
+
+func (c *LazyClient) TopicPropagated(name string) (bool, error) {
+	err := c.init()
+	if err != nil {
+		return false, err
+	}
+	return c.inner.TopicPropagated(name)
+}
+
+func (c *LazyClient) TopicPartitionStates(name string) ([]PartitionState, error) {
+	err := c.init()
+	if err != nil {
+		return nil, err
+	}
+	return c.inner.TopicPartitionStates(name)
+}
+
+func (c *LazyClient) ListTopics() ([]TopicSummary, error) {
+	err := c.init()
+	if err != nil {
+		return nil, err
+	}
+	return c.inner.ListTopics()
+}
+
+func (c *LazyClient) TopicOffsets(topic string, timestamp *int64) ([]PartitionOffsets, error) {
+	err := c.init()
+	if err != nil {
+		return nil, err
+	}
+	return c.inner.TopicOffsets(topic, timestamp)
+}
+
+func (c *LazyClient) DescribeTopicConfig(topic string) ([]sarama.ConfigEntry, error) {
+	err := c.init()
+	if err != nil {
+		return nil, err
+	}
+	return c.inner.DescribeTopicConfig(topic)
+}
+
+func (c *LazyClient) AlterTopicConfig(ctx context.Context, topic string, set map[string]*string, remove []string) error {
+	err := c.init()
+	if err != nil {
+		return err
+	}
+	return c.retry(ctx, "AlterTopicConfig", func() error { return c.inner.AlterTopicConfig(topic, set, remove) })
+}
+
+func (c *LazyClient) BrokerConfig(resourceType sarama.ConfigResourceType, broker string) (map[string]*string, error) {
+	err := c.init()
+	if err != nil {
+		return nil, err
+	}
+	return c.inner.BrokerConfig(resourceType, broker)
+}
+
+func (c *LazyClient) AlterBrokerConfig(ctx context.Context, resourceType sarama.ConfigResourceType, broker string, set map[string]*string, remove []string) error {
+	err := c.init()
+	if err != nil {
+		return err
+	}
+	return c.retry(ctx, "AlterBrokerConfig", func() error { return c.inner.AlterBrokerConfig(resourceType, broker, set, remove) })
+}
+
+func (c *LazyClient) DeleteTopic(ctx context.Context, t string) error {
+	err := c.init()
+	if err != nil {
+		return err
+	}
+	return c.retry(ctx, "DeleteTopic", func() error { return c.inner.DeleteTopic(t) })
+}
+
+func (c *LazyClient) AddPartitions(ctx context.Context, t Topic) error {
 	err := c.init()
 	if err != nil {
 		return err
 	}
-	return c.inner.UpdateTopic(t)
+	return c.retry(ctx, "AddPartitions", func() error { return c.inner.AddPartitions(t) })
 }
 
-func (c *LazyClient) DeleteTopic(t string) error {
+func (c *LazyClient) ValidateCreateTopic(t Topic) error {
 	err := c.init()
 	if err != nil {
 		return err
 	}
-	return c.inner.DeleteTopic(t)
+	return c.inner.ValidateCreateTopic(t)
 }
 
-func (c *LazyClient) AddPartitions(t Topic) error {
+func (c *LazyClient) ValidateUpdateTopic(t Topic) error {
 	err := c.init()
 	if err != nil {
 		return err
 	}
-	return c.inner.AddPartitions(t)
+	return c.inner.ValidateUpdateTopic(t)
 }
 
 func (c *LazyClient) CanAlterReplicationFactor() (bool, error) {
@@ -106,12 +392,28 @@ func (c *LazyClient) CanAlterReplicationFactor() (bool, error) {
 	return c.inner.CanAlterReplicationFactor(), nil
 }
 
-func (c *LazyClient) AlterReplicationFactor(t Topic) error {
+func (c *LazyClient) SupportsAPI(apiKey, version int) (bool, error) {
+	err := c.init()
+	if err != nil {
+		return false, err
+	}
+	return c.inner.SupportsAPI(apiKey, version), nil
+}
+
+func (c *LazyClient) AlterReplicationFactor(ctx context.Context, t Topic) error {
+	err := c.init()
+	if err != nil {
+		return err
+	}
+	return c.retry(ctx, "AlterReplicationFactor", func() error { return c.inner.AlterReplicationFactor(t) })
+}
+
+func (c *LazyClient) AlterReplicaAssignment(ctx context.Context, t Topic) error {
 	err := c.init()
 	if err != nil {
 		return err
 	}
-	return c.inner.AlterReplicationFactor(t)
+	return c.retry(ctx, "AlterReplicaAssignment", func() error { return c.inner.AlterReplicaAssignment(t) })
 }
 
 func (c *LazyClient) IsReplicationFactorUpdating(topic string) (bool, error) {
@@ -122,12 +424,28 @@ func (c *LazyClient) IsReplicationFactorUpdating(topic string) (bool, error) {
 	return c.inner.IsReplicationFactorUpdating(topic)
 }
 
-func (c *LazyClient) CreateACL(s StringlyTypedACL) error {
+func (c *LazyClient) CreateACL(ctx context.Context, s StringlyTypedACL) error {
+	err := c.init()
+	if err != nil {
+		return err
+	}
+	return c.retryCreate(ctx, "CreateACL", func() error { return c.inner.CreateACL(s) })
+}
+
+func (c *LazyClient) CreateACLs(ctx context.Context, acls []StringlyTypedACL) error {
+	err := c.init()
+	if err != nil {
+		return err
+	}
+	return c.retryCreate(ctx, "CreateACLs", func() error { return c.inner.CreateACLs(acls) })
+}
+
+func (c *LazyClient) DeleteACLs(ctx context.Context, acls []StringlyTypedACL) error {
 	err := c.init()
 	if err != nil {
 		return err
 	}
-	return c.inner.CreateACL(s)
+	return c.retry(ctx, "DeleteACLs", func() error { return c.inner.DeleteACLs(acls) })
 }
 
 func (c *LazyClient) InvalidateACLCache() error {
@@ -139,6 +457,14 @@ func (c *LazyClient) InvalidateACLCache() error {
 	return nil
 }
 
+func (c *LazyClient) FindACLs(s StringlyTypedACL) ([]StringlyTypedACL, error) {
+	err := c.init()
+	if err != nil {
+		return nil, err
+	}
+	return c.inner.FindACLs(s)
+}
+
 func (c *LazyClient) ListACLs() ([]*sarama.ResourceAcls, error) {
 	err := c.init()
 	if err != nil {
@@ -147,20 +473,20 @@ func (c *LazyClient) ListACLs() ([]*sarama.ResourceAcls, error) {
 	return c.inner.ListACLs()
 }
 
-func (c *LazyClient) DeleteACL(s StringlyTypedACL) error {
+func (c *LazyClient) DeleteACL(ctx context.Context, s StringlyTypedACL) error {
 	err := c.init()
 	if err != nil {
 		return err
 	}
-	return c.inner.DeleteACL(s)
+	return c.retry(ctx, "DeleteACL", func() error { return c.inner.DeleteACL(s) })
 }
 
-func (c *LazyClient) AlterQuota(q Quota) error {
+func (c *LazyClient) AlterQuota(ctx context.Context, q Quota) error {
 	err := c.init()
 	if err != nil {
 		return err
 	}
-	return c.inner.AlterQuota(q, false)
+	return c.retry(ctx, "AlterQuota", func() error { return c.inner.AlterQuota(q, false) })
 }
 
 func (c *LazyClient) DescribeQuota(entityType string, entityName string) (*Quota, error) {
@@ -171,12 +497,20 @@ func (c *LazyClient) DescribeQuota(entityType string, entityName string) (*Quota
 	return c.inner.DescribeQuota(entityType, entityName)
 }
 
-func (c *LazyClient) UpsertUserScramCredential(userScramCredential UserScramCredential) error {
+func (c *LazyClient) DescribeQuotaEntity(q Quota) (*Quota, error) {
+	err := c.init()
+	if err != nil {
+		return nil, err
+	}
+	return c.inner.DescribeQuotaEntity(q)
+}
+
+func (c *LazyClient) UpsertUserScramCredential(ctx context.Context, userScramCredential UserScramCredential) error {
 	err := c.init()
 	if err != nil {
 		return err
 	}
-	return c.inner.UpsertUserScramCredential(userScramCredential)
+	return c.retry(ctx, "UpsertUserScramCredential", func() error { return c.inner.UpsertUserScramCredential(userScramCredential) })
 }
 
 func (c *LazyClient) DescribeUserScramCredential(username string, mechanism string) (*UserScramCredential, error) {
@@ -187,10 +521,116 @@ func (c *LazyClient) DescribeUserScramCredential(username string, mechanism stri
 	return c.inner.DescribeUserScramCredential(username, mechanism)
 }
 
-func (c *LazyClient) DeleteUserScramCredential(userScramCredential UserScramCredential) error {
+func (c *LazyClient) DescribeUserScramCredentials(username string) ([]UserScramCredential, error) {
+	err := c.init()
+	if err != nil {
+		return nil, err
+	}
+	return c.inner.DescribeUserScramCredentials(username)
+}
+
+func (c *LazyClient) DeleteUserScramCredential(ctx context.Context, userScramCredential UserScramCredential) error {
 	err := c.init()
 	if err != nil {
 		return err
 	}
-	return c.inner.DeleteUserScramCredential(userScramCredential)
+	return c.retry(ctx, "DeleteUserScramCredential", func() error { return c.inner.DeleteUserScramCredential(userScramCredential) })
+}
+
+func (c *LazyClient) SetConsumerGroupOffsets(ctx context.Context, o ConsumerGroupOffsets) error {
+	err := c.init()
+	if err != nil {
+		return err
+	}
+	return c.retry(ctx, "SetConsumerGroupOffsets", func() error { return c.inner.SetConsumerGroupOffsets(o) })
+}
+
+func (c *LazyClient) ListConsumerGroupOffsets(group string, topic string) (map[int32]int64, error) {
+	err := c.init()
+	if err != nil {
+		return nil, err
+	}
+	return c.inner.ListConsumerGroupOffsets(group, topic)
+}
+
+func (c *LazyClient) ListConsumerGroups() ([]ConsumerGroup, error) {
+	err := c.init()
+	if err != nil {
+		return nil, err
+	}
+	return c.inner.ListConsumerGroups()
+}
+
+func (c *LazyClient) DescribeBrokers() ([]BrokerInfo, int32, error) {
+	err := c.init()
+	if err != nil {
+		return nil, noController, err
+	}
+	return c.inner.DescribeBrokers()
+}
+
+func (c *LazyClient) DescribeCluster() (ClusterInfo, error) {
+	err := c.init()
+	if err != nil {
+		return ClusterInfo{}, err
+	}
+	return c.inner.DescribeCluster()
+}
+
+func (c *LazyClient) DescribeConsumerGroups(groups []ConsumerGroup) ([]ConsumerGroup, error) {
+	err := c.init()
+	if err != nil {
+		return nil, err
+	}
+	return c.inner.DescribeConsumerGroups(groups)
+}
+
+func (c *LazyClient) ReassignPartition(ctx context.Context, r PartitionReassignment) error {
+	err := c.init()
+	if err != nil {
+		return err
+	}
+	return c.retry(ctx, "ReassignPartition", func() error { return c.inner.ReassignPartition(r) })
+}
+
+func (c *LazyClient) CancelPartitionReassignment(ctx context.Context, topic string, partition int32) error {
+	err := c.init()
+	if err != nil {
+		return err
+	}
+	return c.retry(ctx, "CancelPartitionReassignment", func() error { return c.inner.CancelPartitionReassignment(topic, partition) })
+}
+
+// ThrottleReassignment is not retried as a whole, as it counts the
+// reassignments throttling each broker
+func (c *LazyClient) ThrottleReassignment(r PartitionReassignment, current []int32, rate int) (*ReassignmentThrottle, error) {
+	err := c.init()
+	if err != nil {
+		return nil, err
+	}
+	return c.inner.ThrottleReassignment(r, current, rate)
+}
+
+func (c *LazyClient) RemoveReassignmentThrottle(topic string, partition int32, throttle ReassignmentThrottle) error {
+	err := c.init()
+	if err != nil {
+		return err
+	}
+	return c.inner.RemoveReassignmentThrottle(topic, partition, throttle)
+}
+
+func (c *LazyClient) PartitionReassignmentStatus(topic string, partition int32) (*PartitionReassignmentStatus, error) {
+	err := c.init()
+	if err != nil {
+		return nil, err
+	}
+	return c.inner.PartitionReassignmentStatus(topic, partition)
+}
+
+func (c *LazyClient) PartitionReplicas(topic string, partition int32) ([]int32, error) {
+	err := c.init()
+	if err != nil {
+		return nil, err
+	}
+	return c.inner.PartitionReplicas(topic, partition)
 }
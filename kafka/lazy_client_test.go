@@ -1,8 +1,14 @@
 package kafka
 
 import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/IBM/sarama"
 )
@@ -42,3 +48,258 @@ func Test_LazyClientWithConfigErrors(t *testing.T) {
 		t.Fatalf("expected err, got %v", err)
 	}
 }
+
+func Test_LazyClientPing(t *testing.T) {
+	mb := sarama.NewMockBroker(t, 1)
+	defer mb.Close()
+	mb.SetHandlerByMap(map[string]sarama.MockResponse{
+		"ApiVersionsRequest": sarama.NewMockApiVersionsResponse(t),
+	})
+
+	// accepts connections but never answers
+	silent, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer silent.Close()
+	go func() {
+		for {
+			conn, err := silent.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	refused := "127.0.0.1:1"
+
+	c := &LazyClient{Config: &Config{
+		BootstrapServers: &[]string{refused, mb.Addr()},
+		Timeout:          1,
+	}}
+	assertNil(t, c.Ping())
+
+	c = &LazyClient{Config: &Config{
+		BootstrapServers: &[]string{refused, silent.Addr().String()},
+		Timeout:          1,
+	}}
+	err = c.Ping()
+	if !errors.Is(err, sarama.ErrOutOfBrokers) {
+		t.Fatalf("expected %v, got %v", sarama.ErrOutOfBrokers, err)
+	}
+	for _, s := range []string{refused + " (", "connection refused", silent.Addr().String() + " (timeout)"} {
+		if !strings.Contains(err.Error(), s) {
+			t.Errorf("expected %q in %q", s, err)
+		}
+	}
+}
+
+func Test_LazyClientPingIPv6(t *testing.T) {
+	l, err := net.Listen("tcp", "[::1]:0")
+	if err != nil {
+		t.Skipf("IPv6 is not available: %s", err)
+	}
+	mb := sarama.NewMockBrokerListener(t, 1, l)
+	defer mb.Close()
+	mb.SetHandlerByMap(map[string]sarama.MockResponse{
+		"ApiVersionsRequest": sarama.NewMockApiVersionsResponse(t),
+	})
+
+	// the listener's address is bracketed, as are bootstrap servers
+	if !strings.HasPrefix(mb.Addr(), "[::1]:") {
+		t.Fatalf("expected a bracketed IPv6 address, got %s", mb.Addr())
+	}
+
+	c := &LazyClient{Config: &Config{
+		BootstrapServers: &[]string{"127.0.0.1:1", "[::1]:1", mb.Addr()},
+		Timeout:          1,
+	}}
+	assertNil(t, c.Ping())
+
+	c = &LazyClient{Config: &Config{
+		BootstrapServers: &[]string{"127.0.0.1:1", "[::1]:1"},
+		Timeout:          1,
+	}}
+	err = c.Ping()
+	if err == nil || !strings.Contains(err.Error(), "[::1]:1 (") {
+		t.Fatalf("expected [::1]:1 to be reported unreachable, got %v", err)
+	}
+}
+
+func Test_LazyClientFailover(t *testing.T) {
+	mb := sarama.NewMockBroker(t, 1)
+	defer mb.Close()
+	mb.SetHandlerByMap(map[string]sarama.MockResponse{
+		"ApiVersionsRequest": sarama.NewMockApiVersionsResponse(t),
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(mb.Addr(), mb.BrokerID()).
+			SetController(mb.BrokerID()),
+	})
+
+	// the primary servers are used while one of them can be reached
+	c := &LazyClient{Config: &Config{
+		BootstrapServers:         &[]string{mb.Addr()},
+		FailoverBootstrapServers: [][]string{{"127.0.0.1:2"}},
+		Timeout:                  1,
+	}}
+	servers, err := c.reachableBootstrapServers()
+	assertNil(t, err)
+	assertEquals(t, true, reflect.DeepEqual([]string{mb.Addr()}, servers))
+
+	// and the first reachable group of failover servers once none can be
+	c = &LazyClient{Config: &Config{
+		BootstrapServers:         &[]string{"127.0.0.1:1"},
+		FailoverBootstrapServers: [][]string{{"127.0.0.1:2"}, {"127.0.0.1:3", mb.Addr()}},
+		Timeout:                  1,
+	}}
+	assertNil(t, c.init())
+	defer c.inner.client.Close()
+	assertEquals(t, true, reflect.DeepEqual([]string{"127.0.0.1:3", mb.Addr()}, *c.Config.BootstrapServers))
+
+	c = &LazyClient{Config: &Config{
+		BootstrapServers:         &[]string{"127.0.0.1:1"},
+		FailoverBootstrapServers: [][]string{{"127.0.0.1:2"}},
+		Timeout:                  1,
+	}}
+	_, err = c.reachableBootstrapServers()
+	if !errors.Is(err, sarama.ErrOutOfBrokers) {
+		t.Fatalf("expected %v, got %v", sarama.ErrOutOfBrokers, err)
+	}
+	for _, s := range []string{"127.0.0.1:1 (", "failover_bootstrap_servers[0]: ", "127.0.0.1:2 ("} {
+		if !strings.Contains(err.Error(), s) {
+			t.Errorf("expected %q in %q", s, err)
+		}
+	}
+}
+
+func Test_LazyClientMaxConcurrency(t *testing.T) {
+	for _, tc := range []struct {
+		maxConcurrency int
+		expected       int
+	}{
+		{maxConcurrency: 2, expected: 2},
+		{maxConcurrency: 0, expected: 8},
+	} {
+		c := &LazyClient{Config: &Config{MaxConcurrency: tc.maxConcurrency}}
+		var mu sync.Mutex
+		running, peak := 0, 0
+		started := make(chan void, 8)
+		done := make(chan void)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				assertNil(t, c.retry(context.Background(), "Test", func() error {
+					mu.Lock()
+					running++
+					if running > peak {
+						peak = running
+					}
+					mu.Unlock()
+
+					started <- member
+					<-done
+
+					mu.Lock()
+					running--
+					mu.Unlock()
+					return nil
+				}))
+			}()
+		}
+		for i := 0; i < tc.expected; i++ {
+			<-started
+		}
+		// give any operation that should have waited the chance to start
+		time.Sleep(50 * time.Millisecond)
+		close(done)
+		wg.Wait()
+
+		assertEquals(t, tc.expected, peak)
+	}
+}
+
+func Test_LazyClientRetryRefreshesController(t *testing.T) {
+	old := sarama.NewMockBroker(t, 1)
+	defer old.Close()
+	elected := sarama.NewMockBroker(t, 2)
+	defer elected.Close()
+
+	handlers := func(controller int32, deleteTopics sarama.MockResponse) map[string]sarama.MockResponse {
+		return map[string]sarama.MockResponse{
+			"ApiVersionsRequest": sarama.NewMockApiVersionsResponse(t),
+			"MetadataRequest": sarama.NewMockMetadataResponse(t).
+				SetBroker(old.Addr(), old.BrokerID()).
+				SetBroker(elected.Addr(), elected.BrokerID()).
+				SetController(controller),
+			"DeleteTopicsRequest": deleteTopics,
+		}
+	}
+	old.SetHandlerByMap(handlers(old.BrokerID(), sarama.NewMockDeleteTopicsResponse(t).SetError(sarama.ErrNotController)))
+	elected.SetHandlerByMap(handlers(old.BrokerID(), sarama.NewMockDeleteTopicsResponse(t)))
+
+	c := &LazyClient{Config: &Config{
+		BootstrapServers: &[]string{old.Addr()},
+		KafkaVersion:     "2.8.0",
+		Timeout:          5,
+		RetryMaxRetries:  3,
+	}}
+	assertNil(t, c.init())
+
+	// an election the client's cached metadata does not know of yet
+	old.SetHandlerByMap(handlers(elected.BrokerID(), sarama.NewMockDeleteTopicsResponse(t).SetError(sarama.ErrNotController)))
+	elected.SetHandlerByMap(handlers(elected.BrokerID(), sarama.NewMockDeleteTopicsResponse(t)))
+
+	assertNil(t, c.DeleteTopic(context.Background(), "syslog"))
+
+	controller, release, err := c.inner.controller()
+	assertNil(t, err)
+	defer release()
+	assertEquals(t, elected.BrokerID(), controller.ID())
+
+	id, err := c.RefreshController()
+	assertNil(t, err)
+	assertEquals(t, elected.BrokerID(), id)
+}
+
+func Test_LazyClientCreateTopicTimedOut(t *testing.T) {
+	mb := sarama.NewMockBroker(t, 1)
+	defer mb.Close()
+
+	createTopics := func(kerr sarama.KError) sarama.MockResponse {
+		return sarama.NewMockWrapper(&sarama.CreateTopicsResponse{
+			Version:     3,
+			TopicErrors: map[string]*sarama.TopicError{"syslog": {Err: kerr}},
+		})
+	}
+	handlers := func(createTopics sarama.MockResponse) map[string]sarama.MockResponse {
+		return map[string]sarama.MockResponse{
+			"ApiVersionsRequest": sarama.NewMockApiVersionsResponse(t),
+			"MetadataRequest": sarama.NewMockMetadataResponse(t).
+				SetBroker(mb.Addr(), mb.BrokerID()).
+				SetController(mb.BrokerID()),
+			"CreateTopicsRequest": createTopics,
+		}
+	}
+	mb.SetHandlerByMap(handlers(sarama.NewMockSequence(createTopics(sarama.ErrRequestTimedOut), createTopics(sarama.ErrTopicAlreadyExists))))
+
+	c := &LazyClient{Config: &Config{
+		BootstrapServers: &[]string{mb.Addr()},
+		KafkaVersion:     "2.8.0",
+		Timeout:          5,
+		RetryMaxRetries:  3,
+	}}
+	topic := Topic{Name: "syslog", Partitions: 1, ReplicationFactor: 1}
+
+	// the timed out request created the topic its retry finds
+	assertNil(t, c.CreateTopic(context.Background(), topic))
+
+	// without a timeout first, the topic is someone else's
+	mb.SetHandlerByMap(handlers(createTopics(sarama.ErrTopicAlreadyExists)))
+	if err := c.CreateTopic(context.Background(), topic); !IsTopicExistsError(err) {
+		t.Errorf("expected the topic to already exist, got %v", err)
+	}
+}
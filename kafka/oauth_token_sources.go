@@ -0,0 +1,365 @@
+package kafka
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/compute/metadata"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+const (
+	oauthTokenSourceClientCredentials = "clientcredentials"
+	oauthTokenSourceFile              = "file"
+	oauthTokenSourceExec              = "exec"
+	oauthTokenSourceAzureMSI          = "azure-msi"
+	oauthTokenSourceGCP               = "gcp"
+
+	oauthTokenCommandTimeout = 30 * time.Second
+	azureMSIRequestTimeout   = 30 * time.Second
+
+	// azureIMDSTokenEndpoint is the token endpoint of the Azure instance
+	// metadata service of VMs and AKS nodes
+	azureIMDSTokenEndpoint = "http://169.254.169.254/metadata/identity/oauth2/token"
+)
+
+var oauthTokenSources = []string{oauthTokenSourceClientCredentials, oauthTokenSourceFile, oauthTokenSourceExec, oauthTokenSourceAzureMSI, oauthTokenSourceGCP}
+
+// gcpDefaultScope is requested by the gcp token source unless
+// sasl_oauth_scopes says otherwise
+const gcpDefaultScope = "https://www.googleapis.com/auth/cloud-platform"
+
+// fileTokenSource reads the token from a file, e.g. one kept up to date by a
+// sidecar. A JWT's expiry is taken from its exp claim, so the file is only
+// read again shortly before it expires; other tokens are read every time.
+type fileTokenSource struct {
+	path string
+}
+
+func (f *fileTokenSource) Token(ctx context.Context) (*oauth2.Token, error) {
+	b, err := os.ReadFile(f.path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading oauth token file: %w", err)
+	}
+
+	token := strings.TrimSpace(string(b))
+	if token == "" {
+		return nil, fmt.Errorf("oauth token file %s is empty", f.path)
+	}
+
+	expiry, _ := jwtExpiry(token)
+	return &oauth2.Token{AccessToken: token, Expiry: expiry}, nil
+}
+
+// execTokenSource runs a command printing a JSON object with the
+// access_token, and its expiry as an RFC 3339 time or expires_in seconds
+type execTokenSource struct {
+	command []string
+}
+
+type execTokenOutput struct {
+	AccessToken string    `json:"access_token"`
+	Expiry      time.Time `json:"expiry"`
+	ExpiresIn   int64     `json:"expires_in"`
+}
+
+func (e *execTokenSource) Token(ctx context.Context) (*oauth2.Token, error) {
+	ctx, cancel := context.WithTimeout(ctx, oauthTokenCommandTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, e.command[0], e.command[1:]...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("error running oauth token command %s: %w: %s", e.command[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	var out execTokenOutput
+	if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+		return nil, fmt.Errorf("oauth token command %s did not print a JSON object: %w", e.command[0], err)
+	}
+	if out.AccessToken == "" {
+		return nil, fmt.Errorf("oauth token command %s printed no access_token", e.command[0])
+	}
+
+	expiry := out.Expiry
+	if expiry.IsZero() && out.ExpiresIn > 0 {
+		expiry = time.Now().Add(time.Duration(out.ExpiresIn) * time.Second)
+	}
+	return &oauth2.Token{AccessToken: out.AccessToken, Expiry: expiry}, nil
+}
+
+// azureMSITokenSource gets tokens for the resource, e.g. an Event Hubs
+// namespace, from the managed identity of the Azure VM, AKS node or App
+// Service the provider runs on. clientID selects a user-assigned identity,
+// the system-assigned one is used when it is empty.
+type azureMSITokenSource struct {
+	resource string
+	clientID string
+	// endpoint is the instance metadata service, unless identityHeader is
+	// set, in which case it is the App Service identity endpoint
+	endpoint       string
+	identityHeader string
+}
+
+func newAzureMSITokenSource(resource, clientID string) *azureMSITokenSource {
+	// App Service, Functions and Container Apps have their own endpoint
+	if endpoint, header := os.Getenv("IDENTITY_ENDPOINT"), os.Getenv("IDENTITY_HEADER"); endpoint != "" && header != "" {
+		return &azureMSITokenSource{resource: resource, clientID: clientID, endpoint: endpoint, identityHeader: header}
+	}
+	return &azureMSITokenSource{resource: resource, clientID: clientID, endpoint: azureIMDSTokenEndpoint}
+}
+
+type azureMSITokenOutput struct {
+	AccessToken string `json:"access_token"`
+	// both are seconds, sent as strings by the instance metadata service
+	ExpiresOn json.Number `json:"expires_on"`
+	ExpiresIn json.Number `json:"expires_in"`
+}
+
+func (a *azureMSITokenSource) Token(ctx context.Context) (*oauth2.Token, error) {
+	ctx, cancel := context.WithTimeout(ctx, azureMSIRequestTimeout)
+	defer cancel()
+
+	query := url.Values{"resource": {a.resource}}
+	if a.identityHeader != "" {
+		query.Set("api-version", "2019-08-01")
+	} else {
+		query.Set("api-version", "2018-02-01")
+	}
+	if a.clientID != "" {
+		query.Set("client_id", a.clientID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.endpoint+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if a.identityHeader != "" {
+		req.Header.Set("X-IDENTITY-HEADER", a.identityHeader)
+	} else {
+		req.Header.Set("Metadata", "true")
+	}
+
+	// the metadata service is link-local, and must not be reached through
+	// the proxy of the environment
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	res, err := (&http.Client{Transport: transport}).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error requesting an Azure managed identity token: %w", err)
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading the Azure managed identity token: %w", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error requesting an Azure managed identity token for %s: %s: %s", a.resource, res.Status, strings.TrimSpace(string(body)))
+	}
+
+	var out azureMSITokenOutput
+	if err := json.Unmarshal(body, &out); err != nil {
+		return nil, fmt.Errorf("invalid Azure managed identity token response: %w", err)
+	}
+	if out.AccessToken == "" {
+		return nil, fmt.Errorf("the Azure managed identity token response has no access_token")
+	}
+
+	var expiry time.Time
+	if expiresOn, err := strconv.ParseInt(out.ExpiresOn.String(), 10, 64); err == nil {
+		expiry = time.Unix(expiresOn, 0)
+	} else if expiresIn, err := strconv.ParseInt(out.ExpiresIn.String(), 10, 64); err == nil {
+		expiry = time.Now().Add(time.Duration(expiresIn) * time.Second)
+	}
+	return &oauth2.Token{AccessToken: out.AccessToken, Expiry: expiry}, nil
+}
+
+// azureMSIResource returns the resource to get managed identity tokens for:
+// the first of sasl_oauth_scopes without its /.default suffix, or else the
+// namespace of the first bootstrap server, e.g.
+// https://example.servicebus.windows.net for Event Hubs
+func (c *Config) azureMSIResource() (string, error) {
+	if len(c.SASLOAuthScopes) > 0 {
+		return strings.TrimSuffix(c.SASLOAuthScopes[0], "/.default"), nil
+	}
+	if c.BootstrapServers == nil || len(*c.BootstrapServers) == 0 {
+		return "", fmt.Errorf("sasl_oauth_scopes must be configured to use the %q oauth token source without bootstrap_servers", oauthTokenSourceAzureMSI)
+	}
+	host := (*c.BootstrapServers)[0]
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return "https://" + host, nil
+}
+
+// gcpTokenSource gets access tokens from Google's Application Default
+// Credentials, e.g. GOOGLE_APPLICATION_CREDENTIALS, the gcloud user or the
+// service account of the GCE instance or GKE workload. The credentials and
+// the email of their principal are only looked up on the first token.
+//
+// Google Managed Service for Apache Kafka does not accept the access token
+// itself, but a JWT-like token wrapping it, as built by Google's own Kafka
+// login handlers.
+type gcpTokenSource struct {
+	scopes []string
+	creds  *google.Credentials
+	email  string
+	// tokenInfoEndpoint tells the email of the principal of credentials
+	// that do not include it, e.g. those of a gcloud user
+	tokenInfoEndpoint string
+}
+
+func newGCPTokenSource(scopes []string) *gcpTokenSource {
+	return &gcpTokenSource{scopes: scopes, tokenInfoEndpoint: gcpTokenInfoEndpoint}
+}
+
+// gcpTokenInfoEndpoint describes a Google access token
+const gcpTokenInfoEndpoint = "https://oauth2.googleapis.com/tokeninfo"
+
+// gcpTokenHeader is the header of the wrapped tokens
+const gcpTokenHeader = `{"typ":"JWT","alg":"GOOG_OAUTH2_TOKEN"}`
+
+type gcpTokenClaims struct {
+	Exp   int64  `json:"exp"`
+	Iat   int64  `json:"iat"`
+	Iss   string `json:"iss"`
+	Sub   string `json:"sub"`
+	Scope string `json:"scope"`
+}
+
+type gcpCredentialsFile struct {
+	ClientEmail                    string `json:"client_email"`
+	ServiceAccountImpersonationURL string `json:"service_account_impersonation_url"`
+}
+
+func (g *gcpTokenSource) Token(ctx context.Context) (*oauth2.Token, error) {
+	if g.creds == nil {
+		creds, err := google.FindDefaultCredentials(ctx, g.scopes...)
+		if err != nil {
+			return nil, fmt.Errorf("error finding Google application default credentials: %w", err)
+		}
+		g.creds = creds
+	}
+
+	token, err := g.creds.TokenSource.Token()
+	if err != nil {
+		return nil, fmt.Errorf("error getting a Google access token: %w", err)
+	}
+
+	if g.email == "" {
+		g.email, err = g.principalEmail(ctx, token.AccessToken)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &oauth2.Token{AccessToken: wrapGCPToken(token, g.email, time.Now()), Expiry: token.Expiry}, nil
+}
+
+// principalEmail returns the email of the service account or user the
+// credentials are of
+func (g *gcpTokenSource) principalEmail(ctx context.Context, accessToken string) (string, error) {
+	if len(g.creds.JSON) == 0 {
+		// the credentials of the GCE instance or GKE workload
+		email, err := metadata.EmailWithContext(ctx, "default")
+		if err != nil {
+			return "", fmt.Errorf("error getting the service account email from the metadata server: %w", err)
+		}
+		return email, nil
+	}
+
+	var file gcpCredentialsFile
+	if err := json.Unmarshal(g.creds.JSON, &file); err == nil {
+		if file.ClientEmail != "" {
+			return file.ClientEmail, nil
+		}
+		// e.g. .../serviceAccounts/name@project.iam.gserviceaccount.com:generateAccessToken
+		if i := strings.LastIndex(file.ServiceAccountImpersonationURL, "/"); i >= 0 {
+			if email, _, ok := strings.Cut(file.ServiceAccountImpersonationURL[i+1:], ":"); ok {
+				return email, nil
+			}
+		}
+	}
+
+	// user credentials only tell their email through the token info
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.tokenInfoEndpoint+"?"+url.Values{"access_token": {accessToken}}.Encode(), nil)
+	if err != nil {
+		return "", err
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("error looking up the email of the Google credentials: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("error looking up the email of the Google credentials: %s", res.Status)
+	}
+	var info struct {
+		Email string `json:"email"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&info); err != nil {
+		return "", fmt.Errorf("invalid Google token info response: %w", err)
+	}
+	if info.Email == "" {
+		return "", fmt.Errorf("the Google credentials have no email, they need the userinfo.email scope")
+	}
+	return info.Email, nil
+}
+
+// wrapGCPToken wraps the access token for Google Managed Service for Apache
+// Kafka: the header, the claims and the access token, each base64url
+// encoded and joined by dots
+func wrapGCPToken(token *oauth2.Token, email string, now time.Time) string {
+	claims, _ := json.Marshal(gcpTokenClaims{
+		Exp:   token.Expiry.Unix(),
+		Iat:   now.Unix(),
+		Iss:   "Google",
+		Sub:   email,
+		Scope: "kafka",
+	})
+	return strings.Join([]string{
+		base64.RawURLEncoding.EncodeToString([]byte(gcpTokenHeader)),
+		base64.RawURLEncoding.EncodeToString(claims),
+		base64.RawURLEncoding.EncodeToString([]byte(token.AccessToken)),
+	}, ".")
+}
+
+// jwtExpiry returns the time of the exp claim of a JWT, without verifying
+// the token
+func jwtExpiry(token string) (time.Time, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	var claims struct {
+		Exp float64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp <= 0 {
+		return time.Time{}, false
+	}
+
+	return time.Unix(int64(claims.Exp), 0), true
+}
@@ -0,0 +1,332 @@
+package kafka
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+func testJWT(exp int64) string {
+	enc := base64.RawURLEncoding
+	return strings.Join([]string{
+		enc.EncodeToString([]byte(`{"alg":"none"}`)),
+		enc.EncodeToString([]byte(fmt.Sprintf(`{"sub":"terraform","exp":%d}`, exp))),
+		"signature",
+	}, ".")
+}
+
+func Test_fileTokenSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	exp := time.Now().Add(time.Hour).Unix()
+	if err := os.WriteFile(path, []byte(testJWT(exp)+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tokenProvider := newOauthbearerTokenProvider(&fileTokenSource{path: path}, nil, defaultOAuthRefreshSkew, 0)
+	token, err := tokenProvider.Token()
+	assertNil(t, err)
+	assertEquals(t, testJWT(exp), token.Token)
+	assertEquals(t, exp, tokenProvider.tokenExpiration.Unix())
+
+	// the file is only read again when the token expires
+	if err := os.WriteFile(path, []byte("opaque-token"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	token, err = tokenProvider.Token()
+	assertNil(t, err)
+	assertEquals(t, testJWT(exp), token.Token)
+
+	tokenProvider.tokenExpiration = time.Now()
+	token, err = tokenProvider.Token()
+	assertNil(t, err)
+	assertEquals(t, "opaque-token", token.Token)
+
+	if err := os.WriteFile(path, []byte("\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := (&fileTokenSource{path: path}).Token(context.Background()); err == nil {
+		t.Error("expected an error for an empty token file")
+	}
+}
+
+func Test_execTokenSource(t *testing.T) {
+	expiry := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	for _, tc := range []struct {
+		name    string
+		command []string
+		token   string
+		expiry  time.Time
+		err     string
+	}{
+		{
+			name:    "expiry",
+			command: []string{"sh", "-c", fmt.Sprintf(`echo '{"access_token":"a","expiry":"%s"}'`, expiry.Format(time.RFC3339))},
+			token:   "a",
+			expiry:  expiry,
+		},
+		{
+			name:    "expires_in",
+			command: []string{"sh", "-c", `echo '{"access_token":"b","expires_in":3600}'`},
+			token:   "b",
+			expiry:  expiry,
+		},
+		{
+			name:    "failing command",
+			command: []string{"sh", "-c", "echo denied >&2; exit 1"},
+			err:     "denied",
+		},
+		{
+			name:    "not json",
+			command: []string{"sh", "-c", "echo token"},
+			err:     "did not print a JSON object",
+		},
+		{
+			name:    "no access_token",
+			command: []string{"sh", "-c", `echo '{"expires_in":3600}'`},
+			err:     "printed no access_token",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			token, err := (&execTokenSource{command: tc.command}).Token(context.Background())
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("expected an error containing %q, got %v", tc.err, err)
+				}
+				return
+			}
+
+			assertNil(t, err)
+			assertEquals(t, tc.token, token.AccessToken)
+			if d := token.Expiry.Sub(tc.expiry); d < -time.Minute || d > time.Minute {
+				t.Errorf("expected expiry %s, got %s", tc.expiry, token.Expiry)
+			}
+		})
+	}
+}
+
+func Test_jwtExpiry(t *testing.T) {
+	exp, ok := jwtExpiry(testJWT(1700000000))
+	assertEquals(t, true, ok)
+	assertEquals(t, int64(1700000000), exp.Unix())
+
+	for _, token := range []string{"opaque-token", "a.b.c", testJWT(0)} {
+		if _, ok := jwtExpiry(token); ok {
+			t.Errorf("expected no expiry for %q", token)
+		}
+	}
+}
+
+func Test_azureMSITokenSource(t *testing.T) {
+	expiresOn := time.Now().Add(time.Hour).Unix()
+	var query url.Values
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query, header = r.URL.Query(), r.Header
+		if query.Get("resource") == "https://denied.servicebus.windows.net" {
+			http.Error(w, `{"error":"invalid_resource"}`, http.StatusBadRequest)
+			return
+		}
+		// the instance metadata service sends the numbers as strings
+		fmt.Fprintf(w, `{"access_token":"msi-token","expires_on":"%d","expires_in":"3599"}`, expiresOn)
+	}))
+	defer server.Close()
+
+	source := &azureMSITokenSource{resource: "https://example.servicebus.windows.net", clientID: "identity", endpoint: server.URL}
+	tokenProvider := newOauthbearerTokenProvider(source, nil, defaultOAuthRefreshSkew, 0)
+	token, err := tokenProvider.Token()
+	assertNil(t, err)
+	assertEquals(t, "msi-token", token.Token)
+	assertEquals(t, expiresOn, tokenProvider.tokenExpiration.Unix())
+	assertEquals(t, "true", header.Get("Metadata"))
+	assertEquals(t, "https://example.servicebus.windows.net", query.Get("resource"))
+	assertEquals(t, "identity", query.Get("client_id"))
+	assertEquals(t, "2018-02-01", query.Get("api-version"))
+
+	// App Service authenticates requests with its identity header
+	source = &azureMSITokenSource{resource: "https://example.servicebus.windows.net", endpoint: server.URL, identityHeader: "secret"}
+	_, err = source.Token(context.Background())
+	assertNil(t, err)
+	assertEquals(t, "secret", header.Get("X-IDENTITY-HEADER"))
+	assertEquals(t, "", query.Get("client_id"))
+	assertEquals(t, "2019-08-01", query.Get("api-version"))
+
+	source = &azureMSITokenSource{resource: "https://denied.servicebus.windows.net", endpoint: server.URL}
+	if _, err := source.Token(context.Background()); err == nil || !strings.Contains(err.Error(), "invalid_resource") {
+		t.Errorf("expected the error of the endpoint, got %v", err)
+	}
+}
+
+func TestConfig_azureMSIResource(t *testing.T) {
+	config := Config{BootstrapServers: &[]string{"example.servicebus.windows.net:9093"}}
+	resource, err := config.azureMSIResource()
+	assertNil(t, err)
+	assertEquals(t, "https://example.servicebus.windows.net", resource)
+
+	config.SASLOAuthScopes = []string{"https://eventhubs.azure.net/.default"}
+	resource, err = config.azureMSIResource()
+	assertNil(t, err)
+	assertEquals(t, "https://eventhubs.azure.net", resource)
+
+	if _, err := (&Config{}).azureMSIResource(); err == nil {
+		t.Error("expected an error without bootstrap servers or scopes")
+	}
+
+	t.Setenv("IDENTITY_ENDPOINT", "")
+	config = Config{
+		BootstrapServers:       &[]string{"example.servicebus.windows.net:9093"},
+		SASLMechanism:          "oauthbearer",
+		SASLOAuthTokenSource:   oauthTokenSourceAzureMSI,
+		SASLUsername:           "user",
+		SASLOAuthAzureClientID: "identity",
+	}
+	sConfig, err := config.newKafkaConfig()
+	assertNil(t, err)
+	source, ok := sConfig.Net.SASL.TokenProvider.(*oauthbearerTokenProvider).oauth2Config.(*azureMSITokenSource)
+	if !ok {
+		t.Fatalf("expected an azure-msi token source, got %T", sConfig.Net.SASL.TokenProvider.(*oauthbearerTokenProvider).oauth2Config)
+	}
+	assertEquals(t, "identity", source.clientID)
+	assertEquals(t, azureIMDSTokenEndpoint, source.endpoint)
+
+	t.Setenv("AZURE_CLIENT_ID", "env-identity")
+	config.SASLOAuthAzureClientID = ""
+	sConfig, err = config.newKafkaConfig()
+	assertNil(t, err)
+	source = sConfig.Net.SASL.TokenProvider.(*oauthbearerTokenProvider).oauth2Config.(*azureMSITokenSource)
+	assertEquals(t, "env-identity", source.clientID)
+}
+
+func Test_gcpTokenSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assertNil(t, r.ParseForm())
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/tokeninfo" {
+			assertEquals(t, "gcp-token", r.Form.Get("access_token"))
+			fmt.Fprint(w, `{"email":"user@example.com"}`)
+			return
+		}
+		assertEquals(t, "refresh-token", r.Form.Get("refresh_token"))
+		fmt.Fprint(w, `{"access_token":"gcp-token","token_type":"Bearer","expires_in":3600}`)
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "credentials.json")
+	credentials := fmt.Sprintf(`{"type":"authorized_user","client_id":"id","client_secret":"secret","refresh_token":"refresh-token","token_uri":%q}`, server.URL)
+	if err := os.WriteFile(path, []byte(credentials), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", path)
+
+	config := Config{SASLMechanism: "oauthbearer", SASLOAuthTokenSource: oauthTokenSourceGCP}
+	sConfig, err := config.newKafkaConfig()
+	assertNil(t, err)
+	tokenProvider := sConfig.Net.SASL.TokenProvider.(*oauthbearerTokenProvider)
+	source, ok := tokenProvider.oauth2Config.(*gcpTokenSource)
+	if !ok {
+		t.Fatalf("expected a gcp token source, got %T", tokenProvider.oauth2Config)
+	}
+	assertEquals(t, gcpDefaultScope, source.scopes[0])
+	assertEquals(t, gcpTokenInfoEndpoint, source.tokenInfoEndpoint)
+	source.tokenInfoEndpoint = server.URL + "/tokeninfo"
+
+	token, err := tokenProvider.Token()
+	assertNil(t, err)
+	parts := strings.Split(token.Token, ".")
+	assertEquals(t, 3, len(parts))
+	accessToken, err := base64.RawURLEncoding.DecodeString(parts[2])
+	assertNil(t, err)
+	assertEquals(t, "gcp-token", string(accessToken))
+	assertEquals(t, "user@example.com", source.email)
+	if time.Until(tokenProvider.tokenExpiration) < 50*time.Minute {
+		t.Errorf("expected the token to expire in an hour, got %v", tokenProvider.tokenExpiration)
+	}
+
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", filepath.Join(t.TempDir(), "missing.json"))
+	if _, err := newGCPTokenSource([]string{gcpDefaultScope}).Token(context.Background()); err == nil {
+		t.Error("expected an error without credentials")
+	}
+}
+
+func Test_wrapGCPToken(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	token := &oauth2.Token{AccessToken: "ya29.access-token", Expiry: now.Add(time.Hour)}
+
+	parts := strings.Split(wrapGCPToken(token, "kafka@project.iam.gserviceaccount.com", now), ".")
+	assertEquals(t, 3, len(parts))
+	for _, part := range parts {
+		if strings.ContainsAny(part, "+/=") {
+			t.Errorf("expected unpadded base64url, got %q", part)
+		}
+	}
+
+	header, err := base64.RawURLEncoding.DecodeString(parts[0])
+	assertNil(t, err)
+	assertEquals(t, `{"typ":"JWT","alg":"GOOG_OAUTH2_TOKEN"}`, string(header))
+
+	claims, err := base64.RawURLEncoding.DecodeString(parts[1])
+	assertNil(t, err)
+	assertEquals(t, `{"exp":1700003600,"iat":1700000000,"iss":"Google","sub":"kafka@project.iam.gserviceaccount.com","scope":"kafka"}`, string(claims))
+
+	accessToken, err := base64.RawURLEncoding.DecodeString(parts[2])
+	assertNil(t, err)
+	assertEquals(t, "ya29.access-token", string(accessToken))
+}
+
+func Test_gcpTokenSource_principalEmail(t *testing.T) {
+	for _, tc := range []struct {
+		json  string
+		email string
+	}{
+		{`{"type":"service_account","client_email":"kafka@project.iam.gserviceaccount.com"}`, "kafka@project.iam.gserviceaccount.com"},
+		{`{"type":"impersonated_service_account","service_account_impersonation_url":"https://iamcredentials.googleapis.com/v1/projects/-/serviceAccounts/admin@project.iam.gserviceaccount.com:generateAccessToken"}`, "admin@project.iam.gserviceaccount.com"},
+	} {
+		source := &gcpTokenSource{creds: &google.Credentials{JSON: []byte(tc.json)}}
+		email, err := source.principalEmail(context.Background(), "token")
+		assertNil(t, err)
+		assertEquals(t, tc.email, email)
+	}
+}
+
+func TestConfig_NewKafkaConfig_OAuthTokenSource(t *testing.T) {
+	config := Config{
+		SASLMechanism:        "oauthbearer",
+		SASLOAuthTokenSource: oauthTokenSourceFile,
+		SASLOAuthTokenFile:   "/var/run/secrets/token",
+	}
+	sConfig, err := config.newKafkaConfig()
+	assertNil(t, err)
+	tokenProvider := sConfig.Net.SASL.TokenProvider.(*oauthbearerTokenProvider)
+	if _, ok := tokenProvider.oauth2Config.(*fileTokenSource); !ok {
+		t.Errorf("expected a file token source, got %T", tokenProvider.oauth2Config)
+	}
+
+	config.SASLOAuthTokenSource = oauthTokenSourceExec
+	config.SASLOAuthTokenCommand = []string{"token-helper", "--audience", "kafka"}
+	sConfig, err = config.newKafkaConfig()
+	assertNil(t, err)
+	tokenProvider = sConfig.Net.SASL.TokenProvider.(*oauthbearerTokenProvider)
+	if _, ok := tokenProvider.oauth2Config.(*execTokenSource); !ok {
+		t.Errorf("expected an exec token source, got %T", tokenProvider.oauth2Config)
+	}
+
+	for _, c := range []Config{
+		{SASLMechanism: "oauthbearer", SASLOAuthTokenSource: oauthTokenSourceFile},
+		{SASLMechanism: "oauthbearer", SASLOAuthTokenSource: oauthTokenSourceExec},
+		{SASLMechanism: "oauthbearer", SASLOAuthTokenSource: "vault"},
+	} {
+		if _, err := c.newKafkaConfig(); err == nil {
+			t.Errorf("expected an error for token source %q", c.SASLOAuthTokenSource)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package kafka
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+
+	"github.com/youmark/pkcs8"
+)
+
+// errIncorrectPassphrase is returned when an encrypted private key cannot be
+// decrypted with the configured passphrase
+var errIncorrectPassphrase = errors.New("incorrect client_key_passphrase for the encrypted private key")
+
+// UnsupportedKeyFormatError is returned for encrypted private keys using a
+// scheme that cannot be decrypted
+type UnsupportedKeyFormatError struct {
+	msg string
+}
+
+func (e UnsupportedKeyFormatError) Error() string {
+	return "unsupported private key format: " + e.msg
+}
+
+// decryptPKCS8PrivateKey decrypts the DER of an "ENCRYPTED PRIVATE KEY" PEM
+// block, returning the DER of the PKCS#8 private key. Only PBES2 is
+// supported, which is what OpenSSL writes.
+func decryptPKCS8PrivateKey(der []byte, passphrase []byte) (plain []byte, err error) {
+	// the library decrypts without checking the length of the encrypted
+	// data, which panics for a malformed key
+	defer func() {
+		if r := recover(); r != nil {
+			plain, err = nil, UnsupportedKeyFormatError{msg: fmt.Sprintf("malformed encrypted PKCS#8 key: %v", r)}
+		}
+	}()
+
+	key, _, err := pkcs8.ParsePrivateKey(der, passphrase)
+	if err != nil {
+		// a wrong passphrase yields garbage, which fails to parse as a key
+		if err.Error() == "pkcs8: incorrect password" {
+			return nil, errIncorrectPassphrase
+		}
+		return nil, UnsupportedKeyFormatError{msg: err.Error()}
+	}
+
+	return x509.MarshalPKCS8PrivateKey(key)
+}
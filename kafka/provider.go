@@ -1,10 +1,17 @@
 package kafka
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"net"
+	"strconv"
+	"strings"
 
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 func Provider() *schema.Provider {
@@ -13,8 +20,32 @@ func Provider() *schema.Provider {
 			"bootstrap_servers": {
 				Type:        schema.TypeList,
 				Elem:        &schema.Schema{Type: schema.TypeString},
-				Required:    true,
-				Description: "A list of kafka brokers",
+				Optional:    true,
+				DefaultFunc: envListDefaultFunc("KAFKA_BOOTSTRAP_SERVERS"),
+				Description: "A list of kafka brokers. Either this or `bootstrap_servers_srv` is required.",
+			},
+			"bootstrap_servers_srv": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				DefaultFunc:   schema.EnvDefaultFunc("KAFKA_BOOTSTRAP_SERVERS_SRV", nil),
+				ConflictsWith: []string{"bootstrap_servers"},
+				Description:   "A DNS SRV record, e.g. _kafka._tcp.example.com, to look the bootstrap servers up from each time the provider is configured, instead of listing them in `bootstrap_servers`.",
+			},
+			"failover_bootstrap_servers": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "The bootstrap servers of other clusters, e.g. the passive cluster of an active/passive pair, tried in turn when none of the bootstrap servers can be reached within `dial_timeout`.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"bootstrap_servers": {
+							Type:        schema.TypeList,
+							Required:    true,
+							MinItems:    1,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "A list of kafka brokers of the cluster.",
+						},
+					},
+				},
 			},
 			"ca_cert_file": {
 				Type:        schema.TypeString,
@@ -43,6 +74,13 @@ func Provider() *schema.Provider {
 				DefaultFunc: schema.EnvDefaultFunc("KAFKA_CA_CERT", nil),
 				Description: "CA certificate file to validate the server's certificate.",
 			},
+			"ca_certs": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				DefaultFunc: envListDefaultFunc("KAFKA_CA_CERTS"),
+				Description: "Additional CA certificates, or paths to files containing them, to validate the server's certificate, e.g. during a CA rotation. Each may be a bundle of several certificates.",
+			},
 			"client_cert": {
 				Type:        schema.TypeString,
 				Optional:    true,
@@ -61,6 +99,20 @@ func Provider() *schema.Provider {
 				DefaultFunc: schema.EnvDefaultFunc("KAFKA_CLIENT_KEY_PASSPHRASE", nil),
 				Description: "The passphrase for the private key that the certificate was issued for.",
 			},
+			"client_key_passphrase_file": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				DefaultFunc:   schema.EnvDefaultFunc("KAFKA_CLIENT_KEY_PASSPHRASE_FILE", nil),
+				ConflictsWith: []string{"client_key_passphrase"},
+				Description:   "Path to a file containing the passphrase for the private key, e.g. a mounted secret, instead of setting `client_key_passphrase`.",
+			},
+			"client_cert_format": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				DefaultFunc:      schema.EnvDefaultFunc("KAFKA_CLIENT_CERT_FORMAT", clientCertFormatPEM),
+				ValidateDiagFunc: validateDiagFunc(validation.StringInSlice([]string{clientCertFormatPEM, clientCertFormatPKCS12}, false)),
+				Description:      "The format of client_cert, either pem or pkcs12. A pkcs12 bundle holds both the certificate and its private key, so client_key is not needed; client_key_passphrase is used to decrypt it.",
+			},
 			"sasl_aws_region": {
 				Type:        schema.TypeString,
 				Optional:    true,
@@ -71,7 +123,7 @@ func Provider() *schema.Provider {
 				Type:        schema.TypeString,
 				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc("KAFKA_VERSION", "2.7.0"),
-				Description: "The version of Kafka protocol to use in `$MAJOR.$MINOR.$PATCH` format. Some features may not be available on older versions. Default is 2.7.0.",
+				Description: "The version of Kafka protocol to use in `$MAJOR.$MINOR.$PATCH` format, or `auto` to detect it from the APIs the brokers support. Some features may not be available on older versions. Default is 2.7.0.",
 			},
 			"sasl_aws_container_authorization_token_file": {
 				Type:        schema.TypeString,
@@ -94,9 +146,39 @@ func Provider() *schema.Provider {
 			"sasl_aws_external_id": {
 				Type:        schema.TypeString,
 				Optional:    true,
-				Default:     "",
+				DefaultFunc: schema.EnvDefaultFunc("KAFKA_SASL_AWS_EXTERNAL_ID", ""),
 				Description: "External ID of the AWS IAM role to assume",
 			},
+			"sasl_aws_web_identity_token_file": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Path to a web identity token, e.g. of an EKS service account, used to assume `sasl_aws_role_arn`.",
+			},
+			"sasl_aws_role_chain": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "AWS IAM roles to assume in turn, each with the credentials of the one before it, e.g. an intermediate role then the role with access to MSK. The first is assumed with `sasl_aws_role_arn` if set, or else with the other AWS credentials of the provider.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"role_arn": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "Arn of the AWS IAM role to assume.",
+						},
+						"external_id": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Sensitive:   true,
+							Description: "External ID of the role.",
+						},
+						"session_name": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Session name to assume the role with. Defaults to `terraform-kafka-provider`.",
+						},
+					},
+				},
+			},
 			"sasl_aws_profile": {
 				Type:        schema.TypeString,
 				Optional:    true,
@@ -131,7 +213,7 @@ func Provider() *schema.Provider {
 				Type:        schema.TypeList,
 				Optional:    true,
 				Elem:        &schema.Schema{Type: schema.TypeString},
-				DefaultFunc: schema.EnvDefaultFunc("AWS_SHARED_CONFIG_FILES", nil),
+				DefaultFunc: envListDefaultFunc("AWS_SHARED_CONFIG_FILES"),
 				Description: "List of paths to AWS shared config files.",
 			},
 			"sasl_username": {
@@ -140,6 +222,25 @@ func Provider() *schema.Provider {
 				DefaultFunc: schema.EnvDefaultFunc("KAFKA_SASL_USERNAME", nil),
 				Description: "Username for SASL authentication.",
 			},
+			"sasl_authz_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("KAFKA_SASL_AUTHZ_ID", ""),
+				Description: "The identity to act as, the SASL authzid, when it differs from sasl_username, e.g. for a service authenticating as itself to act on behalf of another principal. Only used with the plain, scram-sha256 and scram-sha512 sasl mechanisms.",
+			},
+			"sasl_handshake": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("KAFKA_SASL_HANDSHAKE", true),
+				Description: "Send a SaslHandshake before authenticating. Only disable it for legacy brokers that expect the SASL token without one; it requires the plain sasl_mechanism.",
+			},
+			"sasl_version": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("KAFKA_SASL_VERSION", nil),
+				ValidateFunc: validation.IntInSlice([]int{0, 1}),
+				Description:  "The version of the SASL flow, 1 to authenticate with SaslAuthenticate requests, or 0 to send the SASL tokens on their own after the handshake, for brokers that reject SaslAuthenticate. Version 0 is not supported by the oauthbearer and aws-iam sasl mechanisms.",
+			},
 			"sasl_password": {
 				Type:        schema.TypeString,
 				Optional:    true,
@@ -156,9 +257,66 @@ func Provider() *schema.Provider {
 				Type:        schema.TypeList,
 				Optional:    true,
 				Elem:        &schema.Schema{Type: schema.TypeString},
-				DefaultFunc: schema.EnvDefaultFunc("KAFKA_SASL_OAUTH_SCOPES", nil),
+				DefaultFunc: envListDefaultFunc("KAFKA_SASL_OAUTH_SCOPES"),
 				Description: "OAuth scopes to request when using the oauthbearer mechanism",
 			},
+			"sasl_oauth_token_source": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("KAFKA_SASL_OAUTH_TOKEN_SOURCE", oauthTokenSourceClientCredentials),
+				ValidateFunc: validation.StringInSlice(oauthTokenSources, false),
+				Description:  "Where the oauthbearer mechanism gets its tokens from: clientcredentials from sasl_token_url, a file, an exec command, azure-msi, the Azure managed identity the provider runs with, or gcp, Google's application default credentials.",
+			},
+			"sasl_oauth_token_file": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("KAFKA_SASL_OAUTH_TOKEN_FILE", nil),
+				Description: "The file to read the token from with the file oauth token source. It is read again when the token expires.",
+			},
+			"sasl_oauth_azure_client_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("KAFKA_SASL_OAUTH_AZURE_CLIENT_ID", nil),
+				Description: "The client ID of the user-assigned managed identity to get tokens for with the azure-msi oauth token source. Defaults to the `AZURE_CLIENT_ID` environment variable, or the system-assigned identity.",
+			},
+			"sasl_oauth_token_command": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The command and its arguments to run with the exec oauth token source. It must print a JSON object with the access_token, and its expiry or expires_in.",
+			},
+			"sasl_oauth_extensions": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "SASL extensions to send with the token when using the oauthbearer or aws-iam mechanism, e.g. a cluster or pool ID for multi-tenant brokers.",
+			},
+			"sasl_token_auth": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("KAFKA_SASL_TOKEN_AUTH", "false"),
+				Description: "Authenticate with a delegation token using SCRAM. Set sasl_username to the token ID and sasl_password to the token HMAC. Requires the scram-sha256 or scram-sha512 sasl_mechanism.",
+			},
+			"sasl_oauth_client_cert_enabled": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("KAFKA_SASL_OAUTH_CLIENT_CERT_ENABLED", "false"),
+				Description: "Present `client_cert` and `client_key` to the token endpoint when using the oauthbearer mechanism.",
+			},
+			"sasl_oauth_refresh_skew": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("KAFKA_SASL_OAUTH_REFRESH_SKEW", 2),
+				ValidateFunc: validation.IntAtLeast(1),
+				Description:  "Number of seconds before an oauth token expires that it is refreshed, when using the oauthbearer mechanism.",
+			},
+			"sasl_oauth_refresh_jitter": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("KAFKA_SASL_OAUTH_REFRESH_JITTER", 0),
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Maximum number of seconds of random jitter added to `sasl_oauth_refresh_skew`, so that concurrent clients don't refresh their tokens at the same time.",
+			},
 			"sasl_mechanism": {
 				Type:        schema.TypeString,
 				Optional:    true,
@@ -177,45 +335,488 @@ func Provider() *schema.Provider {
 				DefaultFunc: schema.EnvDefaultFunc("KAFKA_ENABLE_TLS", "true"),
 				Description: "Enable communication with the Kafka Cluster over TLS.",
 			},
+			"tls_min_version": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				DefaultFunc:      schema.EnvDefaultFunc("KAFKA_TLS_MIN_VERSION", "1.2"),
+				ValidateDiagFunc: validateDiagFunc(validation.StringInSlice([]string{"1.0", "1.1", "1.2", "1.3"}, false)),
+				Description:      "The minimum TLS version to accept, one of 1.0, 1.1, 1.2 or 1.3. Defaults to 1.2.",
+			},
+			"tls_max_version": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				DefaultFunc:      schema.EnvDefaultFunc("KAFKA_TLS_MAX_VERSION", nil),
+				ValidateDiagFunc: validateDiagFunc(validation.StringInSlice([]string{"1.0", "1.1", "1.2", "1.3"}, false)),
+				Description:      "The maximum TLS version to accept, one of 1.0, 1.1, 1.2 or 1.3. Defaults to the highest version supported.",
+			},
+			"tls_cipher_suites": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				DefaultFunc: envListDefaultFunc("KAFKA_TLS_CIPHER_SUITES"),
+				Description: "The cipher suites to allow for TLS 1.2 connections, e.g. TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384. Defaults to Go's defaults. TLS 1.3 cipher suites cannot be configured, so this has no effect if tls_min_version is 1.3.",
+			},
+			"tls_exclusive_ca": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("KAFKA_TLS_EXCLUSIVE_CA", false),
+				Description: "Trust only the CAs of `ca_cert` and `ca_certs`, rather than them and the system's, for the broker connections, e.g. to reject certificates signed by public CAs. The oauth token endpoint still trusts the system's CAs.",
+			},
+			"tls_server_name": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("KAFKA_TLS_SERVER_NAME", nil),
+				Description: "The name to verify the brokers' certificates against and send in SNI, instead of the host being connected to, e.g. when connecting through a load balancer.",
+			},
+			"proxy_url": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("KAFKA_PROXY_URL", nil),
+				Description: "The URL of a SOCKS5 proxy to connect to the brokers through, e.g. socks5://proxy:1080. The proxy environment variables only apply to the oauth token endpoint.",
+			},
+			"proxy_username": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("KAFKA_PROXY_USERNAME", nil),
+				Description: "Username to authenticate to the SOCKS5 proxy_url with.",
+			},
+			"proxy_password": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("KAFKA_PROXY_PASSWORD", nil),
+				Description: "Password to authenticate to the SOCKS5 proxy_url with.",
+			},
+			"ssh_tunnel": {
+				Type:          schema.TypeList,
+				Optional:      true,
+				MaxItems:      1,
+				ConflictsWith: []string{"proxy_url"},
+				Description:   "Connect to the brokers through an SSH tunnel, e.g. to reach them through a bastion host without a separate port forward.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"host": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The SSH host to tunnel through, as host or host:port. The port defaults to 22.",
+						},
+						"user": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The user to log in to the SSH host as.",
+						},
+						"private_key": {
+							Type:          schema.TypeString,
+							Optional:      true,
+							Sensitive:     true,
+							ConflictsWith: []string{"ssh_tunnel.0.private_key_file"},
+							Description:   "The PEM encoded private key to authenticate with. Without it or `private_key_file`, the keys of the running SSH agent are used.",
+						},
+						"private_key_file": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Path to the private key to authenticate with.",
+						},
+						"private_key_passphrase": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Sensitive:   true,
+							Description: "The passphrase of the private key, if it is encrypted.",
+						},
+						"known_hosts_file": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Path to the known_hosts file to verify the SSH host's key against. Defaults to `~/.ssh/known_hosts`.",
+						},
+						"insecure_ignore_host_key": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "Skip verifying the SSH host's key. Only use this for development.",
+						},
+					},
+				},
+			},
+			"client_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("KAFKA_CLIENT_ID", defaultClientID),
+				Description: "The client ID the provider uses when talking to the brokers. Default is terraform-provider-kafka.",
+			},
 			"timeout": {
 				Type:        schema.TypeInt,
 				Optional:    true,
-				Default:     120,
+				DefaultFunc: schema.EnvDefaultFunc("KAFKA_TIMEOUT", 120),
 				Description: "Timeout in seconds",
 			},
+			"metadata_refresh_frequency": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("KAFKA_METADATA_REFRESH_FREQUENCY", nil),
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "How often in seconds to refresh cluster metadata in the background. Defaults to 600.",
+			},
+			"keep_alive": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("KAFKA_KEEP_ALIVE", nil),
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "The interval in seconds of TCP keep-alives on connections to the brokers, e.g. to keep firewalls from dropping idle connections. Defaults to the OS's keep-alive settings.",
+			},
+			"max_open_requests": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("KAFKA_MAX_OPEN_REQUESTS", nil),
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "The maximum number of requests sent on a connection to a broker before waiting for their responses. Defaults to 5.",
+			},
+			"metadata_full": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("KAFKA_METADATA_FULL", true),
+				Description: "Fetch the metadata of every topic of the cluster, rather than only the topics used so far. Disabling it speeds up large clusters.",
+			},
+			"allow_auto_topic_creation": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("KAFKA_ALLOW_AUTO_TOPIC_CREATION", false),
+				Description: "Let the provider's metadata requests create topics that don't exist yet, where the brokers have auto.create.topics.enable set.",
+			},
+			"resolve_canonical_bootstrap_servers": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("KAFKA_RESOLVE_CANONICAL_BOOTSTRAP_SERVERS", "false"),
+				Description: "Resolve each bootstrap server to its canonical names and the addresses behind them, e.g. for load balancers with changing IPs.",
+			},
+			"default_topic_config": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Description: "A map of topic config applied to every kafka_topic. A topic's own config takes precedence.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"max_concurrency": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("KAFKA_MAX_CONCURRENCY", 0),
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "The maximum number of admin operations, e.g. creating topics or ACLs and altering configs, to run at the same time, regardless of terraform's -parallelism. Set to 0 for no limit.",
+			},
+			"debug": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("KAFKA_DEBUG", false),
+				Description: "Write the Kafka client's own logs, e.g. of connections, SASL authentication and metadata requests, to the TRACE log. Configured secrets are masked.",
+			},
+			"validate_only": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("KAFKA_VALIDATE_ONLY", false),
+				Description: "Have the brokers validate topic creations and config changes at plan time with validate-only requests, so that e.g. invalid config values fail the plan rather than the apply.",
+			},
+			"retry": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: "Retry admin operations that fail with a transient Kafka error, e.g. NOT_CONTROLLER during a broker restart.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"max_retries": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      defaultRetryMaxRetries,
+							ValidateFunc: validation.IntAtLeast(0),
+							Description:  "The maximum number of retries. Set to 0 to disable retries.",
+						},
+						"max_elapsed_time": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      defaultRetryMaxElapsedTime,
+							ValidateFunc: validation.IntAtLeast(1),
+							Description:  "The maximum time in seconds to keep retrying an operation.",
+						},
+						"admin_max_retries": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      defaultAdminRetryMax,
+							ValidateFunc: validation.IntAtLeast(0),
+							Description:  "The maximum number of times the Kafka client retries a cluster admin request failing with a controller error, within each attempt.",
+						},
+						"admin_backoff": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      defaultAdminRetryBackoff,
+							ValidateFunc: validation.IntAtLeast(1),
+							Description:  "The time in milliseconds the Kafka client waits between retries of a cluster admin request.",
+						},
+					},
+				},
+			},
+			"dial_timeout": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("KAFKA_DIAL_TIMEOUT", nil),
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Timeout in seconds for establishing a connection to a broker. Defaults to 30.",
+			},
+			"read_timeout": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("KAFKA_READ_TIMEOUT", nil),
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Timeout in seconds for reading a response from a broker. Defaults to `timeout`.",
+			},
+			"write_timeout": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("KAFKA_WRITE_TIMEOUT", nil),
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Timeout in seconds for writing a request to a broker. Defaults to `timeout`.",
+			},
+			"metadata_timeout": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("KAFKA_METADATA_TIMEOUT", nil),
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Timeout in seconds for refreshing cluster metadata. Defaults to `timeout`.",
+			},
 		},
 
-		ConfigureFunc: providerConfigure,
+		ConfigureContextFunc: providerConfigureContext,
 		ResourcesMap: map[string]*schema.Resource{
-			"kafka_topic":                 kafkaTopicResource(),
-			"kafka_acl":                   kafkaACLResource(),
-			"kafka_quota":                 kafkaQuotaResource(),
-			"kafka_user_scram_credential": kafkaUserScramCredentialResource(),
+			"kafka_topic":                  kafkaTopicResource(),
+			"kafka_topic_config":           kafkaTopicConfigResource(),
+			"kafka_broker_config":          kafkaBrokerConfigResource(),
+			"kafka_acl":                    kafkaACLResource(),
+			"kafka_acl_batch":              kafkaACLBatchResource(),
+			"kafka_quota":                  kafkaQuotaResource(),
+			"kafka_user_scram_credential":  kafkaUserScramCredentialResource(),
+			"kafka_consumer_group":         kafkaConsumerGroupResource(),
+			"kafka_partition_reassignment": kafkaPartitionReassignmentResource(),
 		},
 		DataSourcesMap: map[string]*schema.Resource{
-			"kafka_topic": kafkaTopicDataSource(),
+			"kafka_topic":            kafkaTopicDataSource(),
+			"kafka_topic_config":     kafkaTopicConfigDataSource(),
+			"kafka_topic_offsets":    kafkaTopicOffsetsDataSource(),
+			"kafka_topics":           kafkaTopicsDataSource(),
+			"kafka_consumer_groups":  kafkaConsumerGroupsDataSource(),
+			"kafka_acls":             kafkaACLsDataSource(),
+			"kafka_brokers":          kafkaBrokersDataSource(),
+			"kafka_broker_loggers":   kafkaBrokerLoggersDataSource(),
+			"kafka_cluster":          kafkaClusterDataSource(),
+			"kafka_sasl_mechanisms":  kafkaSASLMechanismsDataSource(),
+			"kafka_scram_credential": kafkaScramCredentialDataSource(),
 		},
 	}
 }
 
+// providerConfigureContext configures the provider and checks that Kafka is
+// reachable. As the client connects lazily, e.g. to a cluster created in the
+// same apply, an unreachable cluster is only a warning here.
+func providerConfigureContext(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
+	meta, err := providerConfigure(d)
+	if err != nil {
+		return nil, diag.FromErr(err)
+	}
+
+	client := meta.(*LazyClient)
+	diags := skipTLSVerifyWarning(client.Config)
+	if srv := client.Config.BootstrapServersSRV; srv != "" {
+		servers, err := lookupBootstrapServersSRV(ctx, net.DefaultResolver, srv)
+		if err != nil {
+			return nil, append(diags, diag.Diagnostic{
+				Severity:      diag.Error,
+				Summary:       "Could not look up the bootstrap servers",
+				Detail:        err.Error(),
+				AttributePath: cty.GetAttrPath("bootstrap_servers_srv"),
+			})
+		}
+		log.Printf("[INFO] Looked up bootstrap servers %v from %s", servers, srv)
+		client.Config.BootstrapServers = &servers
+	}
+	if client.Config.BootstrapServers == nil || len(*client.Config.BootstrapServers) == 0 {
+		// not known yet, e.g. when they are outputs of a cluster to create
+		return client, diags
+	}
+
+	errs := validateBootstrapServers(cty.GetAttrPath("bootstrap_servers"), *client.Config.BootstrapServers)
+	for i, servers := range client.Config.FailoverBootstrapServers {
+		errs = append(errs, validateBootstrapServers(cty.GetAttrPath("failover_bootstrap_servers").IndexInt(i).GetAttr("bootstrap_servers"), servers)...)
+	}
+	if errs.HasError() {
+		return nil, append(diags, errs...)
+	}
+
+	if _, err := client.reachableBootstrapServers(); err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  "Kafka is not reachable",
+			Detail:   fmt.Sprintf("%s. Reading or changing Kafka resources will fail until it is.", err),
+		})
+	}
+
+	return client, diags
+}
+
+// skipTLSVerifyWarning warns that the brokers' certificates are not
+// verified, so that it is seen in every plan rather than only in the logs
+func skipTLSVerifyWarning(config *Config) diag.Diagnostics {
+	if !config.TLSEnabled || !config.SkipTLSVerify {
+		return nil
+	}
+	return diag.Diagnostics{{
+		Severity:      diag.Warning,
+		Summary:       "TLS certificate verification is disabled",
+		Detail:        "skip_tls_verify is true, so the brokers' certificates are not verified and the connection to Kafka is open to interception. Set ca_cert instead, or tls_server_name if the certificates are issued for another name.",
+		AttributePath: cty.GetAttrPath("skip_tls_verify"),
+	}}
+}
+
+// validateBootstrapServers returns an error for each server of the
+// attribute at path that isn't a host:port. Empty ones are left out, as they
+// aren't known yet.
+func validateBootstrapServers(path cty.Path, servers []string) diag.Diagnostics {
+	var diags diag.Diagnostics
+	for i, server := range servers {
+		if server == "" {
+			continue
+		}
+		if err := validateBootstrapServer(server); err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity:      diag.Error,
+				Summary:       "Invalid bootstrap server",
+				Detail:        fmt.Sprintf("bootstrap_servers[%d] %q is not a host:port: %s", i, server, err),
+				AttributePath: path.IndexInt(i),
+			})
+		}
+	}
+	return diags
+}
+
+// srvResolver looks up SRV records, as net.Resolver does
+type srvResolver interface {
+	LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+}
+
+// lookupBootstrapServersSRV returns the host:port of each target of the SRV
+// record name, e.g. _kafka._tcp.example.com, in the order of their priority
+func lookupBootstrapServersSRV(ctx context.Context, resolver srvResolver, name string) ([]string, error) {
+	_, records, err := resolver.LookupSRV(ctx, "", "", name)
+	if err != nil {
+		return nil, fmt.Errorf("error looking up SRV record %s: %w", name, err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("SRV record %s has no targets", name)
+	}
+
+	servers := make([]string, 0, len(records))
+	for _, r := range records {
+		servers = append(servers, net.JoinHostPort(strings.TrimSuffix(r.Target, "."), strconv.Itoa(int(r.Port))))
+	}
+	return servers, nil
+}
+
+func validateBootstrapServer(server string) error {
+	if i := strings.Index(server, "://"); i >= 0 {
+		return fmt.Errorf("remove the %s:// scheme, e.g. %q", server[:i], server[i+len("://"):])
+	}
+	if strings.TrimSpace(server) != server {
+		return fmt.Errorf("remove the surrounding whitespace")
+	}
+
+	host, port, err := net.SplitHostPort(server)
+	if err != nil {
+		if suggestion := bracketedIPv6(server); suggestion != "" {
+			return fmt.Errorf("%w; put IPv6 addresses in brackets, e.g. %q", err, suggestion)
+		}
+		return err
+	}
+	if host == "" {
+		return fmt.Errorf("missing host")
+	}
+	if p, err := strconv.Atoi(port); err != nil || p < 1 || p > 65535 {
+		return fmt.Errorf("invalid port %q", port)
+	}
+	return nil
+}
+
+// bracketedIPv6 suggests the bracketed form of an IPv6 address given without
+// brackets, or returns "" if server is not one. As the last group of an
+// address can look like a port, it is taken as the port when what is before
+// it is an address on its own.
+func bracketedIPv6(server string) string {
+	if i := strings.LastIndex(server, ":"); i > 0 {
+		if ip := net.ParseIP(server[:i]); ip != nil && ip.To4() == nil {
+			return fmt.Sprintf("[%s]%s", server[:i], server[i:])
+		}
+	}
+	if ip := net.ParseIP(server); ip != nil && ip.To4() == nil {
+		return fmt.Sprintf("[%s]:9092", server)
+	}
+	return ""
+}
+
 func providerConfigure(d *schema.ResourceData) (interface{}, error) {
 	brokers := dTos("bootstrap_servers", d)
+	bootstrapServersSRV := d.Get("bootstrap_servers_srv").(string)
+	if brokers != nil && len(*brokers) > 0 && bootstrapServersSRV != "" {
+		return nil, fmt.Errorf("only one of bootstrap_servers and bootstrap_servers_srv can be set")
+	}
+	if brokers == nil && bootstrapServersSRV == "" && bootstrapServersUnset(d) {
+		return nil, fmt.Errorf("one of bootstrap_servers or bootstrap_servers_srv must be set")
+	}
 
 	log.Printf("[TRACE] configuring provider with brokers @ %v", brokers)
 
 	saslMechanism := d.Get("sasl_mechanism").(string)
 	switch saslMechanism {
 	case "scram-sha512", "scram-sha256", "aws-iam", "oauthbearer", "plain":
+	case "scram-sha512-plus", "scram-sha256-plus":
+		// sarama only runs the SCRAM exchange for the plain mechanisms, and
+		// gives the SCRAM client no access to the TLS connection to bind to
+		return nil, fmt.Errorf("[ERROR] Invalid sasl mechanism \"%s\": SCRAM with channel binding is not supported by the Kafka client; use \"%s\" over TLS instead", saslMechanism, strings.TrimSuffix(saslMechanism, "-plus"))
 	default:
 		return nil, fmt.Errorf("[ERROR] Invalid sasl mechanism \"%s\": can only be \"scram-sha256\", \"scram-sha512\", \"aws-iam\", \"oauthbearer\" or \"plain\"", saslMechanism)
 	}
 
+	saslHandshake := d.Get("sasl_handshake").(bool)
+	if !saslHandshake && saslMechanism != "plain" {
+		return nil, fmt.Errorf("sasl_handshake can only be disabled with the plain sasl mechanism, got %q", saslMechanism)
+	}
+	var saslVersion *int
+	if v, ok := d.GetOkExists("sasl_version"); ok { //nolint:staticcheck
+		version := v.(int)
+		saslVersion = &version
+		if version == 0 && (saslMechanism == "oauthbearer" || saslMechanism == "aws-iam") {
+			// sarama would silently use version 1, the only one OAUTHBEARER has
+			return nil, fmt.Errorf("sasl_version 0 is not supported by the %s sasl mechanism, which requires version 1", saslMechanism)
+		}
+		if version != 0 && !saslHandshake {
+			return nil, fmt.Errorf("sasl_version %d requires sasl_handshake, only 0 can be used without it", version)
+		}
+	}
+
+	retryMaxRetries := defaultRetryMaxRetries
+	retryMaxElapsedTime := defaultRetryMaxElapsedTime
+	adminRetryMax := defaultAdminRetryMax
+	adminRetryBackoff := defaultAdminRetryBackoff
+	if v, ok := d.Get("retry").([]interface{}); ok && len(v) > 0 && v[0] != nil {
+		retry := v[0].(map[string]interface{})
+		retryMaxRetries = retry["max_retries"].(int)
+		retryMaxElapsedTime = retry["max_elapsed_time"].(int)
+		adminRetryMax = retry["admin_max_retries"].(int)
+		adminRetryBackoff = retry["admin_backoff"].(int)
+	}
+
+	metadataFull := d.Get("metadata_full").(bool)
+
 	config := &Config{
 		BootstrapServers:                       brokers,
 		CACert:                                 d.Get("ca_cert").(string),
+		CACerts:                                stringSliceFromResourceData("ca_certs", d),
 		ClientCert:                             d.Get("client_cert").(string),
 		ClientCertKey:                          d.Get("client_key").(string),
 		ClientCertKeyPassphrase:                d.Get("client_key_passphrase").(string),
+		ClientCertKeyPassphraseFile:            d.Get("client_key_passphrase_file").(string),
+		ClientCertFormat:                       d.Get("client_cert_format").(string),
 		KafkaVersion:                           d.Get("kafka_version").(string),
 		SkipTLSVerify:                          d.Get("skip_tls_verify").(bool),
 		SASLAWSRegion:                          d.Get("sasl_aws_region").(string),
@@ -226,6 +827,8 @@ func providerConfigure(d *schema.ResourceData) (interface{}, error) {
 		SASLTokenUrl:                           d.Get("sasl_token_url").(string),
 		SASLAWSRoleArn:                         d.Get("sasl_aws_role_arn").(string),
 		SASLAWSExternalId:                      d.Get("sasl_aws_external_id").(string),
+		SASLAWSWebIdentityTokenFile:            d.Get("sasl_aws_web_identity_token_file").(string),
+		SASLAWSRoleArnChain:                    awsRoleChainFromResourceData(d),
 		SASLAWSProfile:                         d.Get("sasl_aws_profile").(string),
 		SASLAWSSharedConfigFiles:               dTos("sasl_aws_shared_config_files", d),
 		SASLAWSAccessKey:                       d.Get("sasl_aws_access_key").(string),
@@ -233,9 +836,51 @@ func providerConfigure(d *schema.ResourceData) (interface{}, error) {
 		SASLAWSToken:                           d.Get("sasl_aws_token").(string),
 		SASLAWSCredsDebug:                      d.Get("sasl_aws_creds_debug").(bool),
 		SASLOAuthScopes:                        stringSliceFromResourceData("sasl_oauth_scopes", d),
+		SASLOAuthClientCertEnabled:             d.Get("sasl_oauth_client_cert_enabled").(bool),
+		SASLOAuthRefreshSkewSeconds:            d.Get("sasl_oauth_refresh_skew").(int),
+		SASLOAuthRefreshJitterSeconds:          d.Get("sasl_oauth_refresh_jitter").(int),
+		SASLOAuthTokenSource:                   d.Get("sasl_oauth_token_source").(string),
+		SASLOAuthTokenFile:                     d.Get("sasl_oauth_token_file").(string),
+		SASLOAuthTokenCommand:                  stringSliceFromResourceData("sasl_oauth_token_command", d),
+		SASLOAuthAzureClientID:                 d.Get("sasl_oauth_azure_client_id").(string),
+		SASLOAuthExtensions:                    stringMapFromResourceData("sasl_oauth_extensions", d),
 		SASLMechanism:                          saslMechanism,
+		SASLTokenAuth:                          d.Get("sasl_token_auth").(bool),
+		RetryMaxRetries:                        retryMaxRetries,
+		RetryMaxElapsedTime:                    retryMaxElapsedTime,
+		AdminRetryMax:                          &adminRetryMax,
+		AdminRetryBackoff:                      adminRetryBackoff,
 		TLSEnabled:                             d.Get("tls_enabled").(bool),
+		TLSMinVersion:                          d.Get("tls_min_version").(string),
+		TLSMaxVersion:                          d.Get("tls_max_version").(string),
+		TLSCipherSuites:                        stringSliceFromResourceData("tls_cipher_suites", d),
+		TLSServerName:                          d.Get("tls_server_name").(string),
+		TLSExclusiveCA:                         d.Get("tls_exclusive_ca").(bool),
+		ProxyURL:                               d.Get("proxy_url").(string),
+		ProxyUsername:                          d.Get("proxy_username").(string),
+		ProxyPassword:                          d.Get("proxy_password").(string),
 		Timeout:                                d.Get("timeout").(int),
+		ClientID:                               d.Get("client_id").(string),
+		DialTimeout:                            d.Get("dial_timeout").(int),
+		ReadTimeout:                            d.Get("read_timeout").(int),
+		WriteTimeout:                           d.Get("write_timeout").(int),
+		MetadataTimeout:                        d.Get("metadata_timeout").(int),
+		MetadataRefreshFrequency:               d.Get("metadata_refresh_frequency").(int),
+		MetadataFull:                           &metadataFull,
+		KeepAlive:                              d.Get("keep_alive").(int),
+		MaxOpenRequests:                        d.Get("max_open_requests").(int),
+		SASLAuthzID:                            d.Get("sasl_authz_id").(string),
+		FailoverBootstrapServers:               failoverBootstrapServersFromResourceData(d),
+		SASLDisableHandshake:                   !saslHandshake,
+		SASLVersion:                            saslVersion,
+		ResolveCanonicalBootstrapServers:       d.Get("resolve_canonical_bootstrap_servers").(bool),
+		DefaultTopicConfig:                     stringMapFromResourceData("default_topic_config", d),
+		MaxConcurrency:                         d.Get("max_concurrency").(int),
+		ValidateOnly:                           d.Get("validate_only").(bool),
+		AllowAutoTopicCreation:                 d.Get("allow_auto_topic_creation").(bool),
+		Debug:                                  d.Get("debug").(bool),
+		SSHTunnel:                              sshTunnelFromResourceData(d),
+		BootstrapServersSRV:                    bootstrapServersSRV,
 	}
 
 	if config.CACert == "" {
@@ -248,7 +893,11 @@ func providerConfigure(d *schema.ResourceData) (interface{}, error) {
 		config.ClientCertKey = d.Get("client_key_file").(string)
 	}
 
-	log.Printf("[TRACE] Config @ %v", config.copyWithMaskedSensitiveValues())
+	log.Printf("[INFO] Configuring the Kafka provider with %s: %+v", config.summary(), config.copyWithMaskedSensitiveValues())
+
+	if config.Debug {
+		installSaramaLogger(config)
+	}
 
 	return &LazyClient{
 		Config: config,
@@ -278,6 +927,18 @@ func dTos(key string, d *schema.ResourceData) *[]string {
 	return r
 }
 
+func stringMapFromResourceData(key string, d *schema.ResourceData) map[string]string {
+	result := map[string]string{}
+	if v, ok := d.GetOk(key); ok && v != nil {
+		for k, vv := range v.(map[string]interface{}) {
+			if s, ok := vv.(string); ok {
+				result[k] = s
+			}
+		}
+	}
+	return result
+}
+
 func stringSliceFromResourceData(key string, d *schema.ResourceData) []string {
 	var result []string
 	if v, ok := d.GetOk(key); ok && v != nil {
@@ -291,3 +952,64 @@ func stringSliceFromResourceData(key string, d *schema.ResourceData) []string {
 	}
 	return result
 }
+
+func awsRoleChainFromResourceData(d *schema.ResourceData) []AWSAssumeRole {
+	var chain []AWSAssumeRole
+	for _, v := range d.Get("sasl_aws_role_chain").([]interface{}) {
+		if v == nil {
+			continue
+		}
+		role := v.(map[string]interface{})
+		chain = append(chain, AWSAssumeRole{
+			RoleArn:     role["role_arn"].(string),
+			ExternalId:  role["external_id"].(string),
+			SessionName: role["session_name"].(string),
+		})
+	}
+	return chain
+}
+
+func failoverBootstrapServersFromResourceData(d *schema.ResourceData) [][]string {
+	var groups [][]string
+	for _, v := range d.Get("failover_bootstrap_servers").([]interface{}) {
+		if v == nil {
+			continue
+		}
+		var servers []string
+		for _, s := range v.(map[string]interface{})["bootstrap_servers"].([]interface{}) {
+			if s != nil {
+				servers = append(servers, s.(string))
+			}
+		}
+		groups = append(groups, servers)
+	}
+	return groups
+}
+
+func sshTunnelFromResourceData(d *schema.ResourceData) *SSHTunnel {
+	v, ok := d.Get("ssh_tunnel").([]interface{})
+	if !ok || len(v) == 0 || v[0] == nil {
+		return nil
+	}
+	tunnel := v[0].(map[string]interface{})
+	return &SSHTunnel{
+		Host:                  tunnel["host"].(string),
+		User:                  tunnel["user"].(string),
+		PrivateKey:            tunnel["private_key"].(string),
+		PrivateKeyFile:        tunnel["private_key_file"].(string),
+		PrivateKeyPassphrase:  tunnel["private_key_passphrase"].(string),
+		KnownHostsFile:        tunnel["known_hosts_file"].(string),
+		InsecureIgnoreHostKey: tunnel["insecure_ignore_host_key"].(bool),
+	}
+}
+
+// bootstrapServersUnset reports whether neither bootstrap_servers nor
+// bootstrap_servers_srv is in the configuration, as opposed to being unknown
+// until e.g. the cluster they are outputs of is created
+func bootstrapServersUnset(d *schema.ResourceData) bool {
+	raw := d.GetRawConfig()
+	if raw.IsNull() || !raw.IsKnown() {
+		return false
+	}
+	return raw.GetAttr("bootstrap_servers").IsNull() && raw.GetAttr("bootstrap_servers_srv").IsNull()
+}
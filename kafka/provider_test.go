@@ -4,10 +4,14 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net"
 	"os"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
@@ -93,3 +97,223 @@ func bootstrapServersFromEnv() []string {
 
 	return bootstrapServers
 }
+
+func Test_validateBootstrapServers(t *testing.T) {
+	diags := validateBootstrapServers(cty.GetAttrPath("bootstrap_servers"), []string{"localhost:9092", "[::1]:9092", "", "b-1.msk.amazonaws.com:9098", "10.0.0.1:9092", "[2001:db8::1]:9092", "[fe80::1%eth0]:9092"})
+	if len(diags) != 0 {
+		t.Errorf("expected no errors, got %v", diags)
+	}
+
+	for server, reason := range map[string]string{
+		"localhost":            "missing port",
+		"kafka://broker:9092":  `remove the kafka:// scheme, e.g. "broker:9092"`,
+		"SASL_SSL://host:9093": "remove the SASL_SSL:// scheme",
+		" localhost:9092":      "whitespace",
+		":9092":                "missing host",
+		"localhost:kafka":      `invalid port "kafka"`,
+		"localhost:0":          `invalid port "0"`,
+		"::1:9092":             `too many colons in address; put IPv6 addresses in brackets, e.g. "[::1]:9092"`,
+		"2001:db8::1":          `put IPv6 addresses in brackets, e.g. "[2001:db8::1]:9092"`,
+		"2001:db8::1:9093":     `e.g. "[2001:db8::1]:9093"`,
+		"[2001:db8::1]":        "missing port",
+		"[::1]:0":              `invalid port "0"`,
+	} {
+		diags := validateBootstrapServers(cty.GetAttrPath("bootstrap_servers"), []string{"localhost:9092", server})
+		if len(diags) != 1 {
+			t.Errorf("expected an error for %q, got %v", server, diags)
+			continue
+		}
+		if !strings.Contains(diags[0].Detail, reason) || !strings.Contains(diags[0].Detail, "bootstrap_servers[1]") {
+			t.Errorf("expected the error for %q to mention %q, got %q", server, reason, diags[0].Detail)
+		}
+		if !diags[0].AttributePath.Equals(cty.GetAttrPath("bootstrap_servers").IndexInt(1)) {
+			t.Errorf("expected the error for %q to point at bootstrap_servers[1], got %v", server, diags[0].AttributePath)
+		}
+	}
+}
+
+func Test_providerConfigureSkipTLSVerify(t *testing.T) {
+	for _, tc := range []struct {
+		raw      map[string]interface{}
+		warnings int
+	}{
+		{raw: map[string]interface{}{"skip_tls_verify": true}, warnings: 1},
+		{raw: map[string]interface{}{"skip_tls_verify": true, "tls_enabled": false}, warnings: 0},
+		{raw: map[string]interface{}{"skip_tls_verify": false}, warnings: 0},
+	} {
+		// no bootstrap servers, so that Kafka is not pinged
+		tc.raw["bootstrap_servers"] = []interface{}{}
+		d := schema.TestResourceDataRaw(t, Provider().Schema, tc.raw)
+		_, diags := providerConfigureContext(context.Background(), d)
+		if diags.HasError() {
+			t.Fatalf("unexpected errors %v", diags)
+		}
+		assertEquals(t, tc.warnings, len(diags))
+		if tc.warnings > 0 {
+			assertEquals(t, "TLS certificate verification is disabled", diags[0].Summary)
+			if !diags[0].AttributePath.Equals(cty.GetAttrPath("skip_tls_verify")) {
+				t.Errorf("expected the warning to point at skip_tls_verify, got %v", diags[0].AttributePath)
+			}
+		}
+	}
+}
+
+func Test_providerEnvDefaults(t *testing.T) {
+	t.Setenv("KAFKA_BOOTSTRAP_SERVERS", "kafka-1:9092, kafka-2:9092")
+	t.Setenv("KAFKA_SASL_USERNAME", "from-env")
+	t.Setenv("KAFKA_SASL_PASSWORD", "secret")
+	t.Setenv("KAFKA_TIMEOUT", "30")
+	t.Setenv("KAFKA_VALIDATE_ONLY", "true")
+
+	def, err := Provider().Schema["bootstrap_servers"].DefaultValue()
+	assertNil(t, err)
+	if !reflect.DeepEqual([]interface{}{"kafka-1:9092", "kafka-2:9092"}, def) {
+		t.Errorf("expected the bootstrap servers of KAFKA_BOOTSTRAP_SERVERS, got %v", def)
+	}
+
+	// the configuration takes precedence over the environment
+	d := schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{
+		"bootstrap_servers": []interface{}{},
+		"sasl_username":     "from-config",
+	})
+	meta, err := providerConfigure(d)
+	assertNil(t, err)
+	config := meta.(*LazyClient).Config
+	assertEquals(t, "from-config", config.SASLUsername)
+	assertEquals(t, "secret", config.SASLPassword)
+	assertEquals(t, 30, config.Timeout)
+	assertEquals(t, true, config.ValidateOnly)
+	assertEquals(t, "*****", config.copyWithMaskedSensitiveValues().SASLPassword)
+}
+
+type testSRVResolver struct {
+	records []*net.SRV
+	err     error
+}
+
+func (r testSRVResolver) LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+	return "", r.records, r.err
+}
+
+func Test_lookupBootstrapServersSRV(t *testing.T) {
+	servers, err := lookupBootstrapServersSRV(context.Background(), testSRVResolver{records: []*net.SRV{
+		{Target: "b-1.kafka.example.com.", Port: 9092, Priority: 10},
+		{Target: "b-2.kafka.example.com.", Port: 9093, Priority: 20},
+	}}, "_kafka._tcp.example.com")
+	assertNil(t, err)
+	if !reflect.DeepEqual([]string{"b-1.kafka.example.com:9092", "b-2.kafka.example.com:9093"}, servers) {
+		t.Errorf("unexpected bootstrap servers %v", servers)
+	}
+
+	_, err = lookupBootstrapServersSRV(context.Background(), testSRVResolver{}, "_kafka._tcp.example.com")
+	if err == nil || !strings.Contains(err.Error(), "SRV record _kafka._tcp.example.com has no targets") {
+		t.Errorf("expected an error for an SRV record without targets, got %v", err)
+	}
+
+	_, err = lookupBootstrapServersSRV(context.Background(), testSRVResolver{err: &net.DNSError{Err: "no such host", Name: "_kafka._tcp.example.com", IsNotFound: true}}, "_kafka._tcp.example.com")
+	if err == nil || !strings.Contains(err.Error(), "error looking up SRV record _kafka._tcp.example.com") {
+		t.Errorf("expected an error for a missing SRV record, got %v", err)
+	}
+}
+
+func Test_providerConfigureBootstrapServersSRV(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{
+		"bootstrap_servers":     []interface{}{"localhost:9092"},
+		"bootstrap_servers_srv": "_kafka._tcp.example.com",
+	})
+	if _, err := providerConfigure(d); err == nil {
+		t.Error("expected an error with both bootstrap_servers and bootstrap_servers_srv set")
+	}
+
+	d = schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{
+		"bootstrap_servers_srv": "_kafka._tcp.invalid",
+	})
+	// .invalid never resolves
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, diags := providerConfigureContext(ctx, d)
+	if !diags.HasError() || !diags[len(diags)-1].AttributePath.Equals(cty.GetAttrPath("bootstrap_servers_srv")) {
+		t.Errorf("expected an error for bootstrap_servers_srv, got %v", diags)
+	}
+}
+
+func Test_providerConfigureFailoverBootstrapServers(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{
+		"bootstrap_servers": []interface{}{"localhost:9092"},
+		"failover_bootstrap_servers": []interface{}{
+			map[string]interface{}{"bootstrap_servers": []interface{}{"dr-1:9092", "dr-2:9092"}},
+		},
+	})
+	meta, err := providerConfigure(d)
+	assertNil(t, err)
+	assertEquals(t, true, reflect.DeepEqual([][]string{{"dr-1:9092", "dr-2:9092"}}, meta.(*LazyClient).Config.FailoverBootstrapServers))
+
+	d = schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{
+		"bootstrap_servers": []interface{}{"localhost:9092"},
+		"failover_bootstrap_servers": []interface{}{
+			map[string]interface{}{"bootstrap_servers": []interface{}{"kafka://dr-1:9092"}},
+		},
+	})
+	_, diags := providerConfigureContext(context.Background(), d)
+	expected := cty.GetAttrPath("failover_bootstrap_servers").IndexInt(0).GetAttr("bootstrap_servers").IndexInt(0)
+	if !diags.HasError() || !diags[len(diags)-1].AttributePath.Equals(expected) {
+		t.Errorf("expected an error for failover_bootstrap_servers, got %v", diags)
+	}
+}
+
+func Test_providerConfigureSASLHandshake(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{
+		"bootstrap_servers": []interface{}{"localhost:9092"},
+		"sasl_mechanism":    "plain",
+	})
+	meta, err := providerConfigure(d)
+	assertNil(t, err)
+	assertEquals(t, false, meta.(*LazyClient).Config.SASLDisableHandshake)
+
+	d = schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{
+		"bootstrap_servers": []interface{}{"localhost:9092"},
+		"sasl_mechanism":    "scram-sha512",
+		"sasl_handshake":    false,
+	})
+	if _, err := providerConfigure(d); err == nil || !strings.Contains(err.Error(), "sasl_handshake can only be disabled") {
+		t.Errorf("expected sasl_handshake = false to be rejected with scram-sha512, got %v", err)
+	}
+}
+
+func Test_providerConfigureSASLVersion(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{
+		"bootstrap_servers": []interface{}{"localhost:9092"},
+	})
+	meta, err := providerConfigure(d)
+	assertNil(t, err)
+	if version := meta.(*LazyClient).Config.SASLVersion; version != nil {
+		t.Errorf("expected sasl_version to be unset, got %d", *version)
+	}
+
+	d = schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{
+		"bootstrap_servers": []interface{}{"localhost:9092"},
+		"sasl_version":      0,
+	})
+	meta, err = providerConfigure(d)
+	assertNil(t, err)
+	assertEquals(t, 0, *meta.(*LazyClient).Config.SASLVersion)
+
+	d = schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{
+		"bootstrap_servers": []interface{}{"localhost:9092"},
+		"sasl_mechanism":    "oauthbearer",
+		"sasl_version":      0,
+	})
+	if _, err := providerConfigure(d); err == nil || !strings.Contains(err.Error(), "sasl_version 0 is not supported") {
+		t.Errorf("expected sasl_version 0 to be rejected with oauthbearer, got %v", err)
+	}
+
+	d = schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{
+		"bootstrap_servers": []interface{}{"localhost:9092"},
+		"sasl_mechanism":    "plain",
+		"sasl_handshake":    false,
+		"sasl_version":      1,
+	})
+	if _, err := providerConfigure(d); err == nil || !strings.Contains(err.Error(), "sasl_version 1 requires sasl_handshake") {
+		t.Errorf("expected sasl_version 1 to be rejected without the handshake, got %v", err)
+	}
+}
@@ -4,11 +4,14 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/IBM/sarama"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 func kafkaACLResource() *schema.Resource {
@@ -16,10 +19,13 @@ func kafkaACLResource() *schema.Resource {
 	return &schema.Resource{
 		CreateContext: aclCreate,
 		ReadContext:   aclRead,
+		UpdateContext: aclUpdate,
 		DeleteContext: aclDelete,
 		Importer: &schema.ResourceImporter{
 			StateContext: importACL,
 		},
+		CustomizeDiff: aclCustomDiff,
+		Timeouts:      resourceTimeouts(),
 		SchemaVersion: 1,
 		MigrateState:  migrateKafkaAclState,
 		Schema: map[string]*schema.Schema{
@@ -30,83 +36,219 @@ func kafkaACLResource() *schema.Resource {
 				Description: "The name of the resource",
 			},
 			"resource_type": {
-				Type:     schema.TypeString,
-				Required: true,
-				ForceNew: true,
+				Type:             schema.TypeString,
+				Required:         true,
+				ForceNew:         true,
+				ValidateDiagFunc: validateDiagFunc(validation.StringInSlice(aclResourceTypes, false)),
 			},
 			"resource_pattern_type_filter": {
-				Type:     schema.TypeString,
-				Default:  "Literal",
-				Optional: true,
-				ForceNew: true,
+				Type:             schema.TypeString,
+				Default:          "Literal",
+				Optional:         true,
+				ForceNew:         true,
+				ValidateDiagFunc: validateDiagFunc(validation.StringInSlice([]string{"Literal", "Prefixed"}, false)),
 			},
 			"acl_principal": {
-				Type:     schema.TypeString,
-				Required: true,
-				ForceNew: true,
+				Type:             schema.TypeString,
+				Required:         true,
+				StateFunc:        func(v interface{}) string { return normalizeACLPrincipal(v.(string)) },
+				ValidateDiagFunc: validateDiagFunc(validateACLPrincipal),
+				Description:      "The principal, e.g. User:alice, or User:* for every user.",
 			},
 			"acl_host": {
-				Type:     schema.TypeString,
-				Required: true,
-				ForceNew: true,
+				Type:        schema.TypeString,
+				Required:    true,
+				StateFunc:   func(v interface{}) string { return normalizeACLHost(v.(string)) },
+				Description: "The host the principal connects from, or * for every host.",
 			},
 			"acl_operation": {
-				Type:     schema.TypeString,
-				Required: true,
-				ForceNew: true,
+				Type:         schema.TypeString,
+				Optional:     true,
+				ExactlyOneOf: []string{"acl_operation", "acl_operations"},
+			},
+			"acl_operations": {
+				Type:         schema.TypeSet,
+				Optional:     true,
+				MinItems:     1,
+				Elem:         &schema.Schema{Type: schema.TypeString},
+				ExactlyOneOf: []string{"acl_operation", "acl_operations"},
+				Description:  "Operations to bind, each with an ACL of its own, instead of the single acl_operation.",
 			},
 			"acl_permission_type": {
-				Type:     schema.TypeString,
-				Required: true,
-				ForceNew: true,
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          "Allow",
+				ValidateDiagFunc: validateDiagFunc(validation.StringInSlice([]string{"Allow", "Deny"}, false)),
+				Description:      "Whether the ACL allows or denies the operation. Deny ACLs take precedence over Allow ones.",
 			},
 		},
 	}
 }
 
+// aclResourceTypes are the types of resource ACLs can be created for
+var aclResourceTypes = []string{"Topic", "Group", "Cluster", "TransactionalID", "DelegationToken"}
+
+// the only name Kafka accepts for the Cluster resource
+const aclClusterResourceName = "kafka-cluster"
+
+// aclPrincipalTypes are the principal types Kafka's authorizer matches ACLs
+// for, spelt as it expects them
+var aclPrincipalTypes = []string{"User", "Group"}
+
+// normalizeACLPrincipal spells the type of the principal the way Kafka does,
+// e.g. user:alice as User:alice. Kafka matches the type exactly, so an ACL
+// for user:alice would never apply. The name is kept as it is.
+func normalizeACLPrincipal(principal string) string {
+	principal = strings.TrimSpace(principal)
+	principalType, name, ok := strings.Cut(principal, ":")
+	if !ok {
+		return principal
+	}
+	for _, t := range aclPrincipalTypes {
+		if strings.EqualFold(principalType, t) {
+			return t + ":" + strings.TrimSpace(name)
+		}
+	}
+	return principal
+}
+
+// normalizeACLHost returns the host the way Kafka lists it
+func normalizeACLHost(host string) string {
+	return strings.ToLower(strings.TrimSpace(host))
+}
+
+func validateACLPrincipal(v interface{}, key string) ([]string, []error) {
+	principal := normalizeACLPrincipal(v.(string))
+	principalType, name, _ := strings.Cut(principal, ":")
+	for _, t := range aclPrincipalTypes {
+		if principalType == t && name != "" {
+			return nil, nil
+		}
+	}
+	return nil, []error{fmt.Errorf("%s must be a principal type and name, e.g. User:alice or User:*, where the type is one of %s, got %q", key, strings.Join(aclPrincipalTypes, ", "), v)}
+}
+
+func aclCustomDiff(ctx context.Context, diff *schema.ResourceDiff, v interface{}) error {
+	for _, key := range []string{"resource_type", "resource_name", "resource_pattern_type_filter"} {
+		if !diff.NewValueKnown(key) {
+			return nil
+		}
+	}
+	return validateACLResource(
+		diff.Get("resource_type").(string),
+		diff.Get("resource_name").(string),
+		diff.Get("resource_pattern_type_filter").(string),
+	)
+}
+
+// validateACLResource rejects the resources Kafka refuses ACLs for, which
+// would otherwise only fail when applied
+func validateACLResource(resourceType, name, patternType string) error {
+	if resourceType != "Cluster" {
+		return nil
+	}
+	if patternType != "Literal" {
+		return fmt.Errorf("resource_pattern_type_filter %q is not supported for resource_type Cluster, which only matches the cluster literally", patternType)
+	}
+	if name != aclClusterResourceName {
+		return fmt.Errorf("resource_name of a Cluster ACL must be %q, got %q", aclClusterResourceName, name)
+	}
+	return nil
+}
+
 func aclCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*LazyClient)
-	a := aclInfo(d)
+	acls := aclsInfo(d)
 
-	log.Printf("[INFO] Creating ACL %s", a)
-	err := c.CreateACL(a)
+	log.Printf("[INFO] Creating ACLs %v", acls)
+	err := c.CreateACLs(ctx, acls)
 
 	if err != nil {
 		log.Println("[ERROR] Failed to create ACL")
-		return diag.FromErr(err)
+		return diagFromErr(err)
 	}
 
-	d.SetId(a.String())
+	d.SetId(aclID(d))
 
 	// Wait for ACL to be visible in Kafka before returning
 	// This handles eventual consistency and ensures the ACL is actually created
-	log.Printf("[INFO] Waiting for ACL %s to be visible in Kafka", a)
-	err = waitForACLToBeVisible(ctx, c, a)
+	timeout := operationTimeout(d, schema.TimeoutCreate, aclPropagationTimeout)
+	log.Printf("[INFO] Waiting for ACLs %v to be visible in Kafka", acls)
+	err = waitForACLs(ctx, c, acls, true, timeout)
 	if err != nil {
 		log.Printf("[ERROR] ACL created but not visible: %v", err)
-		return diag.FromErr(err)
+		return diagFromErr(err)
 	}
 
 	return nil
 }
 
+// aclUpdate replaces the bindings by creating the new ACLs before deleting
+// the old ones, so clients never lose authorization in between. Only the
+// ACLs that differ are created and deleted, e.g. removing one of
+// acl_operations only deletes its ACL. Changes to the resource the bindings
+// apply to, including its pattern type, force a new resource instead.
+func aclUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*LazyClient)
+	create, remove := aclChanges(oldACLsInfo(d), aclsInfo(d))
+	timeout := operationTimeout(d, schema.TimeoutUpdate, aclPropagationTimeout)
+
+	// until the old ACLs are deleted, the state keeps tracking them, so that
+	// a failure leaves none of them behind untracked
+	d.Partial(true)
+
+	log.Printf("[INFO] Updating ACLs: creating %v, deleting %v", create, remove)
+	if len(create) > 0 {
+		err := c.CreateACLs(ctx, create)
+		if err != nil {
+			log.Println("[ERROR] Failed to create ACL")
+			return diagFromErr(err)
+		}
+
+		err = waitForACLs(ctx, c, create, true, timeout)
+		if err != nil {
+			log.Printf("[ERROR] ACL created but not visible: %v", err)
+			return diagFromErr(err)
+		}
+	}
+
+	if len(remove) > 0 {
+		err := c.DeleteACLs(ctx, remove)
+		if err != nil {
+			log.Printf("[ERROR] Failed to delete previous ACLs %v", remove)
+			return diagFromErr(err)
+		}
+
+		err = waitForACLs(ctx, c, remove, false, timeout)
+		if err != nil {
+			log.Printf("[ERROR] ACL deletion requested but still visible: %v", err)
+			return diagFromErr(err)
+		}
+	}
+
+	d.Partial(false)
+	d.SetId(aclID(d))
+	return nil
+}
+
 func aclDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*LazyClient)
-	a := aclInfo(d)
-	log.Printf("[INFO] Deleting ACL %s", a)
+	acls := aclsInfo(d)
+	log.Printf("[INFO] Deleting ACLs %v", acls)
 
-	err := c.DeleteACL(a)
+	err := c.DeleteACLs(ctx, acls)
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromErr(err)
 	}
 
 	// Wait for ACL to be removed from Kafka before returning
 	// This handles eventual consistency and ensures the ACL is actually deleted
-	log.Printf("[INFO] Waiting for ACL %s to be removed from Kafka", a)
-	err = waitForACLToBeDeleted(ctx, c, a)
+	timeout := operationTimeout(d, schema.TimeoutDelete, aclPropagationTimeout)
+	log.Printf("[INFO] Waiting for ACLs %v to be removed from Kafka", acls)
+	err = waitForACLs(ctx, c, acls, false, timeout)
 	if err != nil {
 		log.Printf("[ERROR] ACL deletion requested but still visible: %v", err)
-		return diag.FromErr(err)
+		return diagFromErr(err)
 	}
 
 	return nil
@@ -115,17 +257,18 @@ func aclDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) di
 func aclRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Println("[INFO] Reading ACL")
 	c := meta.(*LazyClient)
-	a := aclInfo(d)
-	log.Printf("[INFO] Reading ACL %s", a)
+	expected := aclsInfo(d)
+	log.Printf("[INFO] Reading ACLs %v", expected)
 
 	currentACLs, err := c.ListACLs()
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromErr(err)
 	}
 
+	existing := map[string]bool{}
 	for _, foundACLs := range currentACLs {
 		// find only ACLs where ResourceName matches
-		if foundACLs.ResourceName != a.Resource.Name {
+		if foundACLs.ResourceName != expected[0].Resource.Name {
 			continue
 		}
 		if len(foundACLs.Acls) < 1 {
@@ -134,55 +277,141 @@ func aclRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag
 		log.Printf("[INFO] Found (%d) ACL(s) for Resource %s: %+v.", len(foundACLs.Acls), foundACLs.ResourceName, foundACLs)
 
 		for _, acl := range foundACLs.Acls {
-			aclID := StringlyTypedACL{
-				ACL: ACL{
-					Principal:      acl.Principal,
-					Host:           acl.Host,
-					Operation:      ACLOperationToString(acl.Operation),
-					PermissionType: ACLPermissionTypeToString(acl.PermissionType),
-				},
-				Resource: Resource{
-					Type:              ACLResourceToString(foundACLs.ResourceType),
-					Name:              foundACLs.ResourceName,
-					PatternTypeFilter: foundACLs.ResourcePatternType.String(),
-				},
-			}
+			existing[aclFromResourceAcls(foundACLs, acl).String()] = true
+		}
+	}
 
-			// Found the ACL, so no need to remove it from state
-			if a.String() == aclID.String() {
-				return nil
-			}
+	// only the managed operations are read back, as ACLs of other
+	// operations of the same principal may belong to other resources
+	operations := []string{}
+	for _, a := range expected {
+		if existing[a.String()] {
+			operations = append(operations, a.ACL.Operation)
+		} else {
+			log.Printf("[INFO] Did not find ACL %s", a)
 		}
 	}
 
-	// If we get here, the ACL was not found
-	log.Printf("[INFO] Did not find ACL %s", a.String())
-	d.SetId("")
+	if len(operations) == 0 {
+		d.SetId("")
+		return nil
+	}
+
+	if _, ok := d.GetOk("acl_operations"); ok {
+		if err := d.Set("acl_operations", operations); err != nil {
+			return diagFromErr(err)
+		}
+	}
 
 	return nil
 }
 
+// importACL imports an ACL by its ID, or by its canonical form, listing the
+// binding in the order of Kafka's own tools, e.g.
+// Topic#orders#LITERAL#User:svc#*#Read#Allow
 func importACL(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
-	parts := strings.Split(d.Id(), "|")
-	if len(parts) == 7 {
-		errSet := errSetter{d: d}
-		errSet.Set("acl_principal", parts[0])
-		errSet.Set("acl_host", parts[1])
-		errSet.Set("acl_operation", parts[2])
-		errSet.Set("acl_permission_type", parts[3])
-		errSet.Set("resource_type", parts[4])
-		errSet.Set("resource_name", parts[5])
-		errSet.Set("resource_pattern_type_filter", parts[6])
-		if errSet.err != nil {
-			return nil, errSet.err
-		}
+	a, operations, err := parseACLImportID(d.Id())
+	if err != nil {
+		return nil, fmt.Errorf("failed importing resource; %w", err)
+	}
+
+	errSet := errSetter{d: d}
+	errSet.Set("acl_principal", a.ACL.Principal)
+	errSet.Set("acl_host", a.ACL.Host)
+	if len(operations) > 1 {
+		errSet.Set("acl_operations", operations)
 	} else {
-		return nil, fmt.Errorf("failed importing resource; expected format is acl_principal|acl_host|acl_operation|acl_permission_type|resource_type|resource_name|resource_pattern_type_filter - got %v segments instead of 7", len(parts))
+		errSet.Set("acl_operation", operations[0])
 	}
+	errSet.Set("acl_permission_type", a.ACL.PermissionType)
+	errSet.Set("resource_type", a.Resource.Type)
+	errSet.Set("resource_name", a.Resource.Name)
+	errSet.Set("resource_pattern_type_filter", a.Resource.PatternTypeFilter)
+	if errSet.err != nil {
+		return nil, errSet.err
+	}
+	// either form is imported with the same ID as the resource would have
+	// if it had been created
+	d.SetId(aclID(d))
 
 	return []*schema.ResourceData{d}, nil
 }
 
+// parseACLImportID returns the binding and operations of an import ID,
+// either acl_principal|acl_host|acl_operation|acl_permission_type|resource_type|resource_name|resource_pattern_type_filter
+// or resource_type#resource_name#resource_pattern_type_filter#acl_principal#acl_host#acl_operation#acl_permission_type.
+// Each of the components is checked and spelt as the resource expects it,
+// e.g. LITERAL as Literal.
+func parseACLImportID(importID string) (StringlyTypedACL, []string, error) {
+	var a StringlyTypedACL
+	var operations string
+	if strings.Contains(importID, "|") {
+		parts := strings.Split(importID, "|")
+		if len(parts) != 7 {
+			return a, nil, fmt.Errorf("expected format is acl_principal|acl_host|acl_operation|acl_permission_type|resource_type|resource_name|resource_pattern_type_filter - got %v segments instead of 7", len(parts))
+		}
+		a.ACL = ACL{Principal: parts[0], Host: parts[1], PermissionType: parts[3]}
+		a.Resource = Resource{Type: parts[4], Name: parts[5], PatternTypeFilter: parts[6]}
+		operations = parts[2]
+	} else {
+		parts := strings.Split(importID, "#")
+		if len(parts) != 7 {
+			return a, nil, fmt.Errorf("expected format is resource_type#resource_name#resource_pattern_type_filter#acl_principal#acl_host#acl_operation#acl_permission_type, e.g. Topic#orders#LITERAL#User:svc#*#Read#Allow, or the resource's ID - got %v segments instead of 7", len(parts))
+		}
+		a.Resource = Resource{Type: parts[0], Name: parts[1], PatternTypeFilter: parts[2]}
+		a.ACL = ACL{Principal: parts[3], Host: parts[4], PermissionType: parts[6]}
+		operations = parts[5]
+	}
+
+	var err error
+	if a.Resource.Type, err = canonicalACLValue("resource_type", a.Resource.Type, aclResourceTypes); err != nil {
+		return a, nil, err
+	}
+	if a.Resource.Name == "" {
+		return a, nil, fmt.Errorf("resource_name must not be empty")
+	}
+	if a.Resource.PatternTypeFilter, err = canonicalACLValue("resource_pattern_type_filter", a.Resource.PatternTypeFilter, []string{"Literal", "Prefixed"}); err != nil {
+		return a, nil, err
+	}
+	if err := validateACLResource(a.Resource.Type, a.Resource.Name, a.Resource.PatternTypeFilter); err != nil {
+		return a, nil, err
+	}
+
+	a.ACL.Principal = normalizeACLPrincipal(a.ACL.Principal)
+	if _, errs := validateACLPrincipal(a.ACL.Principal, "acl_principal"); len(errs) > 0 {
+		return a, nil, errs[0]
+	}
+	a.ACL.Host = normalizeACLHost(a.ACL.Host)
+	if a.ACL.Host == "" {
+		return a, nil, fmt.Errorf("acl_host must not be empty, use * for every host")
+	}
+	if a.ACL.PermissionType, err = canonicalACLValue("acl_permission_type", a.ACL.PermissionType, []string{"Allow", "Deny"}); err != nil {
+		return a, nil, err
+	}
+
+	ops := strings.Split(operations, ",")
+	for i, op := range ops {
+		if ops[i], err = canonicalACLValue("acl_operation", op, aclBindingOperations); err != nil {
+			return a, nil, err
+		}
+	}
+	sort.Strings(ops)
+	a.ACL.Operation = ops[0]
+
+	return a, ops, nil
+}
+
+// canonicalACLValue returns the allowed value matching v regardless of case
+func canonicalACLValue(key, v string, allowed []string) (string, error) {
+	v = strings.TrimSpace(v)
+	for _, a := range allowed {
+		if strings.EqualFold(v, a) {
+			return a, nil
+		}
+	}
+	return "", fmt.Errorf("%s must be one of %s, got %q", key, strings.Join(allowed, ", "), v)
+}
+
 type errSetter struct {
 	err error
 	d   *schema.ResourceData
@@ -213,143 +442,184 @@ func aclInfo(d *schema.ResourceData) StringlyTypedACL {
 	return s
 }
 
-// waitForACLToBeVisible waits for an ACL to be visible in Kafka after creation
-// This handles eventual consistency issues with Kafka ACL propagation
-func waitForACLToBeVisible(ctx context.Context, c *LazyClient, expectedACL StringlyTypedACL) error {
-	maxRetries := 10
-	retryInterval := 200 * time.Millisecond
+// aclsInfo returns an ACL for each of the operations of d
+func aclsInfo(d *schema.ResourceData) []StringlyTypedACL {
+	return expandACLOperations(aclInfo(d), d.Get("acl_operations").(*schema.Set))
+}
 
-	for i := 0; i < maxRetries; i++ {
-		// Check if context is cancelled
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		default:
-		}
+// oldACLsInfo returns the ACLs as they were before the pending changes to d
+func oldACLsInfo(d *schema.ResourceData) []StringlyTypedACL {
+	old := func(key string) string {
+		o, _ := d.GetChange(key)
+		return o.(string)
+	}
+	operations, _ := d.GetChange("acl_operations")
 
-		// Invalidate cache to ensure we get fresh data
-		err := c.InvalidateACLCache()
-		if err != nil {
-			return fmt.Errorf("failed to invalidate ACL cache: %w", err)
-		}
+	return expandACLOperations(StringlyTypedACL{
+		ACL: ACL{
+			Principal:      old("acl_principal"),
+			Host:           old("acl_host"),
+			Operation:      old("acl_operation"),
+			PermissionType: old("acl_permission_type"),
+		},
+		Resource: Resource{
+			Type:              old("resource_type"),
+			Name:              old("resource_name"),
+			PatternTypeFilter: old("resource_pattern_type_filter"),
+		},
+	}, operations.(*schema.Set))
+}
 
-		// List all ACLs
-		acls, err := c.ListACLs()
-		if err != nil {
-			return fmt.Errorf("failed to list ACLs: %w", err)
-		}
+// expandACLOperations returns a copy of a for each of the operations, sorted,
+// or a itself when there are none
+func expandACLOperations(a StringlyTypedACL, operations *schema.Set) []StringlyTypedACL {
+	if operations == nil || operations.Len() == 0 {
+		return []StringlyTypedACL{a}
+	}
 
-		// Check if our ACL exists
-		for _, foundACLs := range acls {
-			if foundACLs.ResourceName != expectedACL.Resource.Name {
-				continue
-			}
+	ops := make([]string, 0, operations.Len())
+	for _, op := range operations.List() {
+		ops = append(ops, op.(string))
+	}
+	sort.Strings(ops)
 
-			for _, acl := range foundACLs.Acls {
-				foundACL := StringlyTypedACL{
-					ACL: ACL{
-						Principal:      acl.Principal,
-						Host:           acl.Host,
-						Operation:      ACLOperationToString(acl.Operation),
-						PermissionType: ACLPermissionTypeToString(acl.PermissionType),
-					},
-					Resource: Resource{
-						Type:              ACLResourceToString(foundACLs.ResourceType),
-						Name:              foundACLs.ResourceName,
-						PatternTypeFilter: foundACLs.ResourcePatternType.String(),
-					},
-				}
-
-				// Check for exact match
-				if expectedACL.String() == foundACL.String() {
-					log.Printf("[INFO] ACL %s is now visible in Kafka (attempt %d)", expectedACL, i+1)
-					return nil
-				}
-			}
-		}
+	acls := make([]StringlyTypedACL, len(ops))
+	for i, op := range ops {
+		acls[i] = a
+		acls[i].ACL.Operation = op
+	}
+	return acls
+}
 
-		// If not found and not the last attempt, wait before retrying
-		if i < maxRetries-1 {
-			log.Printf("[DEBUG] ACL %s not yet visible, retrying in %v (attempt %d/%d)", expectedACL, retryInterval, i+1, maxRetries)
-			time.Sleep(retryInterval)
+// aclID is the ID of the resource: its ACL, with the operations joined by
+// commas when there are several
+func aclID(d *schema.ResourceData) string {
+	acls := aclsInfo(d)
+	ops := make([]string, len(acls))
+	for i, a := range acls {
+		ops[i] = a.ACL.Operation
+	}
+
+	id := acls[0]
+	id.ACL.Operation = strings.Join(ops, ",")
+	return id.String()
+}
+
+// aclChanges returns the ACLs to create and to delete to go from the old
+// ACLs to the new ones
+func aclChanges(old, new []StringlyTypedACL) (create, remove []StringlyTypedACL) {
+	oldIDs := map[string]bool{}
+	for _, a := range old {
+		oldIDs[a.String()] = true
+	}
+	newIDs := map[string]bool{}
+	for _, a := range new {
+		newIDs[a.String()] = true
+		if !oldIDs[a.String()] {
+			create = append(create, a)
+		}
+	}
+	for _, a := range old {
+		if !newIDs[a.String()] {
+			remove = append(remove, a)
 		}
 	}
+	return create, remove
+}
+
+// aclFromResourceAcls returns acl, one of the ACLs of res, as a
+// StringlyTypedACL
+func aclFromResourceAcls(res *sarama.ResourceAcls, acl *sarama.Acl) StringlyTypedACL {
+	return StringlyTypedACL{
+		ACL: ACL{
+			Principal:      acl.Principal,
+			Host:           acl.Host,
+			Operation:      ACLOperationToString(acl.Operation),
+			PermissionType: ACLPermissionTypeToString(acl.PermissionType),
+		},
+		Resource: Resource{
+			Type:              ACLResourceToString(res.ResourceType),
+			Name:              res.ResourceName,
+			PatternTypeFilter: res.ResourcePatternType.String(),
+		},
+	}
+}
+
+const (
+	aclPropagationRetryInterval = 200 * time.Millisecond
+	// aclPropagationTimeout is how long ACLs are waited for unless the
+	// resource's timeouts block says otherwise
+	aclPropagationTimeout = 10 * aclPropagationRetryInterval
+)
+
+// aclPropagationRetries returns how many times to check for an ACL within the
+// timeout
+func aclPropagationRetries(timeout time.Duration) int {
+	if retries := int(timeout / aclPropagationRetryInterval); retries > 1 {
+		return retries
+	}
+	return 1
+}
 
-	return fmt.Errorf("ACL %s was not visible in Kafka after %d attempts over %v", expectedACL, maxRetries, time.Duration(maxRetries)*retryInterval)
+// existingACLs returns the IDs of every ACL in Kafka
+func existingACLs(c *LazyClient) (map[string]bool, error) {
+	resources, err := c.ListACLs()
+	if err != nil {
+		return nil, err
+	}
+
+	existing := map[string]bool{}
+	for _, res := range resources {
+		for _, acl := range res.Acls {
+			existing[aclFromResourceAcls(res, acl).String()] = true
+		}
+	}
+	return existing, nil
 }
 
-// waitForACLToBeDeleted waits for an ACL to be removed from Kafka after deletion
-// This handles eventual consistency issues with Kafka ACL propagation
-func waitForACLToBeDeleted(ctx context.Context, c *LazyClient, deletedACL StringlyTypedACL) error {
-	maxRetries := 10
-	retryInterval := 200 * time.Millisecond
+// waitForACLs waits for all of the ACLs to be visible in Kafka, or to be
+// gone from it, checking them all in each listing rather than one by one
+func waitForACLs(ctx context.Context, c *LazyClient, acls []StringlyTypedACL, visible bool, timeout time.Duration) error {
+	maxRetries := aclPropagationRetries(timeout)
+	pending := acls
 
 	for i := 0; i < maxRetries; i++ {
-		// Check if context is cancelled
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
 		default:
 		}
 
-		// Invalidate cache to ensure we get fresh data
-		err := c.InvalidateACLCache()
-		if err != nil {
+		if err := c.InvalidateACLCache(); err != nil {
 			return fmt.Errorf("failed to invalidate ACL cache: %w", err)
 		}
-
-		// List all ACLs
-		acls, err := c.ListACLs()
+		existing, err := existingACLs(c)
 		if err != nil {
 			return fmt.Errorf("failed to list ACLs: %w", err)
 		}
 
-		// Check if our ACL still exists
-		found := false
-		for _, foundACLs := range acls {
-			if foundACLs.ResourceName != deletedACL.Resource.Name {
-				continue
-			}
-
-			for _, acl := range foundACLs.Acls {
-				foundACL := StringlyTypedACL{
-					ACL: ACL{
-						Principal:      acl.Principal,
-						Host:           acl.Host,
-						Operation:      ACLOperationToString(acl.Operation),
-						PermissionType: ACLPermissionTypeToString(acl.PermissionType),
-					},
-					Resource: Resource{
-						Type:              ACLResourceToString(foundACLs.ResourceType),
-						Name:              foundACLs.ResourceName,
-						PatternTypeFilter: foundACLs.ResourcePatternType.String(),
-					},
-				}
-
-				// Check for exact match
-				if deletedACL.String() == foundACL.String() {
-					found = true
-					break
-				}
-			}
-
-			if found {
-				break
+		remaining := pending[:0:0]
+		for _, a := range pending {
+			if existing[a.String()] != visible {
+				remaining = append(remaining, a)
 			}
 		}
-
-		// If not found, the ACL has been successfully deleted
-		if !found {
-			log.Printf("[INFO] ACL %s has been removed from Kafka (attempt %d)", deletedACL, i+1)
+		pending = remaining
+		if len(pending) == 0 {
 			return nil
 		}
 
-		// If still found and not the last attempt, wait before retrying
 		if i < maxRetries-1 {
-			log.Printf("[DEBUG] ACL %s still visible, retrying in %v (attempt %d/%d)", deletedACL, retryInterval, i+1, maxRetries)
-			time.Sleep(retryInterval)
+			log.Printf("[DEBUG] %d ACLs not yet propagated, retrying in %v (attempt %d/%d)", len(pending), aclPropagationRetryInterval, i+1, maxRetries)
+			if err := sleepContext(ctx, aclPropagationRetryInterval); err != nil {
+				return err
+			}
 		}
 	}
 
-	return fmt.Errorf("ACL %s was still visible in Kafka after %d attempts over %v", deletedACL, maxRetries, time.Duration(maxRetries)*retryInterval)
+	state := "visible in"
+	if !visible {
+		state = "removed from"
+	}
+	return fmt.Errorf("ACLs %v were not %s Kafka after %d attempts over %v", pending, state, maxRetries, time.Duration(maxRetries)*aclPropagationRetryInterval)
 }
@@ -0,0 +1,256 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/id"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// kafkaACLBatchResource manages many ACL bindings as one resource, e.g. the
+// dozens of ACLs of a service, creating and deleting them in single requests
+func kafkaACLBatchResource() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: aclBatchCreate,
+		ReadContext:   aclBatchRead,
+		UpdateContext: aclBatchUpdate,
+		DeleteContext: aclBatchDelete,
+		Timeouts:      resourceTimeouts(),
+		Schema: map[string]*schema.Schema{
+			"acl": {
+				Type:        schema.TypeSet,
+				Required:    true,
+				MinItems:    1,
+				Description: "The ACL bindings to manage. Adding or removing a binding only creates or deletes its ACL.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"resource_name": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The name of the resource.",
+						},
+						"resource_type": {
+							Type:             schema.TypeString,
+							Required:         true,
+							ValidateDiagFunc: validateDiagFunc(validation.StringInSlice(aclResourceTypes, false)),
+							Description:      "The type of the resource.",
+						},
+						"resource_pattern_type_filter": {
+							Type:             schema.TypeString,
+							Optional:         true,
+							Default:          "Literal",
+							ValidateDiagFunc: validateDiagFunc(validation.StringInSlice([]string{"Literal", "Prefixed"}, false)),
+							Description:      "Whether resource_name is the whole name or a prefix of the names.",
+						},
+						"acl_principal": {
+							Type:     schema.TypeString,
+							Required: true,
+							// the bindings are hashed as configured, so unlike
+							// kafka_acl the principal cannot be normalized and
+							// must be spelt as Kafka does
+							ValidateDiagFunc: validateDiagFunc(validateCanonicalACLPrincipal),
+							Description:      "The principal, e.g. User:alice, or User:* for every user.",
+						},
+						"acl_host": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Default:     "*",
+							Description: "The host the principal connects from, or * for every host.",
+						},
+						"acl_operation": {
+							Type:             schema.TypeString,
+							Required:         true,
+							ValidateDiagFunc: validateDiagFunc(validation.StringInSlice(aclBindingOperations, false)),
+							Description:      "The operation that is being allowed or denied.",
+						},
+						"acl_permission_type": {
+							Type:             schema.TypeString,
+							Optional:         true,
+							Default:          "Allow",
+							ValidateDiagFunc: validateDiagFunc(validation.StringInSlice([]string{"Allow", "Deny"}, false)),
+							Description:      "Whether the ACL allows or denies the operation.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// aclBindingOperations are the operations ACLs can be created for
+var aclBindingOperations = []string{"All", "Read", "Write", "Create", "Delete", "Alter", "Describe", "ClusterAction", "DescribeConfigs", "AlterConfigs", "IdempotentWrite"}
+
+// validateCanonicalACLPrincipal is validateACLPrincipal, rejecting principals
+// that only match once normalized, e.g. user:alice
+func validateCanonicalACLPrincipal(v interface{}, key string) ([]string, []error) {
+	warnings, errs := validateACLPrincipal(v, key)
+	if len(errs) > 0 {
+		return warnings, errs
+	}
+	if normalized := normalizeACLPrincipal(v.(string)); normalized != v.(string) {
+		return warnings, []error{fmt.Errorf("%s must be spelt as Kafka does, %q rather than %q", key, normalized, v)}
+	}
+	return warnings, nil
+}
+
+func aclBatchCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*LazyClient)
+	acls, err := expandACLBatch(d.Get("acl").(*schema.Set))
+	if err != nil {
+		return diagFromErr(err)
+	}
+
+	log.Printf("[INFO] Creating %d ACLs", len(acls))
+	if err := c.CreateACLs(ctx, acls); err != nil {
+		return diagFromErr(err)
+	}
+	d.SetId(id.UniqueId())
+
+	timeout := operationTimeout(d, schema.TimeoutCreate, aclPropagationTimeout)
+	if err := waitForACLs(ctx, c, acls, true, timeout); err != nil {
+		return diagFromErr(err)
+	}
+
+	return aclBatchRead(ctx, d, meta)
+}
+
+func aclBatchRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*LazyClient)
+	expected, err := expandACLBatch(d.Get("acl").(*schema.Set))
+	if err != nil {
+		return diagFromErr(err)
+	}
+
+	existing, err := existingACLs(c)
+	if err != nil {
+		return diagFromErr(err)
+	}
+
+	// only the managed bindings are read back, as other ACLs of the same
+	// resources may be managed elsewhere
+	found := make([]StringlyTypedACL, 0, len(expected))
+	for _, a := range expected {
+		if existing[a.String()] {
+			found = append(found, a)
+		} else {
+			log.Printf("[INFO] Did not find ACL %s", a)
+		}
+	}
+
+	if len(found) == 0 {
+		log.Printf("[WARN] None of the ACLs of %s exist anymore", d.Id())
+		d.SetId("")
+		return nil
+	}
+
+	if err := d.Set("acl", flattenACLBatch(found)); err != nil {
+		return diagFromErr(err)
+	}
+	return nil
+}
+
+// aclBatchUpdate creates the added bindings before deleting the removed
+// ones, so clients never lose authorization in between
+func aclBatchUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*LazyClient)
+	o, n := d.GetChange("acl")
+	old, err := expandACLBatch(o.(*schema.Set))
+	if err != nil {
+		return diagFromErr(err)
+	}
+	new, err := expandACLBatch(n.(*schema.Set))
+	if err != nil {
+		return diagFromErr(err)
+	}
+	create, remove := aclChanges(old, new)
+	timeout := operationTimeout(d, schema.TimeoutUpdate, aclPropagationTimeout)
+
+	log.Printf("[INFO] Updating ACLs of %s: creating %v, deleting %v", d.Id(), create, remove)
+	if len(create) > 0 {
+		if err := c.CreateACLs(ctx, create); err != nil {
+			return diagFromErr(err)
+		}
+		if err := waitForACLs(ctx, c, create, true, timeout); err != nil {
+			return diagFromErr(err)
+		}
+	}
+
+	if len(remove) > 0 {
+		if err := c.DeleteACLs(ctx, remove); err != nil {
+			return diagFromErr(err)
+		}
+		if err := waitForACLs(ctx, c, remove, false, timeout); err != nil {
+			return diagFromErr(err)
+		}
+	}
+
+	return aclBatchRead(ctx, d, meta)
+}
+
+func aclBatchDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*LazyClient)
+	acls, err := expandACLBatch(d.Get("acl").(*schema.Set))
+	if err != nil {
+		return diagFromErr(err)
+	}
+
+	log.Printf("[INFO] Deleting %d ACLs", len(acls))
+	if err := c.DeleteACLs(ctx, acls); err != nil {
+		return diagFromErr(err)
+	}
+
+	timeout := operationTimeout(d, schema.TimeoutDelete, aclPropagationTimeout)
+	if err := waitForACLs(ctx, c, acls, false, timeout); err != nil {
+		return diagFromErr(err)
+	}
+	return nil
+}
+
+// expandACLBatch returns the ACLs of the bindings, sorted, rejecting the
+// resources Kafka refuses ACLs for
+func expandACLBatch(bindings *schema.Set) ([]StringlyTypedACL, error) {
+	acls := make([]StringlyTypedACL, 0, bindings.Len())
+	for _, b := range bindings.List() {
+		binding := b.(map[string]interface{})
+		a := StringlyTypedACL{
+			ACL: ACL{
+				Principal:      binding["acl_principal"].(string),
+				Host:           binding["acl_host"].(string),
+				Operation:      binding["acl_operation"].(string),
+				PermissionType: binding["acl_permission_type"].(string),
+			},
+			Resource: Resource{
+				Type:              binding["resource_type"].(string),
+				Name:              binding["resource_name"].(string),
+				PatternTypeFilter: binding["resource_pattern_type_filter"].(string),
+			},
+		}
+		if err := validateACLResource(a.Resource.Type, a.Resource.Name, a.Resource.PatternTypeFilter); err != nil {
+			return nil, err
+		}
+		acls = append(acls, a)
+	}
+	sort.Slice(acls, func(i, j int) bool { return acls[i].String() < acls[j].String() })
+	return acls, nil
+}
+
+func flattenACLBatch(acls []StringlyTypedACL) []interface{} {
+	res := make([]interface{}, 0, len(acls))
+	for _, a := range acls {
+		res = append(res, map[string]interface{}{
+			"resource_name":                a.Resource.Name,
+			"resource_type":                a.Resource.Type,
+			"resource_pattern_type_filter": a.Resource.PatternTypeFilter,
+			"acl_principal":                a.ACL.Principal,
+			"acl_host":                     a.ACL.Host,
+			"acl_operation":                a.ACL.Operation,
+			"acl_permission_type":          a.ACL.PermissionType,
+		})
+	}
+	return res
+}
@@ -0,0 +1,165 @@
+package kafka
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	uuid "github.com/hashicorp/go-uuid"
+	r "github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAcc_ACLBatch(t *testing.T) {
+	t.Parallel()
+	u, err := uuid.GenerateUUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	aclResourceName := fmt.Sprintf("syslog-%s", u)
+	bs := testBootstrapServers[0]
+
+	r.Test(t, r.TestCase{
+		ProviderFactories: overrideProviderFactory(),
+		PreCheck:          func() { testAccPreCheck(t) },
+		CheckDestroy:      func(s *terraform.State) error { return testAccCheckAclDestroy(aclResourceName) },
+		Steps: []r.TestStep{
+			{
+				Config: cfg(t, bs, fmt.Sprintf(testResourceACLBatch_initialConfig, aclResourceName)),
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckResourceAttr("kafka_acl_batch.test", "acl.#", "2"),
+					testAccCheckACLBatch(aclResourceName, "User:Alice|*|Read|Allow", "User:Alice|*|Describe|Allow"),
+				),
+			},
+			{
+				// Describe is kept, Read deleted and Write created
+				Config: cfg(t, bs, fmt.Sprintf(testResourceACLBatch_updatedConfig, aclResourceName)),
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckResourceAttr("kafka_acl_batch.test", "acl.#", "2"),
+					testAccCheckACLBatch(aclResourceName, "User:Alice|*|Describe|Allow", "User:Alice|*|Write|Allow"),
+				),
+			},
+		},
+	})
+}
+
+// testAccCheckACLBatch checks that the ACLs of the topic are exactly the
+// given principal|host|operation|permission_type bindings
+func testAccCheckACLBatch(topic string, bindings ...string) r.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testProvider.Meta().(*LazyClient)
+		if err := client.InvalidateACLCache(); err != nil {
+			return err
+		}
+		existing, err := existingACLs(client)
+		if err != nil {
+			return err
+		}
+
+		suffix := fmt.Sprintf("|Topic|%s|Literal", topic)
+		found := 0
+		for id := range existing {
+			if strings.HasSuffix(id, suffix) {
+				found++
+			}
+		}
+		for _, b := range bindings {
+			if !existing[b+suffix] {
+				return fmt.Errorf("expected ACL %s%s to exist", b, suffix)
+			}
+		}
+		if found != len(bindings) {
+			return fmt.Errorf("expected %d ACLs for topic %s, got %d", len(bindings), topic, found)
+		}
+		return nil
+	}
+}
+
+func Test_expandACLBatch(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, kafkaACLBatchResource().Schema, map[string]interface{}{
+		"acl": []interface{}{
+			map[string]interface{}{
+				"resource_name": "syslog",
+				"resource_type": "Topic",
+				"acl_principal": "User:Alice",
+				"acl_operation": "Write",
+			},
+			map[string]interface{}{
+				"resource_name":                "app-",
+				"resource_type":                "Group",
+				"resource_pattern_type_filter": "Prefixed",
+				"acl_principal":                "User:Alice",
+				"acl_host":                     "10.0.0.1",
+				"acl_operation":                "Read",
+				"acl_permission_type":          "Deny",
+			},
+		},
+	})
+
+	acls, err := expandACLBatch(d.Get("acl").(*schema.Set))
+	assertNil(t, err)
+	assertEquals(t, 2, len(acls))
+	assertEquals(t, "User:Alice|*|Write|Allow|Topic|syslog|Literal", acls[0].String())
+	assertEquals(t, "User:Alice|10.0.0.1|Read|Deny|Group|app-|Prefixed", acls[1].String())
+
+	d = schema.TestResourceDataRaw(t, kafkaACLBatchResource().Schema, map[string]interface{}{
+		"acl": []interface{}{
+			map[string]interface{}{
+				"resource_name":                "kafka-cluster",
+				"resource_type":                "Cluster",
+				"resource_pattern_type_filter": "Prefixed",
+				"acl_principal":                "User:Alice",
+				"acl_operation":                "Alter",
+			},
+		},
+	})
+	if _, err := expandACLBatch(d.Get("acl").(*schema.Set)); err == nil {
+		t.Error("expected an error for a prefixed Cluster ACL")
+	}
+}
+
+func Test_validateCanonicalACLPrincipal(t *testing.T) {
+	_, errs := validateCanonicalACLPrincipal("User:alice", "acl_principal")
+	assertEquals(t, 0, len(errs))
+	_, errs = validateCanonicalACLPrincipal("user:alice", "acl_principal")
+	assertEquals(t, 1, len(errs))
+	_, errs = validateCanonicalACLPrincipal("alice", "acl_principal")
+	assertEquals(t, 1, len(errs))
+}
+
+const testResourceACLBatch_initialConfig = `
+resource "kafka_acl_batch" "test" {
+  acl {
+    resource_name = "%[1]s"
+    resource_type = "Topic"
+    acl_principal = "User:Alice"
+    acl_operation = "Read"
+  }
+
+  acl {
+    resource_name = "%[1]s"
+    resource_type = "Topic"
+    acl_principal = "User:Alice"
+    acl_operation = "Describe"
+  }
+}
+`
+
+const testResourceACLBatch_updatedConfig = `
+resource "kafka_acl_batch" "test" {
+  acl {
+    resource_name = "%[1]s"
+    resource_type = "Topic"
+    acl_principal = "User:Alice"
+    acl_operation = "Describe"
+  }
+
+  acl {
+    resource_name = "%[1]s"
+    resource_type = "Topic"
+    acl_principal = "User:Alice"
+    acl_operation = "Write"
+  }
+}
+`
@@ -1,9 +1,13 @@
 package kafka
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
+	"reflect"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 
@@ -49,7 +53,7 @@ func TestAcc_ACLCreateAndUpdate(t *testing.T) {
 							PatternTypeFilter: "Literal",
 						},
 					}
-					err := client.DeleteACL(acl)
+					err := client.DeleteACL(context.Background(), acl)
 					// wait for the ACL queue to drain
 					time.Sleep(time.Second)
 					if err != nil {
@@ -96,10 +100,329 @@ func TestAcc_ACLDeletedOutsideOfTerraform(t *testing.T) {
 				ImportStateVerify: true,
 				Config:            cfg(t, bs, fmt.Sprintf(testResourceACL_updateConfig, aclResourceName)),
 			},
+			{
+				ResourceName:      "kafka_acl.test",
+				ImportState:       true,
+				ImportStateId:     fmt.Sprintf("Topic#%s#PREFIXED#User:Alice#*#Write#Deny", aclResourceName),
+				ImportStateVerify: true,
+				Config:            cfg(t, bs, fmt.Sprintf(testResourceACL_updateConfig, aclResourceName)),
+			},
+		},
+	})
+}
+
+func TestAcc_ACLUpdateInPlace(t *testing.T) {
+	t.Parallel()
+	u, err := uuid.GenerateUUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	aclResourceName := fmt.Sprintf("syslog-%s", u)
+	bs := testBootstrapServers[0]
+
+	r.Test(t, r.TestCase{
+		ProviderFactories: overrideProviderFactory(),
+		PreCheck:          func() { testAccPreCheck(t) },
+		CheckDestroy:      func(s *terraform.State) error { return testAccCheckAclDestroy(aclResourceName) },
+		Steps: []r.TestStep{
+			{
+				Config: cfg(t, bs, fmt.Sprintf(testResourceACL_initialConfig, aclResourceName)),
+				Check:  testResourceACL_initialCheck,
+			},
+			{
+				Config: cfg(t, bs, fmt.Sprintf(testResourceACL_updateInPlaceConfig, aclResourceName)),
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckResourceAttr("kafka_acl.test", "id", fmt.Sprintf("User:Alice|*|Read|Allow|Topic|%s|Literal", aclResourceName)),
+					testResourceACL_updateInPlaceCheck,
+				),
+			},
+		},
+	})
+}
+
+func TestAcc_ACLResourceTypes(t *testing.T) {
+	t.Parallel()
+	u, err := uuid.GenerateUUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	aclResourceName := fmt.Sprintf("app-%s", u)
+	bs := testBootstrapServers[0]
+
+	r.Test(t, r.TestCase{
+		ProviderFactories: overrideProviderFactory(),
+		PreCheck:          func() { testAccPreCheck(t) },
+		CheckDestroy:      func(s *terraform.State) error { return testAccCheckAclDestroy(aclResourceName) },
+		Steps: []r.TestStep{
+			{
+				Config: cfg(t, bs, fmt.Sprintf(testResourceACL_resourceTypesConfig, aclResourceName)),
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckResourceAttr("kafka_acl.group", "id", fmt.Sprintf("User:Alice|*|Read|Allow|Group|%s|Prefixed", aclResourceName)),
+					r.TestCheckResourceAttr("kafka_acl.transactional_id", "id", fmt.Sprintf("User:Alice|*|Write|Allow|TransactionalID|%s|Prefixed", aclResourceName)),
+					r.TestCheckResourceAttr("kafka_acl.delegation_token", "id", fmt.Sprintf("User:Alice|*|Describe|Allow|DelegationToken|%s|Literal", aclResourceName)),
+				),
+			},
+		},
+	})
+}
+
+func TestAcc_ACLOperations(t *testing.T) {
+	t.Parallel()
+	u, err := uuid.GenerateUUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	aclResourceName := fmt.Sprintf("syslog-%s", u)
+	bs := testBootstrapServers[0]
+
+	r.Test(t, r.TestCase{
+		ProviderFactories: overrideProviderFactory(),
+		PreCheck:          func() { testAccPreCheck(t) },
+		CheckDestroy:      func(s *terraform.State) error { return testAccCheckAclDestroy(aclResourceName) },
+		Steps: []r.TestStep{
+			{
+				Config: cfg(t, bs, fmt.Sprintf(testResourceACL_operationsConfig, aclResourceName, `"Read", "Describe", "Write"`)),
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckResourceAttr("kafka_acl.test", "id", fmt.Sprintf("User:Alice|*|Describe,Read,Write|Allow|Topic|%s|Literal", aclResourceName)),
+					r.TestCheckResourceAttr("kafka_acl.test", "acl_operations.#", "3"),
+					testAccCheckACLOperations(aclResourceName, sarama.AclOperationDescribe, sarama.AclOperationRead, sarama.AclOperationWrite),
+				),
+			},
+			{
+				Config: cfg(t, bs, fmt.Sprintf(testResourceACL_operationsConfig, aclResourceName, `"Read", "Describe"`)),
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckResourceAttr("kafka_acl.test", "id", fmt.Sprintf("User:Alice|*|Describe,Read|Allow|Topic|%s|Literal", aclResourceName)),
+					testAccCheckACLOperations(aclResourceName, sarama.AclOperationDescribe, sarama.AclOperationRead),
+				),
+			},
+			{
+				ResourceName:      "kafka_acl.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAcc_ACLAllowAndDeny(t *testing.T) {
+	t.Parallel()
+	u, err := uuid.GenerateUUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	aclResourceName := fmt.Sprintf("syslog-%s", u)
+	bs := testBootstrapServers[0]
+
+	r.Test(t, r.TestCase{
+		ProviderFactories: overrideProviderFactory(),
+		PreCheck:          func() { testAccPreCheck(t) },
+		CheckDestroy:      func(s *terraform.State) error { return testAccCheckAclDestroy(aclResourceName) },
+		Steps: []r.TestStep{
+			{
+				Config: cfg(t, bs, fmt.Sprintf(testResourceACL_allowAndDenyConfig, aclResourceName)),
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckResourceAttr("kafka_acl.allow", "id", fmt.Sprintf("User:Alice|*|Read|Allow|Topic|%s|Literal", aclResourceName)),
+					r.TestCheckResourceAttr("kafka_acl.deny", "id", fmt.Sprintf("User:Alice|*|Read|Deny|Topic|%s|Literal", aclResourceName)),
+				),
+			},
+			{
+				// the Allow ACL left in Kafka is not taken for the deleted Deny one
+				Config: cfg(t, bs, fmt.Sprintf(testResourceACL_allowAndDenyConfig, aclResourceName)),
+				PreConfig: func() {
+					client := testProvider.Meta().(*LazyClient)
+					err := client.DeleteACL(context.Background(), StringlyTypedACL{
+						ACL:      ACL{Principal: "User:Alice", Host: "*", Operation: "Read", PermissionType: "Deny"},
+						Resource: Resource{Type: "Topic", Name: aclResourceName, PatternTypeFilter: "Literal"},
+					})
+					if err != nil {
+						t.Fatal(err)
+					}
+				},
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
 		},
 	})
 }
 
+func Test_aclChanges(t *testing.T) {
+	acl := func(op, permission string) StringlyTypedACL {
+		return StringlyTypedACL{
+			ACL:      ACL{Principal: "User:Alice", Host: "*", Operation: op, PermissionType: permission},
+			Resource: Resource{Type: "Topic", Name: "syslog", PatternTypeFilter: "Literal"},
+		}
+	}
+
+	// only the removed operation is deleted
+	create, remove := aclChanges(
+		[]StringlyTypedACL{acl("Describe", "Allow"), acl("Read", "Allow"), acl("Write", "Allow")},
+		[]StringlyTypedACL{acl("Describe", "Allow"), acl("Read", "Allow")},
+	)
+	assertEquals(t, 0, len(create))
+	if !reflect.DeepEqual([]StringlyTypedACL{acl("Write", "Allow")}, remove) {
+		t.Errorf("expected only the Write ACL to be deleted, got %v", remove)
+	}
+
+	// every ACL is replaced when what they share changes
+	create, remove = aclChanges(
+		[]StringlyTypedACL{acl("Describe", "Allow"), acl("Read", "Allow")},
+		[]StringlyTypedACL{acl("Describe", "Deny"), acl("Read", "Deny")},
+	)
+	if !reflect.DeepEqual([]StringlyTypedACL{acl("Describe", "Deny"), acl("Read", "Deny")}, create) {
+		t.Errorf("expected the Deny ACLs to be created, got %v", create)
+	}
+	if !reflect.DeepEqual([]StringlyTypedACL{acl("Describe", "Allow"), acl("Read", "Allow")}, remove) {
+		t.Errorf("expected the Allow ACLs to be deleted, got %v", remove)
+	}
+}
+
+func Test_validateACLResource(t *testing.T) {
+	for _, tc := range []struct {
+		resourceType, name, patternType string
+		err                             string
+	}{
+		{"Group", "app-", "Prefixed", ""},
+		{"TransactionalID", "app-", "Prefixed", ""},
+		{"DelegationToken", "token", "Literal", ""},
+		{"Cluster", "kafka-cluster", "Literal", ""},
+		{"Cluster", "kafka-cluster", "Prefixed", "not supported for resource_type Cluster"},
+		{"Cluster", "my-cluster", "Literal", `must be "kafka-cluster"`},
+	} {
+		err := validateACLResource(tc.resourceType, tc.name, tc.patternType)
+		if tc.err == "" {
+			assertNil(t, err)
+		} else if err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("expected an error containing %q for %v, got %v", tc.err, tc, err)
+		}
+	}
+}
+
+func Test_normalizeACLPrincipal(t *testing.T) {
+	for _, tc := range []struct {
+		principal, normalized string
+		valid                 bool
+	}{
+		{"User:alice", "User:alice", true},
+		{"user:Alice", "User:Alice", true},
+		{" USER:* ", "User:*", true},
+		{"group:admins", "Group:admins", true},
+		{"alice", "alice", false},
+		{"User:", "User:", false},
+		{"Service:alice", "Service:alice", false},
+	} {
+		assertEquals(t, tc.normalized, normalizeACLPrincipal(tc.principal))
+		_, errs := validateACLPrincipal(tc.principal, "acl_principal")
+		if got := len(errs) == 0; got != tc.valid {
+			t.Errorf("expected %q to be valid: %v, got %v", tc.principal, tc.valid, errs)
+		}
+	}
+
+	assertEquals(t, "*", normalizeACLHost(" * "))
+	assertEquals(t, "fe80::1", normalizeACLHost("FE80::1"))
+}
+
+func Test_parseACLImportID(t *testing.T) {
+	expected := StringlyTypedACL{
+		ACL:      ACL{Principal: "User:svc", Host: "*", Operation: "Read", PermissionType: "Allow"},
+		Resource: Resource{Type: "Topic", Name: "orders", PatternTypeFilter: "Literal"},
+	}
+	for _, id := range []string{
+		"Topic#orders#LITERAL#User:svc#*#Read#Allow",
+		"topic#orders#Literal#user:svc#*#READ#allow",
+		"User:svc|*|Read|Allow|Topic|orders|Literal",
+	} {
+		a, operations, err := parseACLImportID(id)
+		assertNil(t, err)
+		assertEquals(t, expected, a)
+		if !reflect.DeepEqual([]string{"Read"}, operations) {
+			t.Errorf("expected the Read operation for %q, got %v", id, operations)
+		}
+	}
+
+	_, operations, err := parseACLImportID("Group#app-#Prefixed#User:svc#*#read,Describe#Deny")
+	assertNil(t, err)
+	if !reflect.DeepEqual([]string{"Describe", "Read"}, operations) {
+		t.Errorf("expected the sorted operations, got %v", operations)
+	}
+
+	for _, tc := range []struct {
+		id, err string
+	}{
+		{"Topic#orders#LITERAL#User:svc#*#Read", "got 6 segments instead of 7"},
+		{"User:svc|*|Read|Allow|Topic|orders", "got 6 segments instead of 7"},
+		{"Queue#orders#LITERAL#User:svc#*#Read#Allow", "resource_type must be one of"},
+		{"Topic##LITERAL#User:svc#*#Read#Allow", "resource_name must not be empty"},
+		{"Topic#orders#MATCH#User:svc#*#Read#Allow", "resource_pattern_type_filter must be one of"},
+		{"Topic#orders#LITERAL#svc#*#Read#Allow", "acl_principal must be a principal type and name"},
+		{"Topic#orders#LITERAL#User:svc##Read#Allow", "acl_host must not be empty"},
+		{"Topic#orders#LITERAL#User:svc#*#Consume#Allow", "acl_operation must be one of"},
+		{"Topic#orders#LITERAL#User:svc#*#Read#Permit", "acl_permission_type must be one of"},
+		{"Cluster#my-cluster#LITERAL#User:svc#*#Alter#Allow", `must be "kafka-cluster"`},
+	} {
+		_, _, err := parseACLImportID(tc.id)
+		if err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("expected an error containing %q for %q, got %v", tc.err, tc.id, err)
+		}
+	}
+}
+
+func testResourceACL_updateInPlaceCheck(s *terraform.State) error {
+	client := testProvider.Meta().(*LazyClient)
+	err := client.InvalidateACLCache()
+	if err != nil {
+		return err
+	}
+	acls, err := client.ListACLs()
+	if err != nil {
+		return err
+	}
+
+	name := s.Modules[0].Resources["kafka_acl.test"].Primary.Attributes["resource_name"]
+	for _, searchACL := range acls {
+		if searchACL.ResourceName != name {
+			continue
+		}
+		if len(searchACL.Acls) != 1 {
+			return fmt.Errorf("there are %d ACLs when there should be 1: %v", len(searchACL.Acls), searchACL.Acls)
+		}
+		if searchACL.Acls[0].Operation != sarama.AclOperationRead {
+			return fmt.Errorf("should be Read, not %v", searchACL.Acls[0].Operation)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("no ACL found for resource %s", name)
+}
+
+func testAccCheckACLOperations(name string, expected ...sarama.AclOperation) r.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testProvider.Meta().(*LazyClient)
+		if err := client.InvalidateACLCache(); err != nil {
+			return err
+		}
+		acls, err := client.ListACLs()
+		if err != nil {
+			return err
+		}
+
+		operations := []sarama.AclOperation{}
+		for _, searchACL := range acls {
+			if searchACL.ResourceName != name {
+				continue
+			}
+			for _, acl := range searchACL.Acls {
+				operations = append(operations, acl.Operation)
+			}
+		}
+		sort.Slice(operations, func(i, j int) bool { return operations[i] < operations[j] })
+		sort.Slice(expected, func(i, j int) bool { return expected[i] < expected[j] })
+		if !reflect.DeepEqual(expected, operations) {
+			return fmt.Errorf("expected the ACLs of %s to have operations %v, got %v", name, expected, operations)
+		}
+		return nil
+	}
+}
+
 func testAccCheckAclDestroy(name string) error {
 	meta := testProvider.Meta()
 	if meta == nil {
@@ -276,6 +599,79 @@ resource "kafka_acl" "test" {
 }
 `
 
+const testResourceACL_updateInPlaceConfig = `
+resource "kafka_acl" "test" {
+	resource_name       = "%s"
+	resource_type       = "Topic"
+	resource_pattern_type_filter = "Literal"
+	acl_principal       = "User:Alice"
+	acl_host            = "*"
+	acl_operation       = "Read"
+	acl_permission_type = "Allow"
+}
+`
+
+const testResourceACL_operationsConfig = `
+resource "kafka_acl" "test" {
+	resource_name       = "%s"
+	resource_type       = "Topic"
+	acl_principal       = "User:Alice"
+	acl_host            = "*"
+	acl_operations      = [%s]
+	acl_permission_type = "Allow"
+}
+`
+
+const testResourceACL_allowAndDenyConfig = `
+resource "kafka_acl" "allow" {
+	resource_name  = "%[1]s"
+	resource_type  = "Topic"
+	acl_principal  = "User:Alice"
+	acl_host       = "*"
+	acl_operation  = "Read"
+}
+
+resource "kafka_acl" "deny" {
+	resource_name       = "%[1]s"
+	resource_type       = "Topic"
+	acl_principal       = "User:Alice"
+	acl_host            = "*"
+	acl_operation       = "Read"
+	acl_permission_type = "Deny"
+}
+`
+
+const testResourceACL_resourceTypesConfig = `
+resource "kafka_acl" "group" {
+	resource_name                = "%[1]s"
+	resource_type                = "Group"
+	resource_pattern_type_filter = "Prefixed"
+	acl_principal                = "User:Alice"
+	acl_host                     = "*"
+	acl_operation                = "Read"
+	acl_permission_type          = "Allow"
+}
+
+resource "kafka_acl" "transactional_id" {
+	resource_name                = "%[1]s"
+	resource_type                = "TransactionalID"
+	resource_pattern_type_filter = "Prefixed"
+	acl_principal                = "User:Alice"
+	acl_host                     = "*"
+	acl_operation                = "Write"
+	acl_permission_type          = "Allow"
+}
+
+resource "kafka_acl" "delegation_token" {
+	resource_name       = "%[1]s"
+	resource_type       = "DelegationToken"
+	acl_principal       = "User:Alice"
+	acl_host            = "*"
+	acl_operation       = "Describe"
+	acl_permission_type = "Allow"
+}
+`
+
 // lintignore:AT004
 func cfg(t *testing.T, bs string, extraCfg string) string {
 	_, err := os.ReadFile("../secrets/ca.crt")
@@ -0,0 +1,231 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/IBM/sarama"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const (
+	brokerConfigTypeBroker       = "broker"
+	brokerConfigTypeBrokerLogger = "broker_logger"
+)
+
+var brokerConfigResourceTypes = map[string]sarama.ConfigResourceType{
+	brokerConfigTypeBroker:       sarama.BrokerResource,
+	brokerConfigTypeBrokerLogger: sarama.BrokerLoggerResource,
+}
+
+func kafkaBrokerConfigResource() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: brokerConfigCreate,
+		ReadContext:   brokerConfigRead,
+		UpdateContext: brokerConfigUpdate,
+		DeleteContext: brokerConfigDelete,
+		CustomizeDiff: brokerConfigDiff,
+		Importer: &schema.ResourceImporter{
+			StateContext: importBrokerConfig,
+		},
+		Schema: map[string]*schema.Schema{
+			"broker_id": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				Default:      "",
+				ValidateFunc: validation.StringMatch(regexp.MustCompile(`^[0-9]*$`), "must be a broker ID"),
+				Description:  "The ID of the broker to manage the config of. Leave it out to manage the cluster-wide default of every broker.",
+			},
+			"resource_type": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				Default:      brokerConfigTypeBroker,
+				ValidateFunc: validation.StringInSlice([]string{brokerConfigTypeBroker, brokerConfigTypeBrokerLogger}, false),
+				Description:  "Either broker, for the broker's dynamic config, or broker_logger, for the log levels of its loggers, which requires broker_id.",
+			},
+			"config": {
+				Type:         schema.TypeMap,
+				Optional:     true,
+				AtLeastOneOf: []string{"config", "sensitive_config"},
+				Description:  "The dynamic config entries to set. Entries not listed are left as they are.",
+				Elem:         &schema.Schema{Type: schema.TypeString},
+				ValidateFunc: validateTopicConfigNotEmpty,
+			},
+			"sensitive_config": {
+				Type:         schema.TypeMap,
+				Optional:     true,
+				Sensitive:    true,
+				AtLeastOneOf: []string{"config", "sensitive_config"},
+				Description:  "Dynamic config entries whose values are secret, e.g. passwords. They are redacted from plans, and as Kafka never returns sensitive values, they are kept as configured rather than read back.",
+				Elem:         &schema.Schema{Type: schema.TypeString},
+				ValidateFunc: validateTopicConfigNotEmpty,
+			},
+		},
+	}
+}
+
+func brokerConfigCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*LazyClient)
+	resourceType, broker := brokerConfigFromResource(d)
+
+	set, _ := brokerConfigChanges(nil, d.Get("config").(map[string]interface{}), nil, d.Get("sensitive_config").(map[string]interface{}))
+	if err := c.AlterBrokerConfig(ctx, resourceType, broker, set, nil); err != nil {
+		return diagFromErr(err)
+	}
+
+	d.SetId(strings.Join([]string{d.Get("resource_type").(string), broker}, "|"))
+	return brokerConfigRead(ctx, d, meta)
+}
+
+func brokerConfigUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*LazyClient)
+	resourceType, broker := brokerConfigFromResource(d)
+
+	if d.HasChanges("config", "sensitive_config") {
+		o, n := d.GetChange("config")
+		so, sn := d.GetChange("sensitive_config")
+		set, remove := brokerConfigChanges(o.(map[string]interface{}), n.(map[string]interface{}), so.(map[string]interface{}), sn.(map[string]interface{}))
+		if err := c.AlterBrokerConfig(ctx, resourceType, broker, set, remove); err != nil {
+			return diagFromErr(err)
+		}
+	}
+
+	return brokerConfigRead(ctx, d, meta)
+}
+
+func brokerConfigRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*LazyClient)
+	resourceType, broker := brokerConfigFromResource(d)
+	log.Printf("[INFO] Reading config of %s", brokerConfigScope(resourceType, broker))
+
+	conf, err := c.BrokerConfig(resourceType, broker)
+	if err != nil {
+		return diagFromErr(err)
+	}
+
+	// only the managed entries are recorded, as for topic configs; Kafka
+	// never returns sensitive values, so those are kept as configured
+	managed := d.Get("config").(map[string]interface{})
+	managedSensitive := d.Get("sensitive_config").(map[string]interface{})
+	config := map[string]string{}
+	sensitive := map[string]string{}
+	for key, value := range conf {
+		if m, ok := managedSensitive[key]; ok {
+			if value != nil {
+				sensitive[key] = *value
+			} else {
+				sensitive[key] = m.(string)
+			}
+			continue
+		}
+		m, ok := managed[key]
+		if !ok && (len(managed) > 0 || len(managedSensitive) > 0) {
+			continue
+		}
+		if value != nil {
+			config[key] = *value
+		} else if ok {
+			config[key] = m.(string)
+		}
+	}
+
+	errSet := errSetter{d: d}
+	errSet.Set("config", config)
+	errSet.Set("sensitive_config", sensitive)
+	if errSet.err != nil {
+		return diagFromErr(errSet.err)
+	}
+
+	return nil
+}
+
+func brokerConfigDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*LazyClient)
+	resourceType, broker := brokerConfigFromResource(d)
+
+	// the managed entries revert to the cluster-wide default or the static
+	// config of the brokers
+	_, remove := brokerConfigChanges(d.Get("config").(map[string]interface{}), nil, d.Get("sensitive_config").(map[string]interface{}), nil)
+	if err := c.AlterBrokerConfig(ctx, resourceType, broker, nil, remove); err != nil {
+		return diagFromErr(err)
+	}
+
+	d.SetId("")
+	return nil
+}
+
+// brokerConfigChanges returns the entries to set and the sorted keys to
+// remove to go from the old to the new config and sensitive_config. Every
+// sensitive entry is set again, as without incremental alters the whole
+// config is replaced and Kafka never returns sensitive values to send back.
+func brokerConfigChanges(oldConfig, newConfig, oldSensitive, newSensitive map[string]interface{}) (map[string]*string, []string) {
+	set, removeConfig := topicConfigChanges(oldConfig, newConfig)
+	setSensitive, removeSensitive := topicConfigChanges(nil, newSensitive)
+	for key, value := range setSensitive {
+		set[key] = value
+	}
+	for key := range oldSensitive {
+		if _, ok := newSensitive[key]; !ok {
+			removeSensitive = append(removeSensitive, key)
+		}
+	}
+
+	// an entry moved between config and sensitive_config is set, not removed
+	remove := []string{}
+	for _, key := range append(removeConfig, removeSensitive...) {
+		if _, ok := set[key]; !ok {
+			remove = append(remove, key)
+		}
+	}
+	sort.Strings(remove)
+
+	return set, remove
+}
+
+func brokerConfigFromResource(d *schema.ResourceData) (sarama.ConfigResourceType, string) {
+	return brokerConfigResourceTypes[d.Get("resource_type").(string)], d.Get("broker_id").(string)
+}
+
+func brokerConfigDiff(ctx context.Context, diff *schema.ResourceDiff, v interface{}) error {
+	config := diff.Get("config").(map[string]interface{})
+	for key := range diff.Get("sensitive_config").(map[string]interface{}) {
+		if _, ok := config[key]; ok {
+			return fmt.Errorf("%s cannot be set in both config and sensitive_config", key)
+		}
+	}
+
+	if !diff.NewValueKnown("resource_type") || !diff.NewValueKnown("broker_id") {
+		return nil
+	}
+	if diff.Get("resource_type").(string) == brokerConfigTypeBrokerLogger && diff.Get("broker_id").(string) == "" {
+		return fmt.Errorf("broker_id must be set for resource_type %s, as loggers have no cluster-wide default", brokerConfigTypeBrokerLogger)
+	}
+	return nil
+}
+
+func importBrokerConfig(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	parts := strings.Split(d.Id(), "|")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("failed importing resource; expected format is resource_type|broker_id - got %v segments instead of 2", len(parts))
+	}
+	if _, ok := brokerConfigResourceTypes[parts[0]]; !ok {
+		return nil, fmt.Errorf("failed importing resource; resource_type must be %s or %s, got %q", brokerConfigTypeBroker, brokerConfigTypeBrokerLogger, parts[0])
+	}
+
+	errSet := errSetter{d: d}
+	errSet.Set("resource_type", parts[0])
+	errSet.Set("broker_id", parts[1])
+	if errSet.err != nil {
+		return nil, errSet.err
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
@@ -0,0 +1,162 @@
+package kafka
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/IBM/sarama"
+	r "github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func Test_brokerConfigChanges(t *testing.T) {
+	oldConfig := map[string]interface{}{"log.cleaner.backoff.ms": "20000", "ssl.keystore.location": "/etc/kafka/keystore.jks"}
+	newConfig := map[string]interface{}{"log.cleaner.backoff.ms": "25000"}
+	oldSensitive := map[string]interface{}{"ssl.key.password": "secret", "ssl.keystore.password": "secret"}
+	newSensitive := map[string]interface{}{"ssl.key.password": "secret", "ssl.keystore.location": "/etc/kafka/keystore.jks"}
+
+	set, remove := brokerConfigChanges(oldConfig, newConfig, oldSensitive, newSensitive)
+	expected := map[string]string{
+		"log.cleaner.backoff.ms": "25000",
+		"ssl.key.password":       "secret",
+		"ssl.keystore.location":  "/etc/kafka/keystore.jks",
+	}
+	if !reflect.DeepEqual(expected, strPtrMapToStrMap(set)) {
+		t.Errorf("unexpected entries to set %v", strPtrMapToStrMap(set))
+	}
+	if !reflect.DeepEqual([]string{"ssl.keystore.password"}, remove) {
+		t.Errorf("unexpected entries to remove %v", remove)
+	}
+
+	set, remove = brokerConfigChanges(oldConfig, nil, oldSensitive, nil)
+	if len(set) != 0 || !reflect.DeepEqual([]string{"log.cleaner.backoff.ms", "ssl.key.password", "ssl.keystore.location", "ssl.keystore.password"}, remove) {
+		t.Errorf("expected every entry to be removed, got %v and %v", strPtrMapToStrMap(set), remove)
+	}
+}
+
+func TestAcc_BrokerConfigClusterDefault(t *testing.T) {
+	bs := testBootstrapServers[0]
+
+	r.Test(t, r.TestCase{
+		ProviderFactories: overrideProviderFactory(),
+		PreCheck:          func() { testAccPreCheck(t) },
+		CheckDestroy:      func(s *terraform.State) error { return testAccCheckBrokerConfig("", map[string]string{})(s) },
+		Steps: []r.TestStep{
+			{
+				Config: cfg(t, bs, fmt.Sprintf(testResourceBrokerConfig, "", `"log.cleaner.backoff.ms" = "20000"`)),
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckResourceAttr("kafka_broker_config.test", "id", "broker|"),
+					r.TestCheckResourceAttr("kafka_broker_config.test", "config.log.cleaner.backoff.ms", "20000"),
+					testAccCheckBrokerConfig("", map[string]string{"log.cleaner.backoff.ms": "20000"}),
+				),
+			},
+			{
+				Config: cfg(t, bs, fmt.Sprintf(testResourceBrokerConfig, "", `"log.cleaner.backoff.ms" = "25000"`)),
+				Check:  testAccCheckBrokerConfig("", map[string]string{"log.cleaner.backoff.ms": "25000"}),
+			},
+			{
+				ResourceName:      "kafka_broker_config.test",
+				ImportState:       true,
+				ImportStateId:     "broker|",
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAcc_BrokerConfigBroker(t *testing.T) {
+	bs := testBootstrapServers[0]
+
+	r.Test(t, r.TestCase{
+		ProviderFactories: overrideProviderFactory(),
+		PreCheck:          func() { testAccPreCheck(t) },
+		CheckDestroy:      func(s *terraform.State) error { return testAccCheckBrokerConfig("1", map[string]string{})(s) },
+		Steps: []r.TestStep{
+			{
+				Config: cfg(t, bs, fmt.Sprintf(testResourceBrokerConfig, `broker_id = "1"`, `"log.cleaner.backoff.ms" = "20000"`)),
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckResourceAttr("kafka_broker_config.test", "id", "broker|1"),
+					testAccCheckBrokerConfig("1", map[string]string{"log.cleaner.backoff.ms": "20000"}),
+				),
+			},
+		},
+	})
+}
+
+func TestAcc_BrokerConfigLoggers(t *testing.T) {
+	bs := testBootstrapServers[0]
+
+	r.Test(t, r.TestCase{
+		ProviderFactories: overrideProviderFactory(),
+		PreCheck:          func() { testAccPreCheck(t) },
+		CheckDestroy:      testAccCheckBrokerLoggerReverted("1", "kafka.controller"),
+		Steps: []r.TestStep{
+			{
+				Config: cfg(t, bs, testResourceBrokerConfig_loggers),
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckResourceAttr("kafka_broker_config.test", "id", "broker_logger|1"),
+					r.TestCheckResourceAttr("kafka_broker_config.test", "config.kafka.controller", "DEBUG"),
+					r.TestCheckResourceAttr("data.kafka_broker_loggers.test", "loggers.kafka.controller", "DEBUG"),
+				),
+			},
+		},
+	})
+}
+
+// testAccCheckBrokerLoggerReverted checks that the logger's level was reset,
+// to that of the root logger, which the test brokers don't set to DEBUG
+func testAccCheckBrokerLoggerReverted(broker, logger string) r.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testProvider.Meta().(*LazyClient)
+		conf, err := client.BrokerConfig(sarama.BrokerLoggerResource, broker)
+		if err != nil {
+			return err
+		}
+		if level := conf[logger]; level != nil && *level == "DEBUG" {
+			return fmt.Errorf("expected the level of %s on broker %s to be reverted, got %s", logger, broker, *level)
+		}
+		return nil
+	}
+}
+
+func testAccCheckBrokerConfig(broker string, expected map[string]string) r.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testProvider.Meta().(*LazyClient)
+		conf, err := client.BrokerConfig(sarama.BrokerResource, broker)
+		if err != nil {
+			return err
+		}
+		if actual := strPtrMapToStrMap(conf); !reflect.DeepEqual(expected, actual) {
+			return fmt.Errorf("expected the config of %s to be %v, got %v", brokerConfigScope(sarama.BrokerResource, broker), expected, actual)
+		}
+		return nil
+	}
+}
+
+const testResourceBrokerConfig = `
+resource "kafka_broker_config" "test" {
+  %s
+
+  config = {
+    %s
+  }
+}
+`
+
+const testResourceBrokerConfig_loggers = `
+resource "kafka_broker_config" "test" {
+  broker_id     = "1"
+  resource_type = "broker_logger"
+
+  config = {
+    "kafka.controller" = "DEBUG"
+  }
+}
+
+data "kafka_broker_loggers" "test" {
+  broker_id = kafka_broker_config.test.broker_id
+
+  depends_on = [kafka_broker_config.test]
+}
+`
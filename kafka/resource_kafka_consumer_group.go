@@ -0,0 +1,179 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func kafkaConsumerGroupResource() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: consumerGroupCreate,
+		ReadContext:   consumerGroupRead,
+		UpdateContext: consumerGroupUpdate,
+		DeleteContext: consumerGroupDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: importConsumerGroup,
+		},
+		Schema: map[string]*schema.Schema{
+			"group_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The ID of the consumer group.",
+			},
+			"topic": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The topic to manage the consumer group's offsets for.",
+			},
+			"reset_to": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				AtLeastOneOf:     []string{"reset_to", "partition_offsets"},
+				ValidateDiagFunc: validateDiagFunc(validation.StringInSlice([]string{offsetResetEarliest, offsetResetLatest}, false)),
+				Description:      "Reset the offsets of every partition to the `earliest` or `latest` offset of the topic.",
+			},
+			"partition_offsets": {
+				Type:         schema.TypeMap,
+				Optional:     true,
+				AtLeastOneOf: []string{"reset_to", "partition_offsets"},
+				Description:  "A map of partition to the offset to commit for it. Takes precedence over `reset_to`.",
+				Elem:         &schema.Schema{Type: schema.TypeInt},
+			},
+			"force": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Commit the offsets even if the consumer group has active members. The broker may still reject the commit.",
+			},
+			"committed_offsets": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Description: "A map of partition to the offset currently committed for it.",
+				Elem:        &schema.Schema{Type: schema.TypeInt},
+			},
+		},
+	}
+}
+
+func consumerGroupCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*LazyClient)
+	o, err := consumerGroupOffsetsInfo(d)
+	if err != nil {
+		return diagFromErr(err)
+	}
+
+	log.Printf("[INFO] Creating consumer group offsets %s", o.ID())
+	if err := c.SetConsumerGroupOffsets(ctx, o); err != nil {
+		log.Println("[ERROR] Failed to set consumer group offsets")
+		return diagFromErr(err)
+	}
+
+	d.SetId(o.ID())
+	return consumerGroupRead(ctx, d, meta)
+}
+
+func consumerGroupUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*LazyClient)
+	o, err := consumerGroupOffsetsInfo(d)
+	if err != nil {
+		return diagFromErr(err)
+	}
+
+	if d.HasChanges("reset_to", "partition_offsets") {
+		log.Printf("[INFO] Updating consumer group offsets %s", o.ID())
+		if err := c.SetConsumerGroupOffsets(ctx, o); err != nil {
+			log.Println("[ERROR] Failed to set consumer group offsets")
+			return diagFromErr(err)
+		}
+	}
+
+	return consumerGroupRead(ctx, d, meta)
+}
+
+func consumerGroupRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*LazyClient)
+	group := d.Get("group_id").(string)
+	topic := d.Get("topic").(string)
+	log.Printf("[INFO] Reading consumer group offsets %s|%s", group, topic)
+
+	offsets, err := c.ListConsumerGroupOffsets(group, topic)
+	if err != nil {
+		log.Printf("[ERROR] Error getting consumer group offsets %s from Kafka", err)
+		_, ok := err.(ConsumerGroupOffsetsMissingError)
+		if ok {
+			d.SetId("")
+			return nil
+		}
+
+		return diagFromErr(err)
+	}
+
+	committed := make(map[string]int, len(offsets))
+	for p, offset := range offsets {
+		committed[strconv.Itoa(int(p))] = int(offset)
+	}
+
+	errSet := errSetter{d: d}
+	errSet.Set("group_id", group)
+	errSet.Set("topic", topic)
+	errSet.Set("committed_offsets", committed)
+	if errSet.err != nil {
+		return diagFromErr(errSet.err)
+	}
+
+	return nil
+}
+
+func consumerGroupDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Committed offsets are left in place; removing them could make the
+	// group's consumers reprocess or skip data.
+	log.Printf("[INFO] Removing consumer group offsets %s from state", d.Id())
+	d.SetId("")
+	return nil
+}
+
+func importConsumerGroup(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	parts := strings.Split(d.Id(), "|")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("failed importing resource; expected format is group_id|topic - got %v segments instead of 2", len(parts))
+	}
+
+	errSet := errSetter{d: d}
+	errSet.Set("group_id", parts[0])
+	errSet.Set("topic", parts[1])
+	errSet.Set("force", false)
+	if errSet.err != nil {
+		return nil, errSet.err
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
+func consumerGroupOffsetsInfo(d *schema.ResourceData) (ConsumerGroupOffsets, error) {
+	o := ConsumerGroupOffsets{
+		Group:   d.Get("group_id").(string),
+		Topic:   d.Get("topic").(string),
+		ResetTo: d.Get("reset_to").(string),
+		Force:   d.Get("force").(bool),
+		Offsets: map[int32]int64{},
+	}
+
+	for k, v := range d.Get("partition_offsets").(map[string]interface{}) {
+		p, err := strconv.ParseInt(k, 10, 32)
+		if err != nil {
+			return o, fmt.Errorf("partition_offsets key %q is not a partition number", k)
+		}
+		o.Offsets[int32(p)] = int64(v.(int))
+	}
+
+	return o, nil
+}
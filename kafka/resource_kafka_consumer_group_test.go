@@ -0,0 +1,72 @@
+package kafka
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	uuid "github.com/hashicorp/go-uuid"
+	r "github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAcc_ConsumerGroupOffsets(t *testing.T) {
+	t.Parallel()
+	u, err := uuid.GenerateUUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	topicName := fmt.Sprintf("syslog-%s", u)
+	groupID := fmt.Sprintf("group-%s", u)
+	bs := testBootstrapServers[0]
+
+	r.Test(t, r.TestCase{
+		ProviderFactories: overrideProviderFactory(),
+		PreCheck:          func() { testAccPreCheck(t) },
+		Steps: []r.TestStep{
+			{
+				Config: cfg(t, bs, fmt.Sprintf(testResourceConsumerGroup_resetTo, topicName, groupID)),
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckResourceAttr("kafka_consumer_group.test", "id", fmt.Sprintf("%s|%s", groupID, topicName)),
+					r.TestCheckResourceAttr("kafka_consumer_group.test", "committed_offsets.%", "2"),
+					r.TestCheckResourceAttr("kafka_consumer_group.test", "committed_offsets.0", "0"),
+					r.TestCheckResourceAttr("kafka_consumer_group.test", "committed_offsets.1", "0"),
+				),
+			},
+			{
+				Config:      cfg(t, bs, fmt.Sprintf(testResourceConsumerGroup_partitionOffsets, topicName, groupID, 5, 3)),
+				ExpectError: regexp.MustCompile("partition 5 does not exist"),
+			},
+		},
+	})
+}
+
+const testResourceConsumerGroup_resetTo = `
+resource "kafka_topic" "test" {
+  name               = "%[1]s"
+  replication_factor = 1
+  partitions         = 2
+}
+
+resource "kafka_consumer_group" "test" {
+  group_id = "%[2]s"
+  topic    = kafka_topic.test.name
+  reset_to = "earliest"
+}
+`
+
+const testResourceConsumerGroup_partitionOffsets = `
+resource "kafka_topic" "test" {
+  name               = "%[1]s"
+  replication_factor = 1
+  partitions         = 2
+}
+
+resource "kafka_consumer_group" "test" {
+  group_id = "%[2]s"
+  topic    = kafka_topic.test.name
+  reset_to = "earliest"
+  partition_offsets = {
+    "%[3]d" = %[4]d
+  }
+}
+`
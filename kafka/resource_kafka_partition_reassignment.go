@@ -0,0 +1,337 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func kafkaPartitionReassignmentResource() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: partitionReassignmentCreate,
+		ReadContext:   partitionReassignmentRead,
+		UpdateContext: partitionReassignmentUpdate,
+		DeleteContext: partitionReassignmentDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: importPartitionReassignment,
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
+		},
+		Schema: map[string]*schema.Schema{
+			"topic": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The topic of the partition to reassign.",
+			},
+			"partition": {
+				Type:         schema.TypeInt,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "The partition to reassign.",
+			},
+			"replicas": {
+				Type:        schema.TypeList,
+				Required:    true,
+				MinItems:    1,
+				Description: "The ordered list of broker IDs to move the partition's replicas to. The first is the preferred leader.",
+				Elem:        &schema.Schema{Type: schema.TypeInt},
+			},
+			"throttle_bytes_per_sec": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(1),
+				Description:  "Limits the replication of the reassignment to this many bytes per second on each broker involved, through the throttled replicas of the topic and the throttle rates of the brokers.",
+			},
+			"auto_remove_throttle": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Whether the throttle is removed once the reassignment is complete, or cancelled.",
+			},
+			"added_throttled_replicas": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Description: "The comma-separated partition:broker pairs the throttle added to each throttled replicas config of the topic, which removing the throttle removes again.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"original_throttle_rates": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Description: "The throttle rates the brokers had before the throttle, keyed by broker:config, which removing the throttle restores. An empty rate was not set, and is removed.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func partitionReassignmentCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*LazyClient)
+	r := partitionReassignmentInfo(d)
+
+	if err := reassignPartition(ctx, c, d, r, d.Timeout(schema.TimeoutCreate)); err != nil {
+		return diagFromErr(err)
+	}
+
+	return partitionReassignmentRead(ctx, d, meta)
+}
+
+func partitionReassignmentUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*LazyClient)
+	r := partitionReassignmentInfo(d)
+
+	if d.HasChange("replicas") {
+		if err := reassignPartition(ctx, c, d, r, d.Timeout(schema.TimeoutUpdate)); err != nil {
+			return diagFromErr(err)
+		}
+	}
+
+	return partitionReassignmentRead(ctx, d, meta)
+}
+
+func partitionReassignmentRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*LazyClient)
+	r := partitionReassignmentInfo(d)
+	log.Printf("[INFO] Reading partition reassignment %s", r.ID())
+
+	replicas, err := c.PartitionReplicas(r.Topic, r.Partition)
+	if err != nil {
+		log.Printf("[ERROR] Error getting replicas of %s from Kafka: %s", r.ID(), err)
+		_, ok := err.(PartitionMissingError)
+		if ok {
+			d.SetId("")
+			return nil
+		}
+
+		return diagFromErr(err)
+	}
+
+	current := make([]int, 0, len(replicas))
+	for _, id := range replicas {
+		current = append(current, int(id))
+	}
+
+	errSet := errSetter{d: d}
+	errSet.Set("topic", r.Topic)
+	errSet.Set("partition", r.Partition)
+	errSet.Set("replicas", current)
+	if errSet.err != nil {
+		return diagFromErr(errSet.err)
+	}
+
+	return nil
+}
+
+func partitionReassignmentDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*LazyClient)
+	r := partitionReassignmentInfo(d)
+
+	// a completed reassignment is left in place; only one that is still
+	// running is cancelled
+	status, err := c.PartitionReassignmentStatus(r.Topic, r.Partition)
+	if err != nil {
+		return diagFromErr(err)
+	}
+
+	if status != nil {
+		log.Printf("[INFO] Cancelling reassignment of %s: %s", r.ID(), status)
+		if err := c.CancelPartitionReassignment(ctx, r.Topic, r.Partition); err != nil {
+			return diagFromErr(err)
+		}
+
+		if throttleConfigured(d) {
+			if err := c.RemoveReassignmentThrottle(r.Topic, r.Partition, reassignmentThrottleInfo(d)); err != nil {
+				return diagFromErr(err)
+			}
+		}
+	}
+
+	d.SetId("")
+	return nil
+}
+
+// reassignPartition moves the partition to r.Replicas and waits for it to
+// complete, throttled if throttle_bytes_per_sec is set. The ID is set once
+// the reassignment is running, along with what the throttle changed. A
+// throttle is left in place while the reassignment still runs, e.g. when
+// waiting for it times out.
+func reassignPartition(ctx context.Context, c *LazyClient, d *schema.ResourceData, r PartitionReassignment, timeout time.Duration) error {
+	var throttle *ReassignmentThrottle
+	if rate, ok := d.GetOk("throttle_bytes_per_sec"); ok {
+		current, err := c.PartitionReplicas(r.Topic, r.Partition)
+		if err != nil {
+			return err
+		}
+		throttle, err = c.ThrottleReassignment(r, current, rate.(int))
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := c.ReassignPartition(ctx, r); err != nil {
+		if throttle != nil {
+			if removeErr := c.RemoveReassignmentThrottle(r.Topic, r.Partition, *throttle); removeErr != nil {
+				log.Printf("[WARN] Failed to remove the throttle of reassignment of %s: %s", r.ID(), removeErr)
+			}
+		}
+		return err
+	}
+	d.SetId(r.ID())
+
+	// a throttle of a previous reassignment that is still in place is
+	// removed along with this one
+	recorded := reassignmentThrottleInfo(d)
+	if throttle != nil {
+		recorded = recorded.merge(*throttle)
+		if err := setReassignmentThrottle(d, recorded); err != nil {
+			return err
+		}
+	}
+
+	if err := waitForPartitionReassignment(ctx, c, r, timeout); err != nil {
+		return err
+	}
+
+	if throttle != nil && d.Get("auto_remove_throttle").(bool) {
+		if err := c.RemoveReassignmentThrottle(r.Topic, r.Partition, recorded); err != nil {
+			return err
+		}
+		return setReassignmentThrottle(d, ReassignmentThrottle{})
+	}
+	return nil
+}
+
+// throttleConfigured reports whether the provider throttles the
+// reassignment and removes the throttle again
+func throttleConfigured(d *schema.ResourceData) bool {
+	_, ok := d.GetOk("throttle_bytes_per_sec")
+	return ok && d.Get("auto_remove_throttle").(bool)
+}
+
+func waitForPartitionReassignment(ctx context.Context, c *LazyClient, r PartitionReassignment, timeout time.Duration) error {
+	refresh := func() (interface{}, string, error) {
+		status, err := c.PartitionReassignmentStatus(r.Topic, r.Partition)
+		if err != nil {
+			return nil, "Error", err
+		}
+		if status != nil {
+			log.Printf("[INFO] Reassignment of %s in progress: %s", r.ID(), status)
+			return status, "Reassigning", nil
+		}
+
+		replicas, err := c.PartitionReplicas(r.Topic, r.Partition)
+		if err != nil {
+			return nil, "Error", err
+		}
+		if !int32SliceEq(replicas, r.Replicas) {
+			return nil, "Error", fmt.Errorf("reassignment of %s did not take effect: replicas are %v, expected %v", r.ID(), replicas, r.Replicas)
+		}
+
+		log.Printf("[INFO] Reassignment of %s complete", r.ID())
+		return replicas, "Done", nil
+	}
+
+	stateConf := &retry.StateChangeConf{
+		Pending:      []string{"Reassigning"},
+		Target:       []string{"Done"},
+		Refresh:      refresh,
+		Timeout:      timeout,
+		Delay:        1 * time.Second,
+		PollInterval: 2 * time.Second,
+	}
+
+	if _, err := stateConf.WaitForStateContext(ctx); err != nil {
+		return fmt.Errorf("error waiting for reassignment of %s to complete: %s", r.ID(), err)
+	}
+
+	return nil
+}
+
+func importPartitionReassignment(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	parts := strings.Split(d.Id(), "|")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("failed importing resource; expected format is topic|partition - got %v segments instead of 2", len(parts))
+	}
+
+	partition, err := strconv.ParseInt(parts[1], 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed importing resource; partition %q is not a number", parts[1])
+	}
+
+	errSet := errSetter{d: d}
+	errSet.Set("topic", parts[0])
+	errSet.Set("partition", int(partition))
+	errSet.Set("auto_remove_throttle", true)
+	if errSet.err != nil {
+		return nil, errSet.err
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
+func partitionReassignmentInfo(d *schema.ResourceData) PartitionReassignment {
+	rawReplicas := d.Get("replicas").([]interface{})
+	replicas := make([]int32, 0, len(rawReplicas))
+	for _, r := range rawReplicas {
+		replicas = append(replicas, int32(r.(int)))
+	}
+
+	return PartitionReassignment{
+		Topic:     d.Get("topic").(string),
+		Partition: int32(d.Get("partition").(int)),
+		Replicas:  replicas,
+	}
+}
+
+// reassignmentThrottleInfo returns what the throttle of the reassignment
+// changed, as recorded in the state
+func reassignmentThrottleInfo(d *schema.ResourceData) ReassignmentThrottle {
+	throttle := ReassignmentThrottle{Replicas: map[string][]string{}, Rates: map[int32]map[string]string{}}
+	for key, pairs := range d.Get("added_throttled_replicas").(map[string]interface{}) {
+		throttle.Replicas[key] = parseThrottledReplicas(pairs.(string))
+	}
+	for key, rate := range d.Get("original_throttle_rates").(map[string]interface{}) {
+		parts := strings.SplitN(key, ":", 2)
+		broker, err := strconv.ParseInt(parts[0], 10, 32)
+		if len(parts) != 2 || err != nil {
+			log.Printf("[WARN] Ignoring the original throttle rate %q, expected broker:config", key)
+			continue
+		}
+		if throttle.Rates[int32(broker)] == nil {
+			throttle.Rates[int32(broker)] = map[string]string{}
+		}
+		throttle.Rates[int32(broker)][parts[1]] = rate.(string)
+	}
+	return throttle
+}
+
+// setReassignmentThrottle records what the throttle of the reassignment
+// changed in the state
+func setReassignmentThrottle(d *schema.ResourceData, throttle ReassignmentThrottle) error {
+	replicas := map[string]interface{}{}
+	for key, pairs := range throttle.Replicas {
+		replicas[key] = formatThrottledReplicas(pairs)
+	}
+	rates := map[string]interface{}{}
+	for broker, brokerRates := range throttle.Rates {
+		for key, rate := range brokerRates {
+			rates[fmt.Sprintf("%d:%s", broker, key)] = rate
+		}
+	}
+
+	errSet := errSetter{d: d}
+	errSet.Set("added_throttled_replicas", replicas)
+	errSet.Set("original_throttle_rates", rates)
+	return errSet.err
+}
@@ -0,0 +1,170 @@
+package kafka
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/IBM/sarama"
+	uuid "github.com/hashicorp/go-uuid"
+	r "github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAcc_PartitionReassignment(t *testing.T) {
+	t.Parallel()
+	u, err := uuid.GenerateUUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	topicName := fmt.Sprintf("syslog-%s", u)
+	bs := testBootstrapServers[0]
+
+	r.Test(t, r.TestCase{
+		ProviderFactories: overrideProviderFactory(),
+		PreCheck:          func() { testAccPreCheck(t) },
+		Steps: []r.TestStep{
+			{
+				Config: cfg(t, bs, fmt.Sprintf(testResourcePartitionReassignment, topicName, "2, 3")),
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckResourceAttr("kafka_partition_reassignment.test", "id", fmt.Sprintf("%s|1", topicName)),
+					r.TestCheckResourceAttr("kafka_partition_reassignment.test", "replicas.#", "2"),
+					r.TestCheckResourceAttr("kafka_partition_reassignment.test", "replicas.0", "2"),
+					r.TestCheckResourceAttr("kafka_partition_reassignment.test", "replicas.1", "3"),
+				),
+			},
+			{
+				Config: cfg(t, bs, fmt.Sprintf(testResourcePartitionReassignment, topicName, "3, 1")),
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckResourceAttr("kafka_partition_reassignment.test", "replicas.0", "3"),
+					r.TestCheckResourceAttr("kafka_partition_reassignment.test", "replicas.1", "1"),
+				),
+			},
+			{
+				ResourceName:      "kafka_partition_reassignment.test",
+				ImportState:       true,
+				ImportStateId:     fmt.Sprintf("%s|1", topicName),
+				ImportStateVerify: true,
+			},
+			{
+				Config:      cfg(t, bs, fmt.Sprintf(testResourcePartitionReassignment, topicName, "3, 42")),
+				ExpectError: regexp.MustCompile(`brokers \[42\] are not part of the cluster`),
+			},
+		},
+	})
+}
+
+func TestAcc_PartitionReassignmentThrottled(t *testing.T) {
+	t.Parallel()
+	u, err := uuid.GenerateUUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	topicName := fmt.Sprintf("syslog-%s", u)
+	bs := testBootstrapServers[0]
+
+	r.Test(t, r.TestCase{
+		ProviderFactories: overrideProviderFactory(),
+		PreCheck:          func() { testAccPreCheck(t) },
+		Steps: []r.TestStep{
+			{
+				Config: cfg(t, bs, fmt.Sprintf(testResourcePartitionReassignment_throttled, topicName)),
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckResourceAttr("kafka_partition_reassignment.test", "replicas.0", "3"),
+					r.TestCheckResourceAttr("kafka_partition_reassignment.test", "throttle_bytes_per_sec", "10485760"),
+					r.TestCheckResourceAttr("kafka_partition_reassignment.test", "original_throttle_rates.%", "0"),
+					testAccCheckReassignmentThrottleRemoved(topicName),
+				),
+			},
+		},
+	})
+}
+
+func Test_reassignmentThrottleState(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, kafkaPartitionReassignmentResource().Schema, map[string]interface{}{
+		"topic":     "test",
+		"partition": 0,
+		"replicas":  []interface{}{3},
+	})
+
+	throttle := ReassignmentThrottle{
+		Replicas: map[string][]string{followerThrottledReplicasConfig: {"0:3"}},
+		Rates: map[int32]map[string]string{
+			1: {leaderThrottledRateConfig: "1000", followerThrottledRateConfig: ""},
+			3: {leaderThrottledRateConfig: "", followerThrottledRateConfig: ""},
+		},
+	}
+	if err := setReassignmentThrottle(d, throttle); err != nil {
+		t.Fatal(err)
+	}
+
+	if recorded := reassignmentThrottleInfo(d); !reflect.DeepEqual(recorded, throttle) {
+		t.Fatalf("expected %v, got %v", throttle, recorded)
+	}
+
+	if err := setReassignmentThrottle(d, ReassignmentThrottle{}); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 0, len(reassignmentThrottleInfo(d).Rates))
+}
+
+// testAccCheckReassignmentThrottleRemoved checks that no throttle is left
+// on the topic or the brokers once the reassignment is complete
+func testAccCheckReassignmentThrottleRemoved(topic string) r.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testProvider.Meta().(*LazyClient)
+		conf, err := client.TopicConfig(topic)
+		if err != nil {
+			return err
+		}
+		for _, key := range []string{leaderThrottledReplicasConfig, followerThrottledReplicasConfig} {
+			if v, ok := conf[key]; ok {
+				return fmt.Errorf("expected %s to be removed from topic %s, got %v", key, topic, v)
+			}
+		}
+
+		for _, broker := range []string{"1", "2", "3"} {
+			conf, err := client.BrokerConfig(sarama.BrokerResource, broker)
+			if err != nil {
+				return err
+			}
+			for _, key := range []string{leaderThrottledRateConfig, followerThrottledRateConfig} {
+				if v, ok := conf[key]; ok {
+					return fmt.Errorf("expected %s to be removed from broker %s, got %v", key, broker, v)
+				}
+			}
+		}
+		return nil
+	}
+}
+
+const testResourcePartitionReassignment_throttled = `
+resource "kafka_topic" "test" {
+  name               = "%s"
+  replication_factor = 1
+  partitions         = 1
+}
+
+resource "kafka_partition_reassignment" "test" {
+  topic                  = kafka_topic.test.name
+  partition              = 0
+  replicas               = [3]
+  throttle_bytes_per_sec = 10485760
+}
+`
+
+const testResourcePartitionReassignment = `
+resource "kafka_topic" "test" {
+  name               = "%s"
+  replication_factor = 2
+  partitions         = 2
+}
+
+resource "kafka_partition_reassignment" "test" {
+  topic     = kafka_topic.test.name
+  partition = 1
+  replicas  = [%s]
+}
+`
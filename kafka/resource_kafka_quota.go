@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net"
 	"time"
 
+	"github.com/IBM/sarama"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -18,6 +20,7 @@ func kafkaQuotaResource() *schema.Resource {
 		CreateContext: quotaCreate,
 		ReadContext:   quotaRead,
 		DeleteContext: quotaDelete,
+		CustomizeDiff: quotaCustomDiff,
 		Schema: map[string]*schema.Schema{
 			"entity_name": {
 				Type:        schema.TypeString,
@@ -25,12 +28,33 @@ func kafkaQuotaResource() *schema.Resource {
 				ForceNew:    true,
 				Description: "The name of the entity (if entity_name is not provided, it will create entity-default Kafka quota)",
 			},
+			"entity_default": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+				Description: "Whether the quota applies to the default entity of entity_type. Defaults to true if entity_name is not set, so set it to false together with `entity_name = \"\"` for a quota of the entity with the empty name.",
+			},
+			"client_id": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ForceNew:      true,
+				ConflictsWith: []string{"client_id_default"},
+				Description:   "The client ID of a combined (user, client-id) quota. Requires entity_type to be user.",
+			},
+			"client_id_default": {
+				Type:          schema.TypeBool,
+				Optional:      true,
+				ForceNew:      true,
+				ConflictsWith: []string{"client_id"},
+				Description:   "Combine the user with the default client ID, for a quota applying to each client ID of the user without its own. Requires entity_type to be user.",
+			},
 			"entity_type": {
 				Type:             schema.TypeString,
 				Required:         true,
 				ForceNew:         true,
 				ValidateDiagFunc: validateDiagFunc(validation.StringInSlice([]string{"client-id", "user", "ip"}, false)),
-				Description:      "The type of the entity (client-id, user, ip)",
+				Description:      "The type of the entity (client-id, user, ip). ip quotas only support connection_creation_rate and require entity_name to be a single IP address.",
 			},
 			"config": {
 				Type:        schema.TypeMap,
@@ -48,10 +72,10 @@ func quotaCreate(ctx context.Context, d *schema.ResourceData, meta interface{})
 	quota := newQuota(d, false)
 	log.Printf("[INFO] Creating Quota %s", quota)
 
-	err := c.AlterQuota(quota)
+	err := c.AlterQuota(ctx, quota)
 	if err != nil {
 		log.Println("[ERROR] Failed to create Quota")
-		return diag.FromErr(err)
+		return diagFromErr(err)
 	}
 
 	stateConf := &retry.StateChangeConf{
@@ -64,7 +88,7 @@ func quotaCreate(ctx context.Context, d *schema.ResourceData, meta interface{})
 	}
 
 	if _, err := stateConf.WaitForStateContext(ctx); err != nil {
-		return diag.FromErr(fmt.Errorf("error waiting for quota (%s) to be created: %s", quota.ID(), err))
+		return diagFromErr(fmt.Errorf("error waiting for quota (%s) to be created: %s", quota.ID(), err))
 	}
 
 	d.SetId(quota.ID())
@@ -74,7 +98,7 @@ func quotaCreate(ctx context.Context, d *schema.ResourceData, meta interface{})
 
 func quotaCreatedFunc(client *LazyClient, q Quota) retry.StateRefreshFunc {
 	return func() (result interface{}, s string, err error) {
-		fq, err := client.DescribeQuota(q.EntityType, q.EntityName)
+		fq, err := client.DescribeQuotaEntity(q)
 		switch e := err.(type) {
 		case QuotaMissingError:
 			return fq, "Pending", nil
@@ -91,10 +115,10 @@ func quotaDelete(ctx context.Context, d *schema.ResourceData, meta interface{})
 	quota := newQuota(d, true)
 	log.Printf("[INFO] Deleting quota %s", quota)
 
-	err := c.AlterQuota(quota)
+	err := c.AlterQuota(ctx, quota)
 	if err != nil {
 		log.Println("[ERROR] Failed to delete Quota")
-		return diag.FromErr(err)
+		return diagFromErr(err)
 	}
 
 	return nil
@@ -104,11 +128,10 @@ func quotaRead(ctx context.Context, d *schema.ResourceData, meta interface{}) di
 	log.Println("[INFO] Reading Quota")
 	c := meta.(*LazyClient)
 
-	entityType := d.Get("entity_type").(string)
-	entityName := d.Get("entity_name").(string)
-	log.Printf("[INFO] Reading Quota %s", entityName)
+	quota := newQuota(d, false)
+	log.Printf("[INFO] Reading Quota %s", quota)
 
-	foundQuota, err := c.DescribeQuota(entityType, entityName)
+	foundQuota, err := c.DescribeQuotaEntity(quota)
 	if err != nil {
 		log.Printf("[ERROR] Error getting quota %s from Kafka", err)
 		_, ok := err.(QuotaMissingError)
@@ -117,7 +140,7 @@ func quotaRead(ctx context.Context, d *schema.ResourceData, meta interface{}) di
 			return nil
 		}
 
-		return diag.FromErr(err)
+		return diagFromErr(err)
 	}
 
 	log.Printf("[DEBUG] Setting the state from Kafka %v", foundQuota)
@@ -129,9 +152,14 @@ func quotaRead(ctx context.Context, d *schema.ResourceData, meta interface{}) di
 	errSet := errSetter{d: d}
 	errSet.Set("entity_name", foundQuota.EntityName)
 	errSet.Set("entity_type", foundQuota.EntityType)
+	errSet.Set("entity_default", foundQuota.EntityDefault)
+	if foundQuota.ClientID != nil {
+		errSet.Set("client_id", foundQuota.ClientID.Name)
+		errSet.Set("client_id_default", foundQuota.ClientID.Default)
+	}
 	errSet.Set("config", configs)
 	if errSet.err != nil {
-		return diag.FromErr(errSet.err)
+		return diagFromErr(errSet.err)
 	}
 
 	log.Printf("[INFO] Found Quota %s %+v.", foundQuota.ID(), foundQuota.Ops)
@@ -152,9 +180,79 @@ func newQuota(d *schema.ResourceData, removeAll bool) Quota {
 		}
 	}
 
-	return Quota{
-		EntityType: d.Get("entity_type").(string),
-		EntityName: d.Get("entity_name").(string),
-		Ops:        ops,
+	q := Quota{
+		EntityType:    d.Get("entity_type").(string),
+		EntityName:    d.Get("entity_name").(string),
+		EntityDefault: quotaEntityDefault(d),
+		Ops:           ops,
+	}
+	if clientID := d.Get("client_id").(string); clientID != "" {
+		q.ClientID = &QuotaEntity{Name: clientID}
+	} else if d.Get("client_id_default").(bool) {
+		q.ClientID = &QuotaEntity{Default: true}
+	}
+	return q
+}
+
+// quotaEntityDefault returns entity_default, falling back to whether
+// entity_name is empty for state written before entity_default existed
+func quotaEntityDefault(d *schema.ResourceData) bool {
+	if v, ok := d.GetOkExists("entity_default"); ok { //nolint:staticcheck
+		return v.(bool)
+	}
+	return d.Get("entity_name").(string) == ""
+}
+
+func quotaCustomDiff(ctx context.Context, diff *schema.ResourceDiff, v interface{}) error {
+	entityType := diff.Get("entity_type").(string)
+	combined := diff.Get("client_id").(string) != "" || diff.Get("client_id_default").(bool)
+	if combined && entityType != "user" {
+		return fmt.Errorf("client_id and client_id_default can only be combined with entity_type user, got %s", entityType)
+	}
+
+	if err := validateQuotaConfig(entityType, diff.Get("entity_name").(string), diff.Get("config").(map[string]interface{})); err != nil {
+		return err
+	}
+
+	// entity_name cannot tell an unset name, the default entity, from the
+	// empty name, so fall back to the raw config
+	nameConfigured := !diff.GetRawConfig().GetAttr("entity_name").IsNull()
+	defaultConfigured := !diff.GetRawConfig().GetAttr("entity_default").IsNull()
+	entityName := diff.Get("entity_name").(string)
+
+	if defaultConfigured {
+		if diff.Get("entity_default").(bool) && entityName != "" {
+			return fmt.Errorf("entity_name %q cannot be set for the default entity", entityName)
+		}
+		return nil
+	}
+
+	if diff.Id() == "" || diff.HasChange("entity_name") {
+		return diff.SetNew("entity_default", !nameConfigured)
+	}
+	return nil
+}
+
+// connectionCreationRate is the only quota of ip entities, and only applies to
+// them
+const connectionCreationRate = "connection_creation_rate"
+
+func validateQuotaConfig(entityType string, entityName string, config map[string]interface{}) error {
+	if entityType == string(sarama.QuotaEntityIP) {
+		// Kafka only accepts single addresses, not CIDR ranges
+		if entityName != "" && net.ParseIP(entityName) == nil {
+			return fmt.Errorf("entity_name %q is not a valid IP address; ip quotas apply to a single address", entityName)
+		}
+		for key := range config {
+			if key != connectionCreationRate {
+				return fmt.Errorf("ip quotas only support %s, got %s", connectionCreationRate, key)
+			}
+		}
+		return nil
+	}
+
+	if _, ok := config[connectionCreationRate]; ok {
+		return fmt.Errorf("%s is only supported for entity_type ip, got %s", connectionCreationRate, entityType)
 	}
+	return nil
 }
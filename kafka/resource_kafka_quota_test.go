@@ -2,6 +2,8 @@ package kafka
 
 import (
 	"fmt"
+	"regexp"
+	"strings"
 	"testing"
 
 	uuid "github.com/hashicorp/go-uuid"
@@ -191,6 +193,133 @@ func testResourceQuota_updateCheck(s *terraform.State) error {
 	return nil
 }
 
+func TestAcc_UserClientIDQuota(t *testing.T) {
+	t.Parallel()
+	u, err := uuid.GenerateUUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := fmt.Sprintf("quota-user-%s", u)
+	bs := testBootstrapServers[0]
+
+	r.Test(t, r.TestCase{
+		ProviderFactories: overrideProviderFactory(),
+		PreCheck:          func() { testAccPreCheck(t) },
+		CheckDestroy:      testAccCheckQuotaDestroy,
+		Steps: []r.TestStep{
+			{
+				Config: cfgs(t, bs, fmt.Sprintf(testResourceQuotaUserClientID, user, `client_id = "app"`)),
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckResourceAttr("kafka_quota.test1", "id", fmt.Sprintf("%s|user|app|client-id", user)),
+					r.TestCheckResourceAttr("kafka_quota.test1", "entity_default", "false"),
+					testResourceQuota_combinedCheck,
+				),
+			},
+			{
+				Config: cfgs(t, bs, fmt.Sprintf(testResourceQuotaUserClientID, user, `client_id_default = true`)),
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckResourceAttr("kafka_quota.test1", "id", fmt.Sprintf("%s|user|%s|client-id", user, entityDefault)),
+					testResourceQuota_combinedCheck,
+				),
+			},
+		},
+	})
+}
+
+func testResourceQuota_combinedCheck(s *terraform.State) error {
+	instanceState := s.RootModule().Resources["kafka_quota.test1"].Primary
+	client := testProvider.Meta().(*LazyClient)
+
+	quota, err := client.DescribeQuotaEntity(quotaFromAttributes(instanceState.Attributes))
+	if err != nil {
+		return err
+	}
+	if quota.ClientID == nil {
+		return fmt.Errorf("expected a combined (user, client-id) quota, got %s", quota)
+	}
+
+	// the user's own quota must be left alone
+	if _, err := client.DescribeQuota("user", quota.EntityName); err == nil {
+		return fmt.Errorf("expected no quota for user %s alone", quota.EntityName)
+	}
+
+	return nil
+}
+
+func quotaFromAttributes(attrs map[string]string) Quota {
+	q := Quota{
+		EntityType:    attrs["entity_type"],
+		EntityName:    attrs["entity_name"],
+		EntityDefault: attrs["entity_default"] == "true",
+	}
+	if attrs["client_id"] != "" {
+		q.ClientID = &QuotaEntity{Name: attrs["client_id"]}
+	} else if attrs["client_id_default"] == "true" {
+		q.ClientID = &QuotaEntity{Default: true}
+	}
+	return q
+}
+
+func TestAcc_IPQuota(t *testing.T) {
+	t.Parallel()
+	bs := testBootstrapServers[0]
+
+	r.Test(t, r.TestCase{
+		ProviderFactories: overrideProviderFactory(),
+		PreCheck:          func() { testAccPreCheck(t) },
+		CheckDestroy:      testAccCheckQuotaDestroy,
+		Steps: []r.TestStep{
+			{
+				Config: cfgs(t, bs, fmt.Sprintf(testResourceQuotaIP, "10.11.12.13", connectionCreationRate)),
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckResourceAttr("kafka_quota.test1", "id", "10.11.12.13|ip"),
+					r.TestCheckResourceAttr("kafka_quota.test1", "config.connection_creation_rate", "25"),
+				),
+			},
+			{
+				Config:      cfgs(t, bs, fmt.Sprintf(testResourceQuotaIP, "10.11.12.0/24", connectionCreationRate)),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("not a valid IP address"),
+			},
+			{
+				Config:      cfgs(t, bs, fmt.Sprintf(testResourceQuotaIP, "10.11.12.13", "consumer_byte_rate")),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("ip quotas only support connection_creation_rate"),
+			},
+		},
+	})
+}
+
+func Test_validateQuotaConfig(t *testing.T) {
+	rate := map[string]interface{}{connectionCreationRate: 10.0}
+	bytes := map[string]interface{}{"consumer_byte_rate": 10.0}
+
+	for _, tc := range []struct {
+		entityType string
+		entityName string
+		config     map[string]interface{}
+		err        string
+	}{
+		{"ip", "10.0.0.1", rate, ""},
+		{"ip", "2001:db8::1", rate, ""},
+		{"ip", "", rate, ""},
+		{"ip", "10.0.0.0/8", rate, "is not a valid IP address"},
+		{"ip", "kafka.example.com", rate, "is not a valid IP address"},
+		{"ip", "10.0.0.1", bytes, "ip quotas only support connection_creation_rate"},
+		{"user", "alice", bytes, ""},
+		{"user", "alice", rate, "connection_creation_rate is only supported for entity_type ip"},
+		{"client-id", "", rate, "connection_creation_rate is only supported for entity_type ip"},
+	} {
+		err := validateQuotaConfig(tc.entityType, tc.entityName, tc.config)
+		if tc.err == "" && err != nil {
+			t.Errorf("%s %q: unexpected error %s", tc.entityType, tc.entityName, err)
+		}
+		if tc.err != "" && (err == nil || !strings.Contains(err.Error(), tc.err)) {
+			t.Errorf("%s %q: expected error containing %q, got %v", tc.entityType, tc.entityName, tc.err, err)
+		}
+	}
+}
+
 func testAccCheckQuotaDestroy(s *terraform.State) error {
 	resourceState := s.Modules[0].Resources["kafka_quota.test1"]
 	if resourceState == nil {
@@ -202,16 +331,13 @@ func testAccCheckQuotaDestroy(s *terraform.State) error {
 		return fmt.Errorf("resource has no primary instance")
 	}
 
-	entityType := instanceState.Attributes["entity_type"]
-	entityName := instanceState.Attributes["entity_name"]
-
 	meta := testProvider.Meta()
 	if meta == nil {
 		return fmt.Errorf("provider Meta() returned nil")
 	}
 
 	client := meta.(*LazyClient)
-	_, err := client.DescribeQuota(entityType, entityName)
+	_, err := client.DescribeQuotaEntity(quotaFromAttributes(instanceState.Attributes))
 
 	if err == nil {
 		return fmt.Errorf("quota was found")
@@ -255,3 +381,24 @@ resource "kafka_quota" "test1" {
   }
 }
 `
+
+const testResourceQuotaUserClientID = `
+resource "kafka_quota" "test1" {
+  entity_name = "%s"
+  entity_type = "user"
+  %s
+  config = {
+    "consumer_byte_rate" = "4000000"
+  }
+}
+`
+
+const testResourceQuotaIP = `
+resource "kafka_quota" "test1" {
+  entity_name = "%s"
+  entity_type = "ip"
+  config = {
+    "%s" = "25"
+  }
+}
+`
@@ -2,10 +2,14 @@ package kafka
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"reflect"
+	"strings"
 	"time"
 
+	"github.com/IBM/sarama"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -19,9 +23,10 @@ func kafkaTopicResource() *schema.Resource {
 		UpdateContext: topicUpdate,
 		DeleteContext: topicDelete,
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: importTopic,
 		},
 		CustomizeDiff: customDiff,
+		Timeouts:      resourceTimeouts(),
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:        schema.TypeString,
@@ -31,24 +36,94 @@ func kafkaTopicResource() *schema.Resource {
 			},
 			"partitions": {
 				Type:         schema.TypeInt,
-				Required:     true,
-				Description:  "Number of partitions.",
+				Optional:     true,
+				Computed:     true,
+				Description:  "Number of partitions. Defaults to the broker's `num.partitions`, or to the number of partitions of `replica_assignment`.",
 				ValidateFunc: validation.IntAtLeast(1),
 			},
 			"replication_factor": {
-				Type:         schema.TypeInt,
-				Required:     true,
-				ForceNew:     false,
-				Description:  "Number of replicas.",
-				ValidateFunc: validation.IntAtLeast(1),
+				Type:          schema.TypeInt,
+				Optional:      true,
+				Computed:      true,
+				ForceNew:      false,
+				ConflictsWith: []string{"replica_assignment"},
+				Description:   "Number of replicas. Defaults to the broker's `default.replication.factor`.",
+				ValidateFunc:  validation.IntAtLeast(1),
+			},
+			"replica_assignment": {
+				Type:          schema.TypeSet,
+				Optional:      true,
+				Computed:      true,
+				ConflictsWith: []string{"replication_factor"},
+				Description:   "The brokers to place the replicas of each partition on. Conflicts with `replication_factor`.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"partition": {
+							Type:         schema.TypeInt,
+							Required:     true,
+							Description:  "The partition number.",
+							ValidateFunc: validation.IntAtLeast(0),
+						},
+						"replicas": {
+							Type:        schema.TypeList,
+							Required:    true,
+							MinItems:    1,
+							Description: "The ordered list of broker IDs holding the partition's replicas. The first is the preferred leader.",
+							Elem:        &schema.Schema{Type: schema.TypeInt},
+						},
+					},
+				},
 			},
 			"config": {
 				Type:        schema.TypeMap,
 				Optional:    true,
 				ForceNew:    false,
-				Description: "A map of string k/v attributes.",
+				Description: "A map of string k/v attributes. An entry set to `inherit` is left unset on the topic, so it inherits the broker's default even if `default_topic_config` sets it.",
+				Elem:        schema.TypeString,
+			},
+			"sensitive_config": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Sensitive:   true,
+				Description: "Config entries whose values are secret, e.g. passwords. They are redacted from plans, and as Kafka never returns sensitive values, they are kept as configured rather than read back. They are left out of `effective_config`.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"adopt_existing": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Manage the topic if it already exists when created, instead of failing. Its partitions, replication and config must match the resource.",
+			},
+			"deletion_protection": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Refuse to delete the topic, including to replace it, while set. It must be set to false and applied before the topic can be destroyed.",
+			},
+			"effective_config": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Description: "The config applied to the topic, including the provider's `default_topic_config`.",
 				Elem:        schema.TypeString,
 			},
+			"leader_replication_throttled_replicas": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "The replicas to throttle on the leader side while reassigning, as `partition:broker` pairs or `*` for all. Rendered into `leader.replication.throttled.replicas`.",
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringMatch(throttledReplicaRegex, "must be a partition:broker pair or *"),
+				},
+			},
+			"follower_replication_throttled_replicas": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "The replicas to throttle on the follower side while reassigning, as `partition:broker` pairs or `*` for all. Rendered into `follower.replication.throttled.replicas`.",
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringMatch(throttledReplicaRegex, "must be a partition:broker pair or *"),
+				},
+			},
 		},
 	}
 }
@@ -57,28 +132,54 @@ func topicCreate(ctx context.Context, d *schema.ResourceData, meta interface{})
 	c := meta.(*LazyClient)
 	t := metaToTopic(d, meta)
 
-	err := c.CreateTopic(t)
+	err := c.CreateTopic(ctx, t)
+	if errors.Is(err, sarama.ErrTopicAlreadyExists) && d.Get("adopt_existing").(bool) {
+		log.Printf("[INFO] Topic %s already exists, adopting it", t.Name)
+		if err := adoptTopic(c, t); err != nil {
+			return diagFromErr(err)
+		}
+		d.SetId(t.Name)
+		return nil
+	}
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromErr(err)
 	}
 
+	// without a poll interval, the wait between reads backs off
+	// exponentially from MinTimeout up to 10s
 	stateConf := &retry.StateChangeConf{
-		Pending:      []string{"Pending"},
-		Target:       []string{"Created"},
-		Refresh:      topicCreateFunc(c, t),
-		Timeout:      time.Duration(c.Config.Timeout) * time.Second,
-		Delay:        1 * time.Second,
-		PollInterval: 2 * time.Second,
+		Pending:    []string{"Pending"},
+		Target:     []string{"Created"},
+		Refresh:    topicCreateFunc(c, t),
+		Timeout:    operationTimeout(d, schema.TimeoutCreate, time.Duration(c.Config.Timeout)*time.Second),
+		Delay:      500 * time.Millisecond,
+		MinTimeout: 500 * time.Millisecond,
 	}
 
 	if _, err := stateConf.WaitForStateContext(ctx); err != nil {
-		return diag.FromErr(fmt.Errorf("error waiting for topic (%s) to be created: %s", t.Name, err))
+		return diagFromErr(fmt.Errorf("error waiting for topic (%s) to be created: %s", t.Name, err))
 	}
 
 	d.SetId(t.Name)
 	return nil
 }
 
+// adoptTopic checks that the existing topic matches t, so that it can be
+// managed as if it had just been created
+func adoptTopic(c *LazyClient, t Topic) error {
+	existing, err := c.ReadTopic(t.Name, true)
+	if err != nil {
+		return err
+	}
+
+	if mismatches := t.mismatches(existing); len(mismatches) > 0 {
+		return fmt.Errorf("topic %s already exists but does not match the resource, so it cannot be adopted: %s", t.Name, strings.Join(mismatches, "; "))
+	}
+	return nil
+}
+
+// topicCreateFunc waits for the topic to be in the metadata of every broker,
+// so that the Read following the creation cannot miss it
 func topicCreateFunc(client *LazyClient, t Topic) retry.StateRefreshFunc {
 	return func() (result interface{}, s string, err error) {
 		topic, err := client.ReadTopic(t.Name, true)
@@ -86,38 +187,63 @@ func topicCreateFunc(client *LazyClient, t Topic) retry.StateRefreshFunc {
 		case TopicMissingError:
 			return topic, "Pending", nil
 		case nil:
-			return topic, "Created", nil
 		default:
 			return topic, "Error", e
 		}
+
+		propagated, err := client.TopicPropagated(t.Name)
+		if err != nil {
+			return topic, "Error", err
+		}
+		if !propagated {
+			return topic, "Pending", nil
+		}
+		return topic, "Created", nil
 	}
 }
 
 func topicUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*LazyClient)
 	t := metaToTopic(d, meta)
-
-	if err := c.UpdateTopic(t); err != nil {
-		return diag.FromErr(err)
+	timeout := operationTimeout(d, schema.TimeoutUpdate, time.Duration(c.Config.Timeout)*time.Second)
+
+	// each change only alters what it is about, so that e.g. adding
+	// partitions doesn't send the topic's config again, and a failure of
+	// one leaves the other parts of the topic as they were
+	if d.HasChanges("config", "sensitive_config", "effective_config", "leader_replication_throttled_replicas", "follower_replication_throttled_replicas") {
+		log.Printf("[INFO] Updating config of %s", t.Name)
+		if err := c.UpdateTopic(ctx, t); err != nil {
+			return diagFromErr(err)
+		}
 	}
 
-	// update replica count of existing partitions before adding new ones
-	if d.HasChange("replication_factor") {
+	// update replicas of existing partitions before adding new ones
+	if d.HasChange("replica_assignment") && len(t.ReplicaAssignment) > 0 {
+		log.Printf("[INFO] Updating replica_assignment of %s", t.Name)
+		if err := c.AlterReplicaAssignment(ctx, t); err != nil {
+			return diagFromErr(err)
+		}
+
+		if err := waitForRFUpdate(ctx, c, d.Id(), timeout); err != nil {
+			return diagFromErr(err)
+		}
+	} else if d.HasChange("replication_factor") {
 		oi, ni := d.GetChange("replication_factor")
 		oldRF := oi.(int)
 		newRF := ni.(int)
 		log.Printf("[INFO] Updating replication_factor from %d to %d", oldRF, newRF)
 		t.ReplicationFactor = int16(newRF)
 
-		if err := c.AlterReplicationFactor(t); err != nil {
-			return diag.FromErr(err)
+		if err := c.AlterReplicationFactor(ctx, t); err != nil {
+			return diagFromErr(err)
 		}
 
-		if err := waitForRFUpdate(ctx, c, d.Id()); err != nil {
-			return diag.FromErr(err)
+		if err := waitForRFUpdate(ctx, c, d.Id(), timeout); err != nil {
+			return diagFromErr(err)
 		}
 	}
 
+	var diags diag.Diagnostics
 	if d.HasChange("partitions") {
 		// update should only be called when we're increasing partitions
 		oi, ni := d.GetChange("partitions")
@@ -126,19 +252,25 @@ func topicUpdate(ctx context.Context, d *schema.ResourceData, meta interface{})
 		log.Printf("[INFO] Updating partitions from %d to %d", oldPartitions, newPartitions)
 		t.Partitions = int32(newPartitions)
 
-		if err := c.AddPartitions(t); err != nil {
-			return diag.FromErr(err)
+		if err := c.AddPartitions(ctx, t); err != nil {
+			return diagFromErr(err)
 		}
+
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  fmt.Sprintf("Partitions of %s increased from %d to %d", t.Name, oldPartitions, newPartitions),
+			Detail:   "Producers partitioning by key will now send some keys to different partitions, so messages with the same key are no longer guaranteed to be consumed in order across the change.",
+		})
 	}
 
-	if err := waitForTopicRefresh(ctx, c, d.Id(), t); err != nil {
-		return diag.FromErr(err)
+	if err := waitForTopicRefresh(ctx, c, d.Id(), t, timeout); err != nil {
+		return append(diags, diagFromErr(err)...)
 	}
 
-	return nil
+	return diags
 }
 
-func waitForRFUpdate(ctx context.Context, client *LazyClient, topic string) error {
+func waitForRFUpdate(ctx context.Context, client *LazyClient, topic string, timeout time.Duration) error {
 	refresh := func() (interface{}, string, error) {
 		isRFUpdating, err := client.IsReplicationFactorUpdating(topic)
 		if err != nil {
@@ -150,7 +282,6 @@ func waitForRFUpdate(ctx context.Context, client *LazyClient, topic string) erro
 		}
 	}
 
-	timeout := time.Duration(client.Config.Timeout) * time.Second
 	stateConf := &retry.StateChangeConf{
 		Pending:      []string{"Updating"},
 		Target:       []string{"Ready"},
@@ -170,8 +301,7 @@ func waitForRFUpdate(ctx context.Context, client *LazyClient, topic string) erro
 	return nil
 }
 
-func waitForTopicRefresh(ctx context.Context, client *LazyClient, topic string, expected Topic) error {
-	timeout := time.Duration(client.Config.Timeout) * time.Second
+func waitForTopicRefresh(ctx context.Context, client *LazyClient, topic string, expected Topic, timeout time.Duration) error {
 	stateConf := &retry.StateChangeConf{
 		Pending:      []string{"Updating"},
 		Target:       []string{"Ready"},
@@ -204,17 +334,27 @@ func topicRefreshFunc(client *LazyClient, topic string, expected Topic) retry.St
 			return actual, "Ready", nil
 		}
 
-		return nil, fmt.Sprintf("%v != %v", strPtrMapToStrMap(actual.Config), strPtrMapToStrMap(expected.Config)), nil
+		return nil, fmt.Sprintf("%v != %v", strPtrMapToStrMap(actual.Config), maskedConfig(expected.Config, actual.Config)), nil
 	}
 }
 
 func topicDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// checked against the state, so that the flag has to be turned off by
+	// an apply of its own rather than together with the destroy
+	if d.Get("deletion_protection").(bool) {
+		return diag.Diagnostics{{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("topic %s has deletion_protection enabled", d.Id()),
+			Detail:   "Set deletion_protection to false and apply it before destroying or replacing the topic.",
+		}}
+	}
+
 	c := meta.(*LazyClient)
 	t := metaToTopic(d, meta)
 
-	err := c.DeleteTopic(t.Name)
+	err := c.DeleteTopic(ctx, t.Name)
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromErr(err)
 	}
 
 	log.Printf("[DEBUG] waiting for topic to delete? %s", t.Name)
@@ -222,14 +362,14 @@ func topicDelete(ctx context.Context, d *schema.ResourceData, meta interface{})
 		Pending:      []string{"Pending"},
 		Target:       []string{"Deleted"},
 		Refresh:      topicDeleteFunc(c, d.Id(), t),
-		Timeout:      300 * time.Second,
+		Timeout:      operationTimeout(d, schema.TimeoutDelete, 300*time.Second),
 		Delay:        3 * time.Second,
 		PollInterval: 2 * time.Second,
 		MinTimeout:   20 * time.Second,
 	}
 	_, err = stateConf.WaitForStateContext(ctx)
 	if err != nil {
-		return diag.FromErr(fmt.Errorf("error waiting for topic (%s) to delete: %s", d.Id(), err))
+		return diagFromErr(fmt.Errorf("error waiting for topic (%s) to delete: %s", d.Id(), err))
 	}
 
 	log.Printf("[DEBUG] deletetopic done! %s", t.Name)
@@ -266,7 +406,7 @@ func topicRead(ctx context.Context, d *schema.ResourceData, meta interface{}) di
 			return nil
 		}
 
-		return diag.FromErr(err)
+		return diagFromErr(err)
 	}
 
 	log.Printf("[DEBUG] Setting the state from Kafka %v", topic)
@@ -274,16 +414,148 @@ func topicRead(ctx context.Context, d *schema.ResourceData, meta interface{}) di
 	errSet.Set("name", topic.Name)
 	errSet.Set("partitions", topic.Partitions)
 	errSet.Set("replication_factor", topic.ReplicationFactor)
+	errSet.Set("replica_assignment", flattenReplicaAssignment(topic.ReplicaAssignment))
+
+	// Kafka never returns sensitive values, so those of config are kept as
+	// configured, those of sensitive_config are kept out of the other
+	// attributes, and any other is left out as its value cannot be known
+	configured := d.Get("config").(map[string]interface{})
+	managedSensitive := d.Get("sensitive_config").(map[string]interface{})
+	sensitive := map[string]string{}
+	for key, value := range topic.Config {
+		if m, ok := managedSensitive[key]; ok {
+			if value != nil {
+				sensitive[key] = *value
+			} else {
+				sensitive[key] = m.(string)
+			}
+			delete(topic.Config, key)
+			continue
+		}
+		if value != nil {
+			continue
+		}
+		if m, ok := configured[key]; ok {
+			v := m.(string)
+			topic.Config[key] = &v
+			continue
+		}
+		log.Printf("[DEBUG] Leaving out the sensitive config %s of topic %s", key, name)
+		delete(topic.Config, key)
+	}
+	errSet.Set("sensitive_config", sensitive)
+
+	errSet.Set("effective_config", topic.Config)
+
+	// throttled replicas set through the raw config stay there
+
+	// provider defaults are only part of config when the topic sets them
+	for key, value := range client.Config.DefaultTopicConfig {
+		if _, ok := configured[key]; ok {
+			continue
+		}
+		if v, ok := topic.Config[key]; ok && v != nil && *v == value {
+			delete(topic.Config, key)
+		}
+	}
+
+	for attr, key := range throttledReplicasAttributes {
+		if _, ok := configured[key]; ok {
+			continue
+		}
+
+		replicas := []string{}
+		if v, ok := topic.Config[key]; ok && v != nil {
+			replicas = parseThrottledReplicas(*v)
+			delete(topic.Config, key)
+		}
+		errSet.Set(attr, replicas)
+	}
+
+	// entries left to inherit the broker's default are kept as configured,
+	// unless the topic sets them again
+	for key, value := range configured {
+		if value != topicConfigInheritDefault {
+			continue
+		}
+		if _, ok := topic.Config[key]; !ok {
+			inherit := topicConfigInheritDefault
+			topic.Config[key] = &inherit
+		}
+	}
 	errSet.Set("config", topic.Config)
 
 	if errSet.err != nil {
-		return diag.FromErr(errSet.err)
+		return diagFromErr(errSet.err)
 	}
 
 	return nil
 }
 
+// importTopic reads the topic and its config from Kafka rather than the
+// cache, so the Read following the import records the complete config set
+// on the topic and the next plan is clean
+func importTopic(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	client := meta.(*LazyClient)
+	topic, err := client.ReadTopic(d.Id(), true)
+	if err != nil {
+		if _, ok := err.(TopicMissingError); ok {
+			return nil, fmt.Errorf("failed importing resource; topic %s could not be found", d.Id())
+		}
+		return nil, err
+	}
+
+	errSet := errSetter{d: d}
+	errSet.Set("name", topic.Name)
+	errSet.Set("adopt_existing", false)
+	errSet.Set("deletion_protection", false)
+	if errSet.err != nil {
+		return nil, errSet.err
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
 func customDiff(ctx context.Context, diff *schema.ResourceDiff, v interface{}) error {
+	if err := replicaAssignmentDiff(diff); err != nil {
+		return err
+	}
+
+	if err := effectiveConfigDiff(diff, v); err != nil {
+		return err
+	}
+
+	config := diff.Get("config").(map[string]interface{})
+	for key := range diff.Get("sensitive_config").(map[string]interface{}) {
+		if _, ok := config[key]; ok {
+			return fmt.Errorf("%s cannot be set in both config and sensitive_config", key)
+		}
+	}
+	for attr, key := range throttledReplicasAttributes {
+		replicas := setToStrings(diff.Get(attr).(*schema.Set))
+		if len(replicas) == 0 {
+			continue
+		}
+		if _, ok := config[key]; ok {
+			return fmt.Errorf("%s cannot be set in config when %s is set", key, attr)
+		}
+		if err := validateThrottledReplicas(replicas); err != nil {
+			return fmt.Errorf("%s: %w", attr, err)
+		}
+	}
+
+	if err := compressionTypeDiff(diff, v); err != nil {
+		return err
+	}
+
+	if err := minInsyncReplicasDiff(diff, v); err != nil {
+		return err
+	}
+
+	if err := validateOnlyDiff(diff, v); err != nil {
+		return err
+	}
+
 	// Skip custom logic for resource creation.
 	if diff.Id() == "" {
 		return nil
@@ -295,14 +567,25 @@ func customDiff(ctx context.Context, diff *schema.ResourceDiff, v interface{}) e
 		ni := n.(int)
 		log.Printf("[INFO] Partitions is changing from %d to %d", oi, ni)
 		if ni < oi {
-			log.Printf("Partitions decreased from %d to %d. Forcing new resource", oi, ni)
-			if err := diff.ForceNew("partitions"); err != nil {
-				return err
-			}
+			return fmt.Errorf("partitions cannot be decreased from %d to %d as Kafka does not support removing partitions; replace the topic (e.g. with `terraform apply -replace`) to reduce them", oi, ni)
 		}
 	}
 
-	if diff.HasChange("replication_factor") {
+	if diff.HasChange("replica_assignment") && replicaAssignmentConfigured(diff) {
+		client := v.(*LazyClient)
+
+		canAlterRF, err := client.CanAlterReplicationFactor()
+		if err != nil {
+			return err
+		}
+
+		if !canAlterRF {
+			log.Println("[INFO] Need Kafka >= 2.4.0 to update replica_assignment in-place")
+			if err := diff.ForceNew("replica_assignment"); err != nil {
+				return err
+			}
+		}
+	} else if diff.HasChange("replication_factor") {
 		log.Printf("[INFO] Checking the diff!")
 		client := v.(*LazyClient)
 
@@ -321,3 +604,147 @@ func customDiff(ctx context.Context, diff *schema.ResourceDiff, v interface{}) e
 
 	return nil
 }
+
+// compressionTypeDiff rejects a compression.type, set on the topic or in
+// default_topic_config, that the configured kafka_version does not support
+func compressionTypeDiff(diff *schema.ResourceDiff, v interface{}) error {
+	client, ok := v.(*LazyClient)
+	if !ok || client.Config == nil || !diff.NewValueKnown("config") {
+		return nil
+	}
+	version, err := client.Config.kafkaVersion()
+	if err != nil {
+		return err
+	}
+	return validateCompressionType(topicConfigFromResource(diff, client.Config.DefaultTopicConfig), version)
+}
+
+// minInsyncReplicasDiff rejects a min.insync.replicas, set on the topic or in
+// default_topic_config, that the topic's replication_factor cannot satisfy
+func minInsyncReplicasDiff(diff *schema.ResourceDiff, v interface{}) error {
+	if !diff.NewValueKnown("config") || !diff.NewValueKnown("replication_factor") {
+		return nil
+	}
+	rf := diff.Get("replication_factor").(int)
+	if rf == 0 {
+		// left to the broker's default, or derived from a replica_assignment
+		// that is not known yet
+		return nil
+	}
+
+	var defaults map[string]string
+	if c, ok := v.(*LazyClient); ok && c.Config != nil {
+		defaults = c.Config.DefaultTopicConfig
+	}
+	return validateMinInsyncReplicas(topicConfigFromResource(diff, defaults), rf)
+}
+
+// validateOnlyDiff has the brokers validate the creation of the topic, or
+// the change of its config, without applying it, when validate_only is set
+func validateOnlyDiff(diff *schema.ResourceDiff, v interface{}) error {
+	client, ok := v.(*LazyClient)
+	if !ok || client.Config == nil || !client.Config.ValidateOnly {
+		return nil
+	}
+	for _, key := range []string{"name", "partitions", "replication_factor", "replica_assignment", "config", "sensitive_config", "leader_replication_throttled_replicas", "follower_replication_throttled_replicas"} {
+		// partitions and replication_factor left out are unknown until the
+		// topic is created; validating with 0 applies the broker's defaults
+		if (key == "partitions" || key == "replication_factor") && diff.GetRawConfig().GetAttr(key).IsNull() {
+			continue
+		}
+		if !diff.NewValueKnown(key) {
+			log.Printf("[DEBUG] %s is not known yet, skipping validation of the topic", key)
+			return nil
+		}
+	}
+
+	t := metaToTopic(diff, v)
+	if diff.Id() == "" {
+		err := client.ValidateCreateTopic(t)
+		if errors.Is(err, sarama.ErrTopicAlreadyExists) && diff.Get("adopt_existing").(bool) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("kafka rejected the creation of topic %s: %w", t.Name, err)
+		}
+		return nil
+	}
+
+	if !diff.HasChanges("effective_config", "sensitive_config") {
+		return nil
+	}
+	if err := client.ValidateUpdateTopic(t); err != nil {
+		return fmt.Errorf("kafka rejected the config of topic %s: %w", t.Name, err)
+	}
+	return nil
+}
+
+// replicaAssignmentDiff validates a configured replica_assignment and derives
+// replication_factor from it. Without one, the assignment is left to Kafka and
+// is unknown until changes to the partitions or replication_factor are applied.
+func replicaAssignmentDiff(diff *schema.ResourceDiff) error {
+	if !replicaAssignmentConfigured(diff) {
+		if diff.Id() != "" && diff.HasChanges("partitions", "replication_factor") {
+			return diff.SetNewComputed("replica_assignment")
+		}
+		return nil
+	}
+
+	raw := diff.Get("replica_assignment").(*schema.Set).List()
+	assignment := expandReplicaAssignment(raw)
+	partitions := diff.Get("partitions").(int)
+	if diff.GetRawConfig().GetAttr("partitions").IsNull() {
+		partitions = len(assignment)
+		if diff.Get("partitions").(int) != partitions {
+			if err := diff.SetNew("partitions", partitions); err != nil {
+				return err
+			}
+		}
+	}
+	if err := validateReplicaAssignment(assignment, int32(partitions)); err != nil {
+		return err
+	}
+
+	rf := int(replicationFactorFromAssignment(assignment))
+	if diff.Get("replication_factor").(int) != rf {
+		return diff.SetNew("replication_factor", rf)
+	}
+
+	return nil
+}
+
+func replicaAssignmentConfigured(diff *schema.ResourceDiff) bool {
+	raw := diff.GetRawConfig().GetAttr("replica_assignment")
+	return raw.IsKnown() && !raw.IsNull() && raw.LengthInt() > 0
+}
+
+// effectiveConfigDiff plans effective_config as the merge of the provider's
+// default_topic_config and the topic's own config, without the entries of
+// sensitive_config
+func effectiveConfigDiff(diff *schema.ResourceDiff, v interface{}) error {
+	for _, key := range []string{"config", "sensitive_config", "leader_replication_throttled_replicas", "follower_replication_throttled_replicas"} {
+		if !diff.NewValueKnown(key) {
+			return diff.SetNewComputed("effective_config")
+		}
+	}
+
+	var defaults map[string]string
+	if c, ok := v.(*LazyClient); ok && c.Config != nil {
+		defaults = c.Config.DefaultTopicConfig
+	}
+	effective := strPtrMapToStrMap(topicConfigFromResource(diff, defaults))
+	for key := range diff.Get("sensitive_config").(map[string]interface{}) {
+		delete(effective, key)
+	}
+
+	current := map[string]string{}
+	for k, v := range diff.Get("effective_config").(map[string]interface{}) {
+		current[k] = v.(string)
+	}
+
+	if diff.Id() == "" || !reflect.DeepEqual(current, effective) {
+		return diff.SetNew("effective_config", effective)
+	}
+
+	return nil
+}
@@ -0,0 +1,149 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func kafkaTopicConfigResource() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: topicConfigCreate,
+		ReadContext:   topicConfigRead,
+		UpdateContext: topicConfigUpdate,
+		DeleteContext: topicConfigDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		Schema: map[string]*schema.Schema{
+			"topic": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The name of the existing topic to manage config entries of.",
+			},
+			"config": {
+				Type:         schema.TypeMap,
+				Required:     true,
+				Description:  "The config entries to set on the topic. Entries not listed are left as they are.",
+				Elem:         &schema.Schema{Type: schema.TypeString},
+				ValidateFunc: validateTopicConfigNotEmpty,
+			},
+		},
+	}
+}
+
+func topicConfigCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*LazyClient)
+	topic := d.Get("topic").(string)
+
+	set, _ := topicConfigChanges(nil, d.Get("config").(map[string]interface{}))
+	if err := c.AlterTopicConfig(ctx, topic, set, nil); err != nil {
+		return diagFromErr(err)
+	}
+
+	d.SetId(topic)
+	return topicConfigRead(ctx, d, meta)
+}
+
+func topicConfigUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*LazyClient)
+
+	if d.HasChange("config") {
+		o, n := d.GetChange("config")
+		set, remove := topicConfigChanges(o.(map[string]interface{}), n.(map[string]interface{}))
+		if err := c.AlterTopicConfig(ctx, d.Id(), set, remove); err != nil {
+			return diagFromErr(err)
+		}
+	}
+
+	return topicConfigRead(ctx, d, meta)
+}
+
+func topicConfigRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*LazyClient)
+	topic := d.Id()
+	log.Printf("[INFO] Reading config of topic %s", topic)
+
+	conf, err := c.TopicConfig(topic)
+	if err != nil {
+		if _, ok := err.(TopicMissingError); ok {
+			log.Printf("[WARN] Topic %s could not be found, removing its config from state", topic)
+			d.SetId("")
+			return nil
+		}
+		return diagFromErr(err)
+	}
+
+	// only the managed entries are recorded; on import, when none are
+	// known yet, every entry set on the topic is
+	managed := d.Get("config").(map[string]interface{})
+	config := map[string]string{}
+	for key, value := range conf {
+		if value == nil {
+			continue
+		}
+		if _, ok := managed[key]; ok || len(managed) == 0 {
+			config[key] = *value
+		}
+	}
+
+	errSet := errSetter{d: d}
+	errSet.Set("topic", topic)
+	errSet.Set("config", config)
+	if errSet.err != nil {
+		return diagFromErr(errSet.err)
+	}
+
+	return nil
+}
+
+func topicConfigDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*LazyClient)
+
+	// the topic stays; only the managed entries revert to the broker defaults
+	_, remove := topicConfigChanges(d.Get("config").(map[string]interface{}), nil)
+	if err := c.AlterTopicConfig(ctx, d.Id(), nil, remove); err != nil {
+		if _, ok := topicReadError(d.Id(), err).(TopicMissingError); !ok {
+			return diagFromErr(err)
+		}
+		log.Printf("[WARN] Topic %s no longer exists, nothing to reset", d.Id())
+	}
+
+	d.SetId("")
+	return nil
+}
+
+// topicConfigChanges returns the entries to set to go from the old to the
+// new config, and the sorted keys to remove
+func topicConfigChanges(old, new map[string]interface{}) (map[string]*string, []string) {
+	set := map[string]*string{}
+	for key, value := range new {
+		v := value.(string)
+		if o, ok := old[key]; ok && o.(string) == v {
+			continue
+		}
+		set[key] = &v
+	}
+
+	remove := []string{}
+	for key := range old {
+		if _, ok := new[key]; !ok {
+			remove = append(remove, key)
+		}
+	}
+	sort.Strings(remove)
+
+	return set, remove
+}
+
+func validateTopicConfigNotEmpty(v interface{}, key string) ([]string, []error) {
+	if len(v.(map[string]interface{})) == 0 {
+		return nil, []error{fmt.Errorf("%s must set at least one config entry", key)}
+	}
+	return nil, nil
+}
@@ -0,0 +1,120 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/IBM/sarama"
+	uuid "github.com/hashicorp/go-uuid"
+	r "github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func Test_topicConfigChanges(t *testing.T) {
+	old := map[string]interface{}{"retention.ms": "1000", "segment.ms": "2000", "cleanup.policy": "compact"}
+	new := map[string]interface{}{"retention.ms": "1000", "segment.ms": "3000", "max.message.bytes": "4096"}
+
+	set, remove := topicConfigChanges(old, new)
+	if !reflect.DeepEqual(map[string]string{"segment.ms": "3000", "max.message.bytes": "4096"}, strPtrMapToStrMap(set)) {
+		t.Errorf("unexpected entries to set %v", strPtrMapToStrMap(set))
+	}
+	if !reflect.DeepEqual([]string{"cleanup.policy"}, remove) {
+		t.Errorf("unexpected entries to remove %v", remove)
+	}
+
+	set, remove = topicConfigChanges(old, nil)
+	if len(set) != 0 || !reflect.DeepEqual([]string{"cleanup.policy", "retention.ms", "segment.ms"}, remove) {
+		t.Errorf("expected every entry to be removed, got %v and %v", strPtrMapToStrMap(set), remove)
+	}
+}
+
+func TestAcc_TopicConfig(t *testing.T) {
+	t.Parallel()
+	u, err := uuid.GenerateUUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	topicName := fmt.Sprintf("syslog-%s", u)
+	bs := testBootstrapServers[0]
+
+	r.Test(t, r.TestCase{
+		ProviderFactories: overrideProviderFactory(),
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccCreateUnmanagedTopic(t, topicName, map[string]string{"cleanup.policy": "compact"})
+		},
+		CheckDestroy: func(s *terraform.State) error { return testAccCheckTopicConfigDestroy(topicName) },
+		Steps: []r.TestStep{
+			{
+				Config: cfg(t, bs, fmt.Sprintf(testResourceTopicConfig, topicName, `"retention.ms" = "11111"`)),
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckResourceAttr("kafka_topic_config.test", "id", topicName),
+					r.TestCheckResourceAttr("kafka_topic_config.test", "config.%", "1"),
+					r.TestCheckResourceAttr("kafka_topic_config.test", "config.retention.ms", "11111"),
+					testAccCheckTopicConfig(topicName, map[string]string{"cleanup.policy": "compact", "retention.ms": "11111"}),
+				),
+			},
+			{
+				Config: cfg(t, bs, fmt.Sprintf(testResourceTopicConfig, topicName, `"segment.ms" = "22222"`)),
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckResourceAttr("kafka_topic_config.test", "config.%", "1"),
+					r.TestCheckResourceAttr("kafka_topic_config.test", "config.segment.ms", "22222"),
+					testAccCheckTopicConfig(topicName, map[string]string{"cleanup.policy": "compact", "segment.ms": "22222"}),
+				),
+			},
+		},
+	})
+}
+
+// testAccCreateUnmanagedTopic creates a topic outside of terraform, deleting
+// it when the test is done
+func testAccCreateUnmanagedTopic(t *testing.T, name string, config map[string]string) {
+	client := testProvider.Meta().(*LazyClient)
+	conf := map[string]*string{}
+	for k, v := range config {
+		v := v
+		conf[k] = &v
+	}
+
+	if err := client.CreateTopic(context.Background(), Topic{Name: name, Partitions: 1, ReplicationFactor: 1, Config: conf}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		// the test may have adopted the topic, and deleted it already
+		if err := client.DeleteTopic(context.Background(), name); err != nil && !errors.Is(err, sarama.ErrUnknownTopicOrPartition) {
+			t.Errorf("could not delete topic %s: %s", name, err)
+		}
+	})
+}
+
+func testAccCheckTopicConfig(topic string, expected map[string]string) r.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testProvider.Meta().(*LazyClient)
+		conf, err := client.TopicConfig(topic)
+		if err != nil {
+			return err
+		}
+		if actual := strPtrMapToStrMap(conf); !reflect.DeepEqual(expected, actual) {
+			return fmt.Errorf("expected the config of %s to be %v, got %v", topic, expected, actual)
+		}
+		return nil
+	}
+}
+
+// the topic outlives the resource, with only the managed entries reset
+func testAccCheckTopicConfigDestroy(topic string) error {
+	return testAccCheckTopicConfig(topic, map[string]string{"cleanup.policy": "compact"})(nil)
+}
+
+const testResourceTopicConfig = `
+resource "kafka_topic_config" "test" {
+  topic = "%s"
+
+  config = {
+    %s
+  }
+}
+`
@@ -1,8 +1,10 @@
 package kafka
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"regexp"
 	"strconv"
 	"testing"
 	"time"
@@ -61,6 +63,226 @@ func TestAcc_TopicConfigUpdate(t *testing.T) {
 	})
 }
 
+func TestAcc_TopicConfigInheritDefault(t *testing.T) {
+	t.Parallel()
+	u, err := uuid.GenerateUUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	topicName := fmt.Sprintf("syslog-%s", u)
+	bs := testBootstrapServers[0]
+
+	r.Test(t, r.TestCase{
+		ProviderFactories: overrideProviderFactory(),
+		PreCheck:          func() { testAccPreCheck(t) },
+		CheckDestroy:      testAccCheckTopicDestroy,
+		Steps: []r.TestStep{
+			{
+				Config: cfg(t, bs, fmt.Sprintf(testResourceTopic_initialConfig, topicName)),
+				Check:  testResourceTopic_initialCheck,
+			},
+			{
+				// the step fails if the inherited entry shows as drift
+				Config: cfg(t, bs, fmt.Sprintf(testResourceTopic_inheritDefault, topicName)),
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckResourceAttr("kafka_topic.test", "config.retention.ms", topicConfigInheritDefault),
+					r.TestCheckNoResourceAttr("kafka_topic.test", "effective_config.retention.ms"),
+					r.TestCheckResourceAttr("kafka_topic.test", "effective_config.segment.ms", "22222"),
+				),
+			},
+		},
+	})
+}
+
+func TestAcc_TopicImport(t *testing.T) {
+	t.Parallel()
+	u, err := uuid.GenerateUUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	topicName := fmt.Sprintf("syslog-%s", u)
+	bs := testBootstrapServers[0]
+
+	r.Test(t, r.TestCase{
+		ProviderFactories: overrideProviderFactory(),
+		PreCheck:          func() { testAccPreCheck(t) },
+		CheckDestroy:      testAccCheckTopicDestroy,
+		Steps: []r.TestStep{
+			{
+				Config: cfg(t, bs, fmt.Sprintf(testResourceTopic_initialConfig, topicName)),
+				Check:  testResourceTopic_initialCheck,
+			},
+			{
+				ResourceName:      "kafka_topic.test",
+				ImportState:       true,
+				ImportStateId:     topicName,
+				ImportStateVerify: true,
+				Config:            cfg(t, bs, fmt.Sprintf(testResourceTopic_initialConfig, topicName)),
+			},
+			{
+				Config:        cfg(t, bs, fmt.Sprintf(testResourceTopic_initialConfig+testResourceTopic_missing, topicName, topicName+"-missing")),
+				ResourceName:  "kafka_topic.missing",
+				ImportState:   true,
+				ImportStateId: topicName + "-missing",
+				ExpectError:   regexp.MustCompile("could not be found"),
+			},
+		},
+	})
+}
+
+func TestAcc_TopicAdoptExisting(t *testing.T) {
+	t.Parallel()
+	u, err := uuid.GenerateUUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	topicName := fmt.Sprintf("syslog-%s", u)
+	bs := testBootstrapServers[0]
+
+	r.Test(t, r.TestCase{
+		ProviderFactories: overrideProviderFactory(),
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccCreateUnmanagedTopic(t, topicName, map[string]string{"retention.ms": "11111", "segment.ms": "22222"})
+		},
+		CheckDestroy: testAccCheckTopicDestroy,
+		Steps: []r.TestStep{
+			{
+				Config:      cfg(t, bs, fmt.Sprintf(testResourceTopic_adoptExisting, topicName, 2)),
+				ExpectError: regexp.MustCompile("cannot be adopted: partitions is 1, not 2"),
+			},
+			{
+				Config: cfg(t, bs, fmt.Sprintf(testResourceTopic_adoptExisting, topicName, 1)),
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckResourceAttr("kafka_topic.test", "id", topicName),
+					r.TestCheckResourceAttr("kafka_topic.test", "adopt_existing", "true"),
+					testResourceTopic_initialCheck,
+				),
+			},
+		},
+	})
+}
+
+func TestAcc_TopicDeletionProtection(t *testing.T) {
+	t.Parallel()
+	u, err := uuid.GenerateUUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	topicName := fmt.Sprintf("syslog-%s", u)
+	bs := testBootstrapServers[0]
+
+	r.Test(t, r.TestCase{
+		ProviderFactories: overrideProviderFactory(),
+		PreCheck:          func() { testAccPreCheck(t) },
+		CheckDestroy:      testAccCheckTopicDestroy,
+		Steps: []r.TestStep{
+			{
+				Config: cfg(t, bs, fmt.Sprintf(testResourceTopic_deletionProtection, topicName, true)),
+				Check:  r.TestCheckResourceAttr("kafka_topic.test", "deletion_protection", "true"),
+			},
+			{
+				Config:      cfg(t, bs, fmt.Sprintf(testResourceTopic_deletionProtection, topicName, true)),
+				Destroy:     true,
+				ExpectError: regexp.MustCompile("has deletion_protection enabled"),
+			},
+			{
+				Config: cfg(t, bs, fmt.Sprintf(testResourceTopic_deletionProtection, topicName, false)),
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckResourceAttr("kafka_topic.test", "deletion_protection", "false"),
+					testResourceTopic_initialCheck,
+				),
+			},
+		},
+	})
+}
+
+func TestAcc_TopicValidateOnly(t *testing.T) {
+	t.Parallel()
+	u, err := uuid.GenerateUUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	topicName := fmt.Sprintf("syslog-%s", u)
+	bs := testBootstrapServers[0]
+
+	r.Test(t, r.TestCase{
+		ProviderFactories: overrideProviderFactory(),
+		PreCheck:          func() { testAccPreCheck(t) },
+		CheckDestroy:      testAccCheckTopicDestroy,
+		Steps: []r.TestStep{
+			{
+				Config:      fmt.Sprintf(testResourceTopic_validateOnly, bs, topicName, "compacted"),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("kafka rejected the creation of topic"),
+			},
+			{
+				// validated with the broker's defaults
+				Config:      fmt.Sprintf(testResourceTopic_validateOnlyBrokerDefaults, bs, topicName, "compacted"),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("kafka rejected the creation of topic"),
+			},
+			{
+				Config: fmt.Sprintf(testResourceTopic_validateOnly, bs, topicName, "compact"),
+				Check:  r.TestCheckResourceAttr("kafka_topic.test", "config.cleanup.policy", "compact"),
+			},
+			{
+				Config:      fmt.Sprintf(testResourceTopic_validateOnly, bs, topicName, "compacted"),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("kafka rejected the config of topic"),
+			},
+		},
+	})
+}
+
+func TestAcc_TopicBrokerDefaults(t *testing.T) {
+	t.Parallel()
+	u, err := uuid.GenerateUUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	topicName := fmt.Sprintf("syslog-%s", u)
+	bs := testBootstrapServers[0]
+
+	r.Test(t, r.TestCase{
+		ProviderFactories: overrideProviderFactory(),
+		PreCheck:          func() { testAccPreCheck(t) },
+		CheckDestroy:      testAccCheckTopicDestroy,
+		Steps: []r.TestStep{
+			{
+				// the test cluster keeps Kafka's defaults of 1 for both
+				Config: cfg(t, bs, fmt.Sprintf(testResourceTopic_brokerDefaults, topicName)),
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckResourceAttr("kafka_topic.test", "partitions", "1"),
+					r.TestCheckResourceAttr("kafka_topic.test", "replication_factor", "1"),
+				),
+			},
+		},
+	})
+}
+
+func TestAcc_TopicMinInsyncReplicas(t *testing.T) {
+	t.Parallel()
+	u, err := uuid.GenerateUUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	topicName := fmt.Sprintf("syslog-%s", u)
+	bs := testBootstrapServers[0]
+
+	r.Test(t, r.TestCase{
+		ProviderFactories: overrideProviderFactory(),
+		PreCheck:          func() { testAccPreCheck(t) },
+		Steps: []r.TestStep{
+			{
+				Config:      cfg(t, bs, fmt.Sprintf(testResourceTopic_minInsyncReplicas, topicName)),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("min.insync.replicas 2 is higher than the replication_factor 1"),
+			},
+		},
+	})
+}
+
 func testAccCheckTopicDestroy(s *terraform.State) error {
 	resourceState := s.Modules[0].Resources["kafka_topic.test"]
 	if resourceState == nil {
@@ -110,6 +332,11 @@ func TestAcc_TopicUpdatePartitions(t *testing.T) {
 				Config: cfg(t, bs, fmt.Sprintf(testResourceTopic_updatePartitions, topicName)),
 				Check:  testResourceTopic_updatePartitionsCheck,
 			},
+			{
+				Config:      cfg(t, bs, fmt.Sprintf(testResourceTopic_initialConfig, topicName)),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("partitions cannot be decreased"),
+			},
 		},
 	})
 }
@@ -175,6 +402,196 @@ func TestAcc_TopicAlterReplicationFactor(t *testing.T) {
 	})
 }
 
+func TestAcc_TopicReplicaAssignment(t *testing.T) {
+	t.Parallel()
+	u, err := uuid.GenerateUUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	topicName := fmt.Sprintf("syslog-%s", u)
+	bs := testBootstrapServers[0]
+
+	r.Test(t, r.TestCase{
+		ProviderFactories: overrideProviderFactory(),
+		PreCheck:          func() { testAccPreCheck(t) },
+		CheckDestroy:      testAccCheckTopicDestroy,
+		Steps: []r.TestStep{
+			{
+				Config: cfg(t, bs, fmt.Sprintf(testResourceTopic_replicaAssignment, topicName, "1, 2", "2, 3")),
+				Check: testResourceTopic_replicaAssignmentCheck(map[int32][]int32{
+					0: {1, 2},
+					1: {2, 3},
+				}),
+			},
+			{
+				Config: cfg(t, bs, fmt.Sprintf(testResourceTopic_replicaAssignment, topicName, "3, 1", "2, 3")),
+				Check: testResourceTopic_replicaAssignmentCheck(map[int32][]int32{
+					0: {3, 1},
+					1: {2, 3},
+				}),
+			},
+		},
+	})
+}
+
+func testResourceTopic_replicaAssignmentCheck(expected map[int32][]int32) r.TestCheckFunc {
+	return func(s *terraform.State) error {
+		resourceState := s.Modules[0].Resources["kafka_topic.test"]
+		instanceState := resourceState.Primary
+
+		client := testProvider.Meta().(*LazyClient)
+		topic, err := client.ReadTopic(instanceState.Attributes["name"], true)
+		if err != nil {
+			return err
+		}
+
+		if !replicaAssignmentEq(expected, topic.ReplicaAssignment) {
+			return fmt.Errorf("expected replica assignment %v, but got %v", expected, topic.ReplicaAssignment)
+		}
+
+		if actual := instanceState.Attributes["replication_factor"]; actual != "2" {
+			return fmt.Errorf("expected replication_factor of 2 in state, but got %s", actual)
+		}
+
+		return nil
+	}
+}
+
+func TestAcc_TopicThrottledReplicas(t *testing.T) {
+	t.Parallel()
+	u, err := uuid.GenerateUUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	topicName := fmt.Sprintf("syslog-%s", u)
+	bs := testBootstrapServers[0]
+
+	r.Test(t, r.TestCase{
+		ProviderFactories: overrideProviderFactory(),
+		PreCheck:          func() { testAccPreCheck(t) },
+		CheckDestroy:      testAccCheckTopicDestroy,
+		Steps: []r.TestStep{
+			{
+				Config: cfg(t, bs, fmt.Sprintf(testResourceTopic_throttledReplicas, topicName, testResourceTopic_throttledReplicasAttributes)),
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckResourceAttr("kafka_topic.test", "leader_replication_throttled_replicas.#", "2"),
+					r.TestCheckResourceAttr("kafka_topic.test", "follower_replication_throttled_replicas.#", "1"),
+					r.TestCheckNoResourceAttr("kafka_topic.test", "config.leader.replication.throttled.replicas"),
+					testResourceTopic_configCheck(leaderThrottledReplicasConfig, "0:1,1:2"),
+					testResourceTopic_configCheck(followerThrottledReplicasConfig, "*"),
+				),
+			},
+			{
+				Config: cfg(t, bs, fmt.Sprintf(testResourceTopic_throttledReplicas, topicName, "")),
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckResourceAttr("kafka_topic.test", "leader_replication_throttled_replicas.#", "0"),
+					testResourceTopic_configCheck(leaderThrottledReplicasConfig, ""),
+				),
+			},
+		},
+	})
+}
+
+// testResourceTopic_configCheck checks the topic config in Kafka. An empty
+// value checks that the key is not set.
+func Test_topicUpdate_OnlyAltersWhatChanged(t *testing.T) {
+	mb := sarama.NewMockBroker(t, 1)
+	defer mb.Close()
+	mb.SetHandlerByMap(map[string]sarama.MockResponse{
+		"ApiVersionsRequest": sarama.NewMockApiVersionsResponse(t),
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(mb.Addr(), mb.BrokerID()).
+			SetController(mb.BrokerID()).
+			SetLeader("syslog", 0, mb.BrokerID()).
+			SetLeader("syslog", 1, mb.BrokerID()),
+		"DescribeConfigsRequest": sarama.NewMockWrapper(&sarama.DescribeConfigsResponse{
+			Version: 2,
+			Resources: []*sarama.ResourceResponse{
+				{
+					Type:    sarama.TopicResource,
+					Name:    "syslog",
+					Configs: []*sarama.ConfigEntry{{Name: "retention.ms", Value: "2000", Source: sarama.SourceTopic}},
+				},
+			},
+		}),
+		"AlterConfigsRequest":     sarama.NewMockAlterConfigsResponse(t),
+		"CreatePartitionsRequest": sarama.NewMockCreatePartitionsResponse(t),
+	})
+
+	meta := &LazyClient{Config: &Config{
+		BootstrapServers: &[]string{mb.Addr()},
+		Timeout:          5,
+	}}
+	resource := kafkaTopicResource()
+	apply := func(partitions, retention string, diff map[string]*terraform.ResourceAttrDiff) (alters []*sarama.AlterConfigsRequest, creates []*sarama.CreatePartitionsRequest) {
+		seen := len(mb.History())
+		state := &terraform.InstanceState{
+			ID: "syslog",
+			Attributes: map[string]string{
+				"id":                  "syslog",
+				"name":                "syslog",
+				"partitions":          partitions,
+				"replication_factor":  "1",
+				"config.%":            "1",
+				"config.retention.ms": retention,
+			},
+		}
+		_, diags := resource.Apply(context.Background(), state, &terraform.InstanceDiff{Attributes: diff}, meta)
+		if diags.HasError() {
+			t.Fatalf("unexpected error updating the topic: %v", diags)
+		}
+		for _, rr := range mb.History()[seen:] {
+			switch req := rr.Request.(type) {
+			case *sarama.AlterConfigsRequest:
+				alters = append(alters, req)
+			case *sarama.CreatePartitionsRequest:
+				creates = append(creates, req)
+			}
+		}
+		return alters, creates
+	}
+
+	// a config change only alters the config
+	alters, creates := apply("2", "1000", map[string]*terraform.ResourceAttrDiff{
+		"config.retention.ms": {Old: "1000", New: "2000"},
+	})
+	assertEquals(t, 1, len(alters))
+	assertEquals(t, 0, len(creates))
+	assertEquals(t, "syslog", alters[0].Resources[0].Name)
+	assertEquals(t, "2000", *alters[0].Resources[0].ConfigEntries["retention.ms"])
+
+	// a partitions change only adds the partitions
+	alters, creates = apply("1", "2000", map[string]*terraform.ResourceAttrDiff{
+		"partitions": {Old: "1", New: "2"},
+	})
+	assertEquals(t, 0, len(alters))
+	assertEquals(t, 1, len(creates))
+	assertEquals(t, int32(2), creates[0].TopicPartitions["syslog"].Count)
+}
+
+func testResourceTopic_configCheck(key string, expected string) r.TestCheckFunc {
+	return func(s *terraform.State) error {
+		instanceState := s.Modules[0].Resources["kafka_topic.test"].Primary
+		client := testProvider.Meta().(*LazyClient)
+		topic, err := client.ReadTopic(instanceState.Attributes["name"], true)
+		if err != nil {
+			return err
+		}
+
+		v, ok := topic.Config[key]
+		if expected == "" {
+			if ok {
+				return fmt.Errorf("expected %s to be unset, but got %s", key, *v)
+			}
+			return nil
+		}
+		if !ok || v == nil || *v != expected {
+			return fmt.Errorf("expected %s to be %s, but got %v", key, expected, strPtrMapToStrMap(topic.Config)[key])
+		}
+		return nil
+	}
+}
+
 func testResourceTopic_noConfigCheck(s *terraform.State) error {
 	resourceState := s.Modules[0].Resources["kafka_topic.test"]
 	if resourceState == nil {
@@ -498,6 +915,14 @@ resource "kafka_topic" "test" {
 }
 `
 
+const testResourceTopic_missing = `
+resource "kafka_topic" "missing" {
+  name               = "%s"
+  replication_factor = 1
+  partitions         = 1
+}
+`
+
 const testResourceTopic_initialConfig = `
 resource "kafka_topic" "test" {
   name               = "%s"
@@ -511,6 +936,79 @@ resource "kafka_topic" "test" {
 }
 `
 
+const testResourceTopic_inheritDefault = `
+resource "kafka_topic" "test" {
+  name               = "%s"
+  replication_factor = 1
+  partitions         = 1
+
+  config = {
+    "retention.ms" = "inherit"
+    "segment.ms" = "22222"
+  }
+}
+`
+
+const testResourceTopic_deletionProtection = `
+resource "kafka_topic" "test" {
+  name                = "%s"
+  replication_factor  = 1
+  partitions          = 1
+  deletion_protection = %t
+
+  config = {
+    "retention.ms" = "11111"
+    "segment.ms" = "22222"
+  }
+}
+`
+
+const testResourceTopic_adoptExisting = `
+resource "kafka_topic" "test" {
+  name               = "%s"
+  replication_factor = 1
+  partitions         = %d
+  adopt_existing     = true
+
+  config = {
+    "retention.ms" = "11111"
+    "segment.ms" = "22222"
+  }
+}
+`
+
+const testResourceTopic_validateOnly = `
+provider "kafka" {
+  bootstrap_servers = ["%s"]
+  validate_only     = true
+}
+
+resource "kafka_topic" "test" {
+  name               = "%s"
+  replication_factor = 1
+  partitions         = 1
+
+  config = {
+    "cleanup.policy" = "%s"
+  }
+}
+`
+
+const testResourceTopic_validateOnlyBrokerDefaults = `
+provider "kafka" {
+  bootstrap_servers = ["%s"]
+  validate_only     = true
+}
+
+resource "kafka_topic" "test" {
+  name = "%s"
+
+  config = {
+    "cleanup.policy" = "%s"
+  }
+}
+`
+
 const testResourceTopic_updateConfig = `
 resource "kafka_topic" "test" {
   name               = "%s"
@@ -537,6 +1035,37 @@ resource "kafka_topic" "test" {
 }
 `
 
+const testResourceTopic_throttledReplicas = `
+resource "kafka_topic" "test" {
+  name               = "%s"
+  replication_factor = 1
+  partitions         = 2
+%s
+}
+`
+
+const testResourceTopic_throttledReplicasAttributes = `
+  leader_replication_throttled_replicas   = ["1:2", "0:1"]
+  follower_replication_throttled_replicas = ["*"]
+`
+
+const testResourceTopic_replicaAssignment = `
+resource "kafka_topic" "test" {
+  name       = "%s"
+  partitions = 2
+
+  replica_assignment {
+    partition = 0
+    replicas  = [%s]
+  }
+
+  replica_assignment {
+    partition = 1
+    replicas  = [%s]
+  }
+}
+`
+
 const testResourceTopic_updateRF = `
 resource "kafka_topic" "test" {
   name               = "%s"
@@ -549,3 +1078,21 @@ resource "kafka_topic" "test" {
   }
 }
 `
+
+const testResourceTopic_minInsyncReplicas = `
+resource "kafka_topic" "test" {
+  name               = "%s"
+  replication_factor = 1
+  partitions         = 1
+
+  config = {
+    "min.insync.replicas" = "2"
+  }
+}
+`
+
+const testResourceTopic_brokerDefaults = `
+resource "kafka_topic" "test" {
+  name = "%s"
+}
+`
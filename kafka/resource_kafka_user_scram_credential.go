@@ -2,6 +2,7 @@ package kafka
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
 	"log"
 	"strings"
@@ -54,6 +55,21 @@ func kafkaUserScramCredentialResource() *schema.Resource {
 				Description:  "The password of the credential",
 				Sensitive:    true,
 			},
+			"salt": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Sensitive:     true,
+				ConflictsWith: []string{"salt_length"},
+				ValidateFunc:  validateScramSalt,
+				Description:   fmt.Sprintf("The base64 encoded salt to generate the credential with, of %d to %d bytes. A random salt is generated if not set.", scramSaltMinLength, scramSaltMaxLength),
+			},
+			"salt_length": {
+				Type:          schema.TypeInt,
+				Optional:      true,
+				ConflictsWith: []string{"salt"},
+				ValidateFunc:  validation.IntBetween(scramSaltMinLength, scramSaltMaxLength),
+				Description:   fmt.Sprintf("The length in bytes of the random salt to generate the credential with. Defaults to %d.", saltSize),
+			},
 		},
 	}
 }
@@ -68,6 +84,8 @@ func importSCRAM(ctx context.Context, d *schema.ResourceData, m interface{}) ([]
 		if errSet.err != nil {
 			return nil, errSet.err
 		}
+		// the ID leaves out the password, as for created credentials
+		d.SetId(UserScramCredential{Name: parts[0], Mechanism: convertedScramMechanism(parts[1])}.ID())
 	} else {
 		return nil, fmt.Errorf("failed importing resource; expected format is username|scram_mechanism|password - got %v segments instead of 3", len(parts))
 	}
@@ -80,10 +98,10 @@ func userScramCredentialCreate(ctx context.Context, d *schema.ResourceData, meta
 	c := meta.(*LazyClient)
 	userScramCredential := parseUserScramCredential(d)
 
-	err := c.UpsertUserScramCredential(userScramCredential)
+	err := c.UpsertUserScramCredential(ctx, userScramCredential)
 	if err != nil {
 		log.Println("[ERROR] Failed to create user scram credential")
-		return diag.FromErr(err)
+		return diagFromErr(err)
 	}
 
 	d.SetId(userScramCredential.ID())
@@ -105,7 +123,7 @@ func userScramCredentialRead(ctx context.Context, d *schema.ResourceData, meta i
 			return nil
 		}
 
-		return diag.FromErr(err)
+		return diagFromErr(err)
 	}
 
 	log.Printf("[DEBUG] Setting the state from Kafka %v", userScramCredential)
@@ -116,7 +134,7 @@ func userScramCredentialRead(ctx context.Context, d *schema.ResourceData, meta i
 	errSet.Set("scram_iterations", userScramCredential.Iterations)
 
 	if errSet.err != nil {
-		return diag.FromErr(errSet.err)
+		return diagFromErr(errSet.err)
 	}
 
 	return nil
@@ -127,10 +145,10 @@ func userScramCredentialUpdate(ctx context.Context, d *schema.ResourceData, meta
 	c := meta.(*LazyClient)
 	userScramCredential := parseUserScramCredential(d)
 
-	err := c.UpsertUserScramCredential(userScramCredential)
+	err := c.UpsertUserScramCredential(ctx, userScramCredential)
 	if err != nil {
 		log.Println("[ERROR] Failed to update user scram credential")
-		return diag.FromErr(err)
+		return diagFromErr(err)
 	}
 
 	return nil
@@ -141,10 +159,10 @@ func userScramCredentialDelete(ctx context.Context, d *schema.ResourceData, meta
 	c := meta.(*LazyClient)
 	userScramCredential := parseUserScramCredential(d)
 
-	err := c.DeleteUserScramCredential(userScramCredential)
+	err := c.DeleteUserScramCredential(ctx, userScramCredential)
 	if err != nil {
 		log.Println("[ERROR] Failed to delete user scram credential")
-		return diag.FromErr(err)
+		return diagFromErr(err)
 	}
 
 	return nil
@@ -153,12 +171,27 @@ func userScramCredentialDelete(ctx context.Context, d *schema.ResourceData, meta
 func parseUserScramCredential(d *schema.ResourceData) UserScramCredential {
 	scram_mechanism_string := d.Get("scram_mechanism").(string)
 	mechanism := convertedScramMechanism(scram_mechanism_string)
+	// the salt was validated, so it decodes
+	salt, _ := base64.StdEncoding.DecodeString(d.Get("salt").(string))
 	return UserScramCredential{
 		Name:       d.Get("username").(string),
 		Mechanism:  mechanism,
 		Iterations: int32(d.Get("scram_iterations").(int)),
 		Password:   []byte(d.Get("password").(string)),
+		Salt:       salt,
+		SaltLength: d.Get("salt_length").(int),
+	}
+}
+
+func validateScramSalt(v interface{}, key string) ([]string, []error) {
+	salt, err := base64.StdEncoding.DecodeString(v.(string))
+	if err != nil {
+		return nil, []error{fmt.Errorf("%s is not base64 encoded: %w", key, err)}
+	}
+	if len(salt) < scramSaltMinLength || len(salt) > scramSaltMaxLength {
+		return nil, []error{fmt.Errorf("%s must be %d to %d bytes, got %d", key, scramSaltMinLength, scramSaltMaxLength, len(salt))}
 	}
+	return nil, nil
 }
 
 func convertedScramMechanism(scram_mechanism_string string) sarama.ScramMechanismType {
@@ -107,6 +107,102 @@ func TestAcc_UserScramCredentialConfigUpdate(t *testing.T) {
 	})
 }
 
+func TestAcc_UserScramCredentialRotation(t *testing.T) {
+	t.Parallel()
+	u, err := uuid.GenerateUUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	username := fmt.Sprintf("test-%s", u)
+	bs := testBootstrapServers[0]
+
+	r.Test(t, r.TestCase{
+		ProviderFactories: overrideProviderFactory(),
+		PreCheck:          func() { testAccPreCheck(t) },
+		CheckDestroy:      testAccCheckUserScramCredentialMechanisms(username),
+		Steps: []r.TestStep{
+			{
+				Config: cfgs(t, bs, fmt.Sprintf(testResourceUserScramCredential_SHA256, username)),
+				Check:  testAccCheckUserScramCredentialMechanisms(username, "SCRAM-SHA-256"),
+			},
+			{
+				Config: cfgs(t, bs, fmt.Sprintf(testResourceUserScramCredential_SHA256, username)+fmt.Sprintf(testResourceUserScramCredential_rotated, username)),
+				Check:  testAccCheckUserScramCredentialMechanisms(username, "SCRAM-SHA-256", "SCRAM-SHA-512"),
+			},
+			{
+				ResourceName:            "kafka_user_scram_credential.rotated",
+				ImportState:             true,
+				ImportStateId:           username + "|SCRAM-SHA-512|test",
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"password"},
+			},
+			{
+				// removing the old mechanism leaves the new one
+				Config: cfgs(t, bs, fmt.Sprintf(testResourceUserScramCredential_rotated, username)),
+				Check:  testAccCheckUserScramCredentialMechanisms(username, "SCRAM-SHA-512"),
+			},
+		},
+	})
+}
+
+func TestAcc_UserScramCredentialSalt(t *testing.T) {
+	t.Parallel()
+	u, err := uuid.GenerateUUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	username := fmt.Sprintf("test-%s", u)
+	bs := testBootstrapServers[0]
+
+	r.Test(t, r.TestCase{
+		ProviderFactories: overrideProviderFactory(),
+		PreCheck:          func() { testAccPreCheck(t) },
+		CheckDestroy:      testAccCheckUserScramCredentialDestroy,
+		Steps: []r.TestStep{
+			{
+				Config: cfg(t, bs, fmt.Sprintf(testResourceUserScramCredential_WithSalt, username, `salt = "Zml4ZWQtc2FsdA=="`)),
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckResourceAttr("kafka_user_scram_credential.test", "salt", "Zml4ZWQtc2FsdA=="),
+					testAccCheckUserScramCredentialMechanisms(username, "SCRAM-SHA-256"),
+				),
+			},
+			{
+				// a new salt updates the credential
+				Config: cfg(t, bs, fmt.Sprintf(testResourceUserScramCredential_WithSalt, username, `salt = "cm90YXRlZC1zYWx0"`)),
+				Check:  r.TestCheckResourceAttr("kafka_user_scram_credential.test", "salt", "cm90YXRlZC1zYWx0"),
+			},
+			{
+				Config: cfg(t, bs, fmt.Sprintf(testResourceUserScramCredential_WithSalt, username, `salt_length = 32`)),
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckNoResourceAttr("kafka_user_scram_credential.test", "salt"),
+					r.TestCheckResourceAttr("kafka_user_scram_credential.test", "salt_length", "32"),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckUserScramCredentialMechanisms(username string, expected ...string) r.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testProvider.Meta().(*LazyClient)
+		credentials, err := client.DescribeUserScramCredentials(username)
+		if _, ok := err.(UserScramCredentialMissingError); err != nil && !ok {
+			return err
+		}
+
+		mechanisms := make([]string, len(credentials))
+		for i, c := range credentials {
+			mechanisms[i] = c.Mechanism.String()
+		}
+		if strings.Join(mechanisms, ",") != strings.Join(expected, ",") {
+			return fmt.Errorf("expected %s to have credentials for %v, got %v", username, expected, mechanisms)
+		}
+		return nil
+	}
+}
+
 func testResourceUserScramCredentialCheck_withoutIterations(s *terraform.State) error {
 	return testResourceUserScramCredentialCheck(s, false)
 }
@@ -205,6 +301,14 @@ resource "kafka_user_scram_credential" "test" {
   password               = "test"
 }
 `
+const testResourceUserScramCredential_rotated = `
+resource "kafka_user_scram_credential" "rotated" {
+  username               = "%s"
+  scram_mechanism        = "SCRAM-SHA-512"
+  password               = "test"
+}
+`
+
 const testResourceUserScramCredential_WithIterations = `
 resource "kafka_user_scram_credential" "test" {
   username               = "%s"
@@ -213,3 +317,12 @@ resource "kafka_user_scram_credential" "test" {
   password               = "test"
 }
 `
+
+const testResourceUserScramCredential_WithSalt = `
+resource "kafka_user_scram_credential" "test" {
+  username               = "%s"
+  scram_mechanism        = "SCRAM-SHA-256"
+  password               = "test"
+  %s
+}
+`
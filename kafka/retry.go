@@ -0,0 +1,119 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+const (
+	defaultRetryMaxRetries     = 3
+	defaultRetryMaxElapsedTime = 60
+	retryInitialBackoff        = 250 * time.Millisecond
+	retryMaxBackoff            = 10 * time.Second
+
+	// sarama's own defaults for the retries of the cluster admin
+	defaultAdminRetryMax     = 5
+	defaultAdminRetryBackoff = 100
+)
+
+// retriableKafkaErrors are returned by brokers while leadership or group
+// coordination moves, e.g. during a rolling restart
+var retriableKafkaErrors = []sarama.KError{
+	sarama.ErrNotController,
+	sarama.ErrLeaderNotAvailable,
+	sarama.ErrNotLeaderForPartition,
+	sarama.ErrRequestTimedOut,
+	sarama.ErrNetworkException,
+	sarama.ErrOffsetsLoadInProgress,
+	sarama.ErrConsumerCoordinatorNotAvailable,
+	sarama.ErrNotCoordinatorForConsumer,
+	sarama.ErrKafkaStorageError,
+}
+
+func isRetriableKafkaError(err error) bool {
+	return isAnyKafkaError(err, retriableKafkaErrors)
+}
+
+// isRetriableCreateError leaves out timeouts: a create that timed out may
+// still have been applied, and sending it again would then fail
+func isRetriableCreateError(err error) bool {
+	return isRetriableKafkaError(err) && !errors.Is(err, sarama.ErrRequestTimedOut)
+}
+
+// retryPolicy retries operations failing with a retriable Kafka error,
+// doubling the wait between attempts
+type retryPolicy struct {
+	maxRetries     int
+	maxElapsedTime time.Duration
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	retriable      func(error) bool
+	sleep          func(context.Context, time.Duration) error
+	now            func() time.Time
+}
+
+func newRetryPolicy(c *Config) retryPolicy {
+	p := retryPolicy{
+		maxRetries:     defaultRetryMaxRetries,
+		maxElapsedTime: defaultRetryMaxElapsedTime * time.Second,
+		initialBackoff: retryInitialBackoff,
+		maxBackoff:     retryMaxBackoff,
+		retriable:      isRetriableKafkaError,
+		sleep:          sleepContext,
+		now:            time.Now,
+	}
+	if c == nil {
+		return p
+	}
+
+	p.maxRetries = c.RetryMaxRetries
+	if c.RetryMaxElapsedTime > 0 {
+		p.maxElapsedTime = time.Duration(c.RetryMaxElapsedTime) * time.Second
+	}
+	return p
+}
+
+func (p retryPolicy) do(ctx context.Context, op string, f func() error) error {
+	start := p.now()
+	backoff := p.initialBackoff
+
+	for attempt := 0; ; attempt++ {
+		err := f()
+		if err == nil || !p.retriable(err) {
+			return err
+		}
+
+		if attempt >= p.maxRetries || p.now().Sub(start)+backoff > p.maxElapsedTime {
+			log.Printf("[ERROR] %s failed after %d attempts: %s", op, attempt+1, err)
+			return err
+		}
+
+		log.Printf("[WARN] %s failed with retriable error, retrying in %s: %s", op, backoff, err)
+		if ctxErr := p.sleep(ctx, backoff); ctxErr != nil {
+			log.Printf("[ERROR] %s cancelled while waiting to retry: %s", op, ctxErr)
+			return fmt.Errorf("%w while waiting to retry: %w", ctxErr, err)
+		}
+
+		backoff *= 2
+		if backoff > p.maxBackoff {
+			backoff = p.maxBackoff
+		}
+	}
+}
+
+// sleepContext waits for d, or until ctx is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,149 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+// fakeClockPolicy returns a policy whose sleeps advance a fake clock instead
+// of waiting, recording each backoff
+func fakeClockPolicy(maxRetries int, maxElapsed time.Duration, sleeps *[]time.Duration) retryPolicy {
+	now := time.Unix(0, 0)
+	return retryPolicy{
+		maxRetries:     maxRetries,
+		maxElapsedTime: maxElapsed,
+		initialBackoff: time.Second,
+		maxBackoff:     4 * time.Second,
+		retriable:      isRetriableKafkaError,
+		sleep: func(_ context.Context, d time.Duration) error {
+			*sleeps = append(*sleeps, d)
+			now = now.Add(d)
+			return nil
+		},
+		now: func() time.Time { return now },
+	}
+}
+
+func Test_retryPolicy(t *testing.T) {
+	notController := fmt.Errorf("error creating topic: %w", sarama.ErrNotController)
+	invalid := fmt.Errorf("error creating topic: %w", sarama.ErrInvalidConfig)
+
+	for _, tc := range []struct {
+		name       string
+		maxRetries int
+		maxElapsed time.Duration
+		errs       []error
+		calls      int
+		sleeps     []time.Duration
+		err        error
+	}{
+		{
+			name:       "success",
+			maxRetries: 3,
+			maxElapsed: time.Minute,
+			errs:       []error{nil},
+			calls:      1,
+		},
+		{
+			name:       "retriable then success",
+			maxRetries: 3,
+			maxElapsed: time.Minute,
+			errs:       []error{notController, notController, nil},
+			calls:      3,
+			sleeps:     []time.Duration{time.Second, 2 * time.Second},
+		},
+		{
+			name:       "not retriable",
+			maxRetries: 3,
+			maxElapsed: time.Minute,
+			errs:       []error{invalid},
+			calls:      1,
+			err:        sarama.ErrInvalidConfig,
+		},
+		{
+			name:       "max retries",
+			maxRetries: 4,
+			maxElapsed: time.Minute,
+			errs:       []error{notController, notController, notController, notController, notController, nil},
+			calls:      5,
+			sleeps:     []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 4 * time.Second},
+			err:        sarama.ErrNotController,
+		},
+		{
+			name:       "retries disabled",
+			maxRetries: 0,
+			maxElapsed: time.Minute,
+			errs:       []error{notController, nil},
+			calls:      1,
+			err:        sarama.ErrNotController,
+		},
+		{
+			name:       "max elapsed time",
+			maxRetries: 10,
+			maxElapsed: 5 * time.Second,
+			errs:       []error{notController, notController, notController, nil},
+			calls:      3,
+			sleeps:     []time.Duration{time.Second, 2 * time.Second},
+			err:        sarama.ErrNotController,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var sleeps []time.Duration
+			calls := 0
+			err := fakeClockPolicy(tc.maxRetries, tc.maxElapsed, &sleeps).do(context.Background(), "Test", func() error {
+				err := tc.errs[calls]
+				calls++
+				return err
+			})
+
+			if !errors.Is(err, tc.err) || (err == nil) != (tc.err == nil) {
+				t.Errorf("expected error %v, got %v", tc.err, err)
+			}
+			if calls != tc.calls {
+				t.Errorf("expected %d calls, got %d", tc.calls, calls)
+			}
+			if fmt.Sprint(sleeps) != fmt.Sprint(tc.sleeps) {
+				t.Errorf("expected sleeps %v, got %v", tc.sleeps, sleeps)
+			}
+		})
+	}
+}
+
+func Test_newRetryPolicy(t *testing.T) {
+	p := newRetryPolicy(nil)
+	if p.maxRetries != defaultRetryMaxRetries || p.maxElapsedTime != defaultRetryMaxElapsedTime*time.Second {
+		t.Errorf("expected defaults for a nil config, got %d retries within %s", p.maxRetries, p.maxElapsedTime)
+	}
+
+	p = newRetryPolicy(&Config{RetryMaxRetries: 0, RetryMaxElapsedTime: 5})
+	if p.maxRetries != 0 || p.maxElapsedTime != 5*time.Second {
+		t.Errorf("expected 0 retries within 5s, got %d retries within %s", p.maxRetries, p.maxElapsedTime)
+	}
+}
+
+func Test_retryPolicy_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	p := newRetryPolicy(&Config{RetryMaxRetries: 3})
+	calls := 0
+	err := p.do(ctx, "Test", func() error {
+		calls++
+		return sarama.ErrNotController
+	})
+	assertEquals(t, 1, calls)
+	if !errors.Is(err, context.Canceled) || !errors.Is(err, sarama.ErrNotController) {
+		t.Errorf("expected the cancellation along with the last error, got %v", err)
+	}
+}
+
+func Test_isRetriableCreateError(t *testing.T) {
+	assertEquals(t, true, isRetriableCreateError(fmt.Errorf("error creating acl: %w", sarama.ErrNotController)))
+	assertEquals(t, false, isRetriableCreateError(fmt.Errorf("error creating acl: %w", sarama.ErrRequestTimedOut)))
+	assertEquals(t, true, isRetriableKafkaError(fmt.Errorf("error deleting acl: %w", sarama.ErrRequestTimedOut)))
+}
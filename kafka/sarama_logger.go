@@ -0,0 +1,63 @@
+package kafka
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/IBM/sarama"
+)
+
+// saramaLoggerOnce guards sarama's loggers, which are global and read by its
+// goroutines without synchronisation, so they are only ever installed once
+var saramaLoggerOnce sync.Once
+
+// installSaramaLogger routes sarama's logs to the TRACE log, masking the
+// secrets of config
+func installSaramaLogger(config *Config) {
+	saramaLoggerOnce.Do(func() {
+		logger := newSaramaLogger(config)
+		sarama.Logger = logger
+		sarama.DebugLogger = logger
+	})
+}
+
+// saramaLogger writes sarama's logs to the TRACE log, with the secrets of
+// the provider's config masked in case a message ever includes one
+type saramaLogger struct {
+	masker *strings.Replacer
+}
+
+func newSaramaLogger(config *Config) *saramaLogger {
+	var pairs []string
+	for _, secret := range []string{
+		config.SASLPassword,
+		config.SASLAWSSecretKey,
+		config.SASLAWSToken,
+		config.SASLAWSExternalId,
+		config.ClientCertKeyPassphrase,
+		config.ProxyPassword,
+	} {
+		if secret != "" {
+			pairs = append(pairs, secret, "*****")
+		}
+	}
+	return &saramaLogger{masker: strings.NewReplacer(pairs...)}
+}
+
+func (l *saramaLogger) Print(v ...interface{}) {
+	l.write(fmt.Sprint(v...))
+}
+
+func (l *saramaLogger) Printf(format string, v ...interface{}) {
+	l.write(fmt.Sprintf(format, v...))
+}
+
+func (l *saramaLogger) Println(v ...interface{}) {
+	l.write(fmt.Sprintln(v...))
+}
+
+func (l *saramaLogger) write(msg string) {
+	log.Printf("[TRACE] [sarama] %s", l.masker.Replace(strings.TrimSuffix(msg, "\n")))
+}
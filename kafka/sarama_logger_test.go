@@ -0,0 +1,32 @@
+package kafka
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+func Test_saramaLogger(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	log.SetFlags(0)
+	t.Cleanup(func() {
+		log.SetOutput(os.Stderr)
+		log.SetFlags(log.LstdFlags)
+	})
+
+	logger := newSaramaLogger(&Config{SASLPassword: "hunter2", ProxyPassword: "swordfish"})
+	logger.Printf("Connected to broker at %s (registered as #%d)\n", "localhost:9092", 1)
+	logger.Println("SASL authentication failed for hunter2")
+	logger.Print("proxy ", "swordfish")
+
+	expected := strings.Join([]string{
+		"[TRACE] [sarama] Connected to broker at localhost:9092 (registered as #1)",
+		"[TRACE] [sarama] SASL authentication failed for *****",
+		"[TRACE] [sarama] proxy *****",
+		"",
+	}, "\n")
+	assertEquals(t, expected, buf.String())
+}
@@ -0,0 +1,196 @@
+package kafka
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+// apiKeySaslHandshake is the key of the SaslHandshake API,
+// https://kafka.apache.org/protocol#The_Messages_SaslHandshake
+const apiKeySaslHandshake = 17
+
+// SASLMechanisms returns the SASL mechanisms the first reachable bootstrap
+// server advertises. Brokers answer a SaslHandshake before authentication,
+// so this works while the provider's credentials are wrong.
+func (c *LazyClient) SASLMechanisms() ([]string, error) {
+	if c.Config == nil || c.Config.BootstrapServers == nil {
+		return nil, errors.New("cannot list SASL mechanisms without bootstrap_servers")
+	}
+	kafkaConfig, err := c.probeKafkaConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	errs := []string{}
+	for _, addr := range *c.Config.BootstrapServers {
+		mechanisms, err := saslHandshake(addr, kafkaConfig, c.configuredSASLMechanism())
+		if err == nil {
+			return mechanisms, nil
+		}
+		errs = append(errs, fmt.Sprintf("%s (%s)", addr, pingErrorReason(err)))
+	}
+	return nil, fmt.Errorf("could not list the SASL mechanisms of any bootstrap server: %s", strings.Join(errs, ", "))
+}
+
+// configuredSASLMechanism returns the name of the SASL mechanism the
+// provider authenticates with, as brokers advertise it, e.g. OAUTHBEARER for
+// aws-iam. It is empty if SASL is disabled.
+func (c *LazyClient) configuredSASLMechanism() string {
+	kafkaConfig, err := c.Config.newKafkaConfig()
+	if err != nil || !kafkaConfig.Net.SASL.Enable {
+		return ""
+	}
+	if kafkaConfig.Net.SASL.Mechanism == "" {
+		// sarama's default
+		return sarama.SASLTypePlaintext
+	}
+	return string(kafkaConfig.Net.SASL.Mechanism)
+}
+
+// checkSASLMechanism fails if the brokers do not advertise the configured
+// SASL mechanism, which would otherwise fail authentication with a generic
+// error. When the mechanisms cannot be listed the check is skipped.
+func (c *LazyClient) checkSASLMechanism() error {
+	mechanism := c.configuredSASLMechanism()
+	if mechanism == "" {
+		return nil
+	}
+
+	mechanisms, err := c.SASLMechanisms()
+	if err != nil {
+		log.Printf("[WARN] Not checking the SASL mechanism: %s", err)
+		return nil
+	}
+	for _, m := range mechanisms {
+		if m == mechanism {
+			return nil
+		}
+	}
+	return fmt.Errorf("broker advertises [%s], but provider configured '%s'", strings.Join(mechanisms, ", "), c.Config.SASLMechanism)
+}
+
+// saslHandshake sends a SaslHandshake for the mechanism to the broker and
+// returns the mechanisms it has enabled, which it lists whether or not it
+// supports the one asked for. sarama only logs them, so the request is sent
+// on a connection of its own.
+func saslHandshake(addr string, kafkaConfig *sarama.Config, mechanism string) ([]string, error) {
+	conn, err := dialBroker(addr, kafkaConfig)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(kafkaConfig.Net.ReadTimeout + kafkaConfig.Net.WriteTimeout)); err != nil {
+		return nil, err
+	}
+
+	// request header v1 and SaslHandshake v1 body
+	clientID := kafkaConfig.ClientID
+	req := make([]byte, 0, 16+len(clientID)+len(mechanism))
+	req = binary.BigEndian.AppendUint16(req, apiKeySaslHandshake)
+	req = binary.BigEndian.AppendUint16(req, 1)
+	req = binary.BigEndian.AppendUint32(req, 0) // correlation ID
+	req = appendKafkaString(req, clientID)
+	req = appendKafkaString(req, mechanism)
+	if _, err := conn.Write(binary.BigEndian.AppendUint32(nil, uint32(len(req)))); err != nil {
+		return nil, err
+	}
+	if _, err := conn.Write(req); err != nil {
+		return nil, err
+	}
+
+	r := bufio.NewReader(conn)
+	var size, correlationID uint32
+	var errorCode int16
+	var count int32
+	if err := binary.Read(r, binary.BigEndian, &size); err != nil {
+		return nil, err
+	}
+	if err := binary.Read(r, binary.BigEndian, &correlationID); err != nil {
+		return nil, err
+	}
+	if err := binary.Read(r, binary.BigEndian, &errorCode); err != nil {
+		return nil, err
+	}
+	if err := binary.Read(r, binary.BigEndian, &count); err != nil {
+		return nil, err
+	}
+	if count < 0 || int64(count) > int64(size) {
+		return nil, fmt.Errorf("invalid SaslHandshake response with %d mechanisms", count)
+	}
+
+	mechanisms := make([]string, 0, count)
+	for i := int32(0); i < count; i++ {
+		var length int16
+		if err := binary.Read(r, binary.BigEndian, &length); err != nil {
+			return nil, err
+		}
+		if length < 0 {
+			return nil, errors.New("invalid SaslHandshake response with a null mechanism")
+		}
+		m := make([]byte, length)
+		if _, err := io.ReadFull(r, m); err != nil {
+			return nil, err
+		}
+		mechanisms = append(mechanisms, string(m))
+	}
+
+	kerr := sarama.KError(errorCode)
+	if kerr != sarama.ErrNoError && kerr != sarama.ErrUnsupportedSASLMechanism {
+		return nil, kerr
+	}
+	return mechanisms, nil
+}
+
+func appendKafkaString(b []byte, s string) []byte {
+	b = binary.BigEndian.AppendUint16(b, uint16(len(s)))
+	return append(b, s...)
+}
+
+// dialBroker connects to the broker as sarama would, through the proxy and
+// over TLS if configured
+func dialBroker(addr string, kafkaConfig *sarama.Config) (net.Conn, error) {
+	var conn net.Conn
+	var err error
+	if kafkaConfig.Net.Proxy.Enable {
+		conn, err = kafkaConfig.Net.Proxy.Dialer.Dial("tcp", addr)
+	} else {
+		conn, err = (&net.Dialer{Timeout: kafkaConfig.Net.DialTimeout}).Dial("tcp", addr)
+	}
+	if err != nil || !kafkaConfig.Net.TLS.Enable {
+		return conn, err
+	}
+
+	tlsConfig := &tls.Config{}
+	if kafkaConfig.Net.TLS.Config != nil {
+		tlsConfig = kafkaConfig.Net.TLS.Config.Clone()
+	}
+	if tlsConfig.ServerName == "" {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		tlsConfig.ServerName = host
+	}
+	tlsConn := tls.Client(conn, tlsConfig)
+	if err := tlsConn.SetDeadline(time.Now().Add(kafkaConfig.Net.DialTimeout)); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if err := tlsConn.Handshake(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return tlsConn, nil
+}
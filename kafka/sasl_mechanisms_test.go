@@ -0,0 +1,47 @@
+package kafka
+
+import (
+	"testing"
+
+	"github.com/IBM/sarama"
+)
+
+func Test_LazyClientSASLMechanisms(t *testing.T) {
+	mb := sarama.NewMockBroker(t, 1)
+	defer mb.Close()
+	mb.SetHandlerByMap(map[string]sarama.MockResponse{
+		"SaslHandshakeRequest": sarama.NewMockSaslHandshakeResponse(t).
+			SetEnabledMechanisms([]string{"SCRAM-SHA-512"}).
+			SetError(sarama.ErrUnsupportedSASLMechanism),
+	})
+
+	config := &Config{
+		BootstrapServers: &[]string{"127.0.0.1:1", mb.Addr()},
+		Timeout:          1,
+		SASLMechanism:    "plain",
+		SASLUsername:     "user",
+		SASLPassword:     "password",
+	}
+	c := &LazyClient{Config: config}
+
+	mechanisms, err := c.SASLMechanisms()
+	assertNil(t, err)
+	assertEquals(t, 1, len(mechanisms))
+	assertEquals(t, "SCRAM-SHA-512", mechanisms[0])
+
+	err = c.checkSASLMechanism()
+	if err == nil || err.Error() != "broker advertises [SCRAM-SHA-512], but provider configured 'plain'" {
+		t.Errorf("expected the plain mechanism to be reported as not advertised, got %v", err)
+	}
+
+	config.SASLMechanism = "scram-sha512"
+	assertNil(t, c.checkSASLMechanism())
+
+	// the check is skipped when the brokers cannot be asked
+	config.BootstrapServers = &[]string{"127.0.0.1:1"}
+	config.SASLMechanism = "plain"
+	assertNil(t, c.checkSASLMechanism())
+	if _, err := c.SASLMechanisms(); err == nil {
+		t.Error("expected an error with no reachable bootstrap server")
+	}
+}
@@ -0,0 +1,46 @@
+package kafka
+
+import (
+	"strings"
+	"testing"
+)
+
+// Test vector from RFC 7677, section 3
+func TestTokenSCRAMClient_Exchange(t *testing.T) {
+	x := &TokenSCRAMClient{HashGeneratorFcn: SHA256, noTokenAuth: true}
+	if err := x.Begin("user", "pencil", ""); err != nil {
+		t.Fatal(err)
+	}
+	x.nonce = "rOprNGfwEbeRWgbNEkqO"
+
+	first, err := x.Step("")
+	assertNil(t, err)
+	assertEquals(t, "n,,n=user,r=rOprNGfwEbeRWgbNEkqO", first)
+
+	final, err := x.Step("r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0,s=W22ZaJ0SNY7soEsUEjb6gQ==,i=4096")
+	assertNil(t, err)
+	assertEquals(t, "c=biws,r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0,p=dHzbZapWIk4jUhN+Ute9ytag9zjfMHgsqmmiz7AndVQ=", final)
+
+	_, err = x.Step("v=6rriTRBi23WpRR/wtup+mMhUZUn/dB5nLTJRsjl95G4=")
+	assertNil(t, err)
+	if !x.Done() {
+		t.Fatal("expected the conversation to be done")
+	}
+}
+
+func TestTokenSCRAMClient_TokenAuthExtension(t *testing.T) {
+	x := &TokenSCRAMClient{HashGeneratorFcn: SHA512}
+	if err := x.Begin("token,id", "hmac", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := x.Step("")
+	assertNil(t, err)
+	if !strings.HasPrefix(first, "n,,n=token=2Cid,r=") || !strings.HasSuffix(first, ",tokenauth=true") {
+		t.Fatalf("unexpected client-first message %q", first)
+	}
+
+	if _, err := x.Step("r=other,s=W22ZaJ0SNY7soEsUEjb6gQ==,i=4096"); err == nil {
+		t.Fatal("expected an error when the server nonce does not extend the client nonce")
+	}
+}
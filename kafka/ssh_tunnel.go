@@ -0,0 +1,302 @@
+package kafka
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+	"golang.org/x/net/proxy"
+)
+
+const defaultSSHPort = "22"
+
+// SSHTunnel is the SSH host, e.g. a bastion, that connections to the brokers
+// are tunnelled through
+type SSHTunnel struct {
+	Host                  string
+	User                  string
+	PrivateKey            string
+	PrivateKeyFile        string
+	PrivateKeyPassphrase  string
+	KnownHostsFile        string
+	InsecureIgnoreHostKey bool
+}
+
+// sshKeepAliveInterval is how often the SSH connection is checked, so that
+// one dropped e.g. by an idle timeout of the bastion is noticed and opened
+// again on the next dial
+const sshKeepAliveInterval = 30 * time.Second
+
+// sshDialers are the SSH dialers built for each configured tunnel
+var (
+	sshDialers      = map[*SSHTunnel]*sshDialer{}
+	sshDialersMutex sync.Mutex
+)
+
+// dialer returns the dialer the brokers are connected through, or nil to
+// connect to them directly. The SSH dialer is built once per tunnel, so that
+// every Kafka config made from a Config shares its SSH connection.
+func (c *Config) dialer(timeout time.Duration) (proxy.Dialer, error) {
+	if c.ProxyURL != "" && c.SSHTunnel != nil {
+		return nil, fmt.Errorf("proxy_url and ssh_tunnel cannot both be set")
+	}
+	if c.ProxyURL != "" {
+		return newProxyDialer(c.ProxyURL, c.ProxyUsername, c.ProxyPassword, timeout)
+	}
+	if c.SSHTunnel != nil {
+		sshDialersMutex.Lock()
+		defer sshDialersMutex.Unlock()
+		if dialer, ok := sshDialers[c.SSHTunnel]; ok {
+			return dialer, nil
+		}
+		dialer, err := newSSHDialer(c.SSHTunnel, timeout)
+		if err != nil {
+			return nil, err
+		}
+		sshDialers[c.SSHTunnel] = dialer
+		return dialer, nil
+	}
+	return nil, nil
+}
+
+// sshDialer connects to the brokers through a single SSH connection, opened
+// on the first dial and opened again once it was closed
+type sshDialer struct {
+	addr   string
+	config *ssh.ClientConfig
+	// agentSocket is the SSH agent authenticated with when no private key
+	// is configured
+	agentSocket string
+
+	mu     sync.Mutex
+	client *ssh.Client
+}
+
+func newSSHDialer(tunnel *SSHTunnel, timeout time.Duration) (*sshDialer, error) {
+	if tunnel.Host == "" || tunnel.User == "" {
+		return nil, fmt.Errorf("ssh_tunnel requires a host and a user")
+	}
+	addr := tunnel.Host
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, defaultSSHPort)
+	}
+
+	auth, err := tunnel.privateKeyAuth()
+	if err != nil {
+		return nil, err
+	}
+	var agentSocket string
+	if auth == nil {
+		agentSocket = os.Getenv("SSH_AUTH_SOCK")
+		if agentSocket == "" {
+			return nil, fmt.Errorf("ssh_tunnel requires private_key, private_key_file or a running SSH agent")
+		}
+	}
+	hostKeyCallback, err := tunnel.hostKeyCallback()
+	if err != nil {
+		return nil, err
+	}
+
+	config := &ssh.ClientConfig{
+		User:            tunnel.User,
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         timeout,
+	}
+	if auth != nil {
+		config.Auth = []ssh.AuthMethod{auth}
+	}
+	return &sshDialer{
+		addr:        addr,
+		config:      config,
+		agentSocket: agentSocket,
+	}, nil
+}
+
+// Dial opens a channel to addr through the SSH connection. A channel failing
+// to open, e.g. as the broker cannot be reached from the bastion, leaves the
+// connection and the other channels through it as they are.
+func (d *sshDialer) Dial(network, addr string) (net.Conn, error) {
+	client, err := d.sshClient()
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := client.Dial(network, addr)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to %s through SSH tunnel %s: %w", addr, d.addr, err)
+	}
+	return newDeadlineConn(conn), nil
+}
+
+// sshClient returns the open SSH connection, opening one if there is none
+func (d *sshDialer) sshClient() (*ssh.Client, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.client != nil {
+		return d.client, nil
+	}
+
+	config := *d.config
+	if d.agentSocket != "" {
+		// the agent is only needed to sign the handshake
+		conn, err := net.Dial("unix", d.agentSocket)
+		if err != nil {
+			return nil, fmt.Errorf("error connecting to the SSH agent: %w", err)
+		}
+		defer conn.Close()
+		config.Auth = []ssh.AuthMethod{ssh.PublicKeysCallback(agent.NewClient(conn).Signers)}
+	}
+
+	log.Printf("[DEBUG] Opening SSH tunnel to %s@%s", d.config.User, d.addr)
+	client, err := ssh.Dial("tcp", d.addr, &config)
+	if err != nil {
+		return nil, fmt.Errorf("error opening SSH tunnel to %s: %w", d.addr, err)
+	}
+	d.client = client
+	go d.keepAlive(client)
+	return client, nil
+}
+
+// keepAlive checks the SSH connection every sshKeepAliveInterval, closing it
+// when it does not answer, and forgets it once it is closed so that the next
+// dial opens a new one
+func (d *sshDialer) keepAlive(client *ssh.Client) {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(sshKeepAliveInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if _, _, err := client.SendRequest("keepalive@openssh.com", true, nil); err != nil {
+					log.Printf("[WARN] SSH tunnel %s is not answering, closing it: %s", d.addr, err)
+					client.Close()
+					return
+				}
+			}
+		}
+	}()
+
+	err := client.Wait()
+	close(done)
+	log.Printf("[DEBUG] SSH tunnel to %s closed: %v", d.addr, err)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.client == client {
+		d.client = nil
+	}
+}
+
+// deadlineConn pipes an SSH channel, which does not support deadlines,
+// through an in-memory connection that does, as the Kafka client sets
+// a deadline on every read and write
+type deadlineConn struct {
+	net.Conn
+	channel net.Conn
+}
+
+func newDeadlineConn(channel net.Conn) *deadlineConn {
+	local, remote := net.Pipe()
+	go func() {
+		io.Copy(channel, remote)
+		channel.Close()
+	}()
+	go func() {
+		io.Copy(remote, channel)
+		remote.Close()
+	}()
+	return &deadlineConn{Conn: local, channel: channel}
+}
+
+func (c *deadlineConn) LocalAddr() net.Addr  { return c.channel.LocalAddr() }
+func (c *deadlineConn) RemoteAddr() net.Addr { return c.channel.RemoteAddr() }
+
+func (c *deadlineConn) Close() error {
+	c.channel.Close()
+	return c.Conn.Close()
+}
+
+// privateKeyAuth authenticates with the configured private key, returning
+// nil if there is none
+func (t *SSHTunnel) privateKeyAuth() (ssh.AuthMethod, error) {
+	key := []byte(t.PrivateKey)
+	if len(key) == 0 && t.PrivateKeyFile != "" {
+		var err error
+		key, err = os.ReadFile(t.PrivateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading ssh_tunnel private_key_file: %w", err)
+		}
+	}
+	if len(key) == 0 {
+		return nil, nil
+	}
+
+	var signer ssh.Signer
+	var err error
+	if t.PrivateKeyPassphrase != "" {
+		signer, err = ssh.ParsePrivateKeyWithPassphrase(key, []byte(t.PrivateKeyPassphrase))
+	} else {
+		signer, err = ssh.ParsePrivateKey(key)
+	}
+	if err != nil {
+		var missing *ssh.PassphraseMissingError
+		if errors.As(err, &missing) {
+			return nil, fmt.Errorf("the ssh_tunnel private key is encrypted, set private_key_passphrase")
+		}
+		return nil, fmt.Errorf("invalid ssh_tunnel private key: %w", err)
+	}
+	return ssh.PublicKeys(signer), nil
+}
+
+// hostKeyCallback verifies the SSH host against known_hosts_file, by default
+// ~/.ssh/known_hosts
+func (t *SSHTunnel) hostKeyCallback() (ssh.HostKeyCallback, error) {
+	if t.InsecureIgnoreHostKey {
+		log.Printf("[WARN] Not verifying the host key of SSH tunnel %s", t.Host)
+		return ssh.InsecureIgnoreHostKey(), nil
+	}
+
+	path := t.KnownHostsFile
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("could not find the default known_hosts file, set ssh_tunnel known_hosts_file: %w", err)
+		}
+		path = filepath.Join(home, ".ssh", "known_hosts")
+	}
+
+	callback, err := knownhosts.New(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading ssh_tunnel known_hosts_file: %w", err)
+	}
+	return callback, nil
+}
+
+// maskedSSHTunnel returns the tunnel with its private key and passphrase
+// masked
+func maskedSSHTunnel(tunnel *SSHTunnel) *SSHTunnel {
+	if tunnel == nil {
+		return nil
+	}
+	masked := *tunnel
+	if masked.PrivateKey != "" {
+		masked.PrivateKey = "*****"
+	}
+	if masked.PrivateKeyPassphrase != "" {
+		masked.PrivateKeyPassphrase = "*****"
+	}
+	return &masked
+}
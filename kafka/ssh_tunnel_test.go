@@ -0,0 +1,232 @@
+package kafka
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+func testSSHKey(t *testing.T) (ssh.Signer, []byte) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := ssh.MarshalPrivateKey(key, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer, pem.EncodeToMemory(block)
+}
+
+// testSSHServer starts an SSH server accepting clientKey, that forwards
+// direct-tcpip channels to their destination, and returns its address
+func testSSHServer(t *testing.T, hostKey ssh.Signer, clientKey ssh.PublicKey) string {
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(_ ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if string(key.Marshal()) != string(clientKey.Marshal()) {
+				return nil, io.EOF
+			}
+			return nil, nil
+		},
+	}
+	config.AddHostKey(hostKey)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				_, chans, reqs, err := ssh.NewServerConn(conn, config)
+				if err != nil {
+					return
+				}
+				go ssh.DiscardRequests(reqs)
+				for newChan := range chans {
+					var dest struct {
+						Host       string
+						Port       uint32
+						OriginHost string
+						OriginPort uint32
+					}
+					if newChan.ChannelType() != "direct-tcpip" || ssh.Unmarshal(newChan.ExtraData(), &dest) != nil {
+						newChan.Reject(ssh.UnknownChannelType, "unsupported")
+						continue
+					}
+					target, err := net.Dial("tcp", net.JoinHostPort(dest.Host, strconv.Itoa(int(dest.Port))))
+					if err != nil {
+						newChan.Reject(ssh.ConnectionFailed, err.Error())
+						continue
+					}
+					ch, chReqs, err := newChan.Accept()
+					if err != nil {
+						target.Close()
+						continue
+					}
+					go ssh.DiscardRequests(chReqs)
+					go func() {
+						defer ch.Close()
+						defer target.Close()
+						go io.Copy(target, ch)
+						io.Copy(ch, target)
+					}()
+				}
+			}()
+		}
+	}()
+
+	return l.Addr().String()
+}
+
+func Test_sshDialer(t *testing.T) {
+	hostKey, _ := testSSHKey(t)
+	clientKey, clientKeyPEM := testSSHKey(t)
+	addr := testSSHServer(t, hostKey, clientKey.PublicKey())
+
+	// the broker is only reached through the tunnel
+	broker, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer broker.Close()
+	go func() {
+		conn, err := broker.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		io.Copy(conn, conn)
+	}()
+
+	knownHosts := filepath.Join(t.TempDir(), "known_hosts")
+	if err := os.WriteFile(knownHosts, []byte(knownhosts.Line([]string{addr}, hostKey.PublicKey())+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	config := Config{SSHTunnel: &SSHTunnel{
+		Host:           addr,
+		User:           "terraform",
+		PrivateKey:     string(clientKeyPEM),
+		KnownHostsFile: knownHosts,
+	}}
+	sConfig, err := config.newKafkaConfig()
+	assertNil(t, err)
+	assertEquals(t, true, sConfig.Net.Proxy.Enable)
+
+	conn, err := sConfig.Net.Proxy.Dialer.Dial("tcp", broker.Addr().String())
+	assertNil(t, err)
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(5 * time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.Write([]byte("ping")); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 4)
+	if _, err := io.ReadFull(conn, buf); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "ping", string(buf))
+
+	// every Kafka config made from the same Config shares the SSH connection
+	again, err := config.newKafkaConfig()
+	assertNil(t, err)
+	assertEquals(t, sConfig.Net.Proxy.Dialer, again.Net.Proxy.Dialer)
+
+	// a broker the bastion cannot reach leaves the connection open
+	unreachable, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	unreachable.Close()
+	tunnel := sConfig.Net.Proxy.Dialer.(*sshDialer)
+	current := func() *ssh.Client {
+		tunnel.mu.Lock()
+		defer tunnel.mu.Unlock()
+		return tunnel.client
+	}
+	client := current()
+	if _, err := tunnel.Dial("tcp", unreachable.Addr().String()); err == nil {
+		t.Error("expected an error for an unreachable broker")
+	}
+	assertEquals(t, client, current())
+	if _, err := conn.Write([]byte("pong")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.ReadFull(conn, buf); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "pong", string(buf))
+
+	// once the connection is closed, the next dial opens a new one
+	client.Close()
+	for i := 0; i < 100 && current() != nil; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	reopened, err := tunnel.Dial("tcp", broker.Addr().String())
+	assertNil(t, err)
+	reopened.Close()
+
+	// a host key other than the known one is rejected
+	otherKey, _ := testSSHKey(t)
+	dialer, err := newSSHDialer(&SSHTunnel{
+		Host:           testSSHServer(t, otherKey, clientKey.PublicKey()),
+		User:           "terraform",
+		PrivateKey:     string(clientKeyPEM),
+		KnownHostsFile: knownHosts,
+	}, time.Second)
+	assertNil(t, err)
+	if _, err := dialer.Dial("tcp", broker.Addr().String()); err == nil {
+		t.Error("expected an error for an unknown host key")
+	}
+}
+
+func Test_newSSHDialer(t *testing.T) {
+	_, key := testSSHKey(t)
+
+	dialer, err := newSSHDialer(&SSHTunnel{Host: "bastion", User: "terraform", PrivateKey: string(key), InsecureIgnoreHostKey: true}, time.Second)
+	assertNil(t, err)
+	assertEquals(t, "bastion:22", dialer.addr)
+
+	t.Setenv("SSH_AUTH_SOCK", "")
+	for _, tc := range []struct {
+		tunnel SSHTunnel
+		err    string
+	}{
+		{SSHTunnel{User: "terraform", PrivateKey: string(key)}, "requires a host and a user"},
+		{SSHTunnel{Host: "bastion", User: "terraform", InsecureIgnoreHostKey: true}, "or a running SSH agent"},
+		{SSHTunnel{Host: "bastion", User: "terraform", PrivateKey: "not a key"}, "invalid ssh_tunnel private key"},
+		{SSHTunnel{Host: "bastion", User: "terraform", PrivateKey: string(key), KnownHostsFile: "/does/not/exist"}, "known_hosts_file"},
+	} {
+		if _, err := newSSHDialer(&tc.tunnel, time.Second); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("expected an error containing %q, got %v", tc.err, err)
+		}
+	}
+
+	config := Config{ProxyURL: "socks5://proxy:1080", SSHTunnel: &SSHTunnel{Host: "bastion", User: "terraform"}}
+	if _, err := config.newKafkaConfig(); err == nil {
+		t.Error("expected an error with both proxy_url and ssh_tunnel set")
+	}
+}
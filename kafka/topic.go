@@ -4,6 +4,9 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/IBM/sarama"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -14,17 +17,84 @@ type Topic struct {
 	Partitions        int32
 	ReplicationFactor int16
 	Config            map[string]*string
+	ReplicaAssignment map[int32][]int32
 }
 
+// Equal compares the topics. The replica assignment is only compared when t
+// has one, as it is derived from the replication factor otherwise.
 func (t *Topic) Equal(other Topic) bool {
 	mape := MapEq(other.Config, t.Config)
 
 	if mape == nil && (other.Name == t.Name) && (other.Partitions == t.Partitions) && (other.ReplicationFactor == t.ReplicationFactor) {
-		return true
+		return len(t.ReplicaAssignment) == 0 || replicaAssignmentEq(t.ReplicaAssignment, other.ReplicaAssignment)
 	}
 	return false
 }
 
+// mismatches describes how other differs from t, comparing them like Equal
+func (t *Topic) mismatches(other Topic) []string {
+	var mismatches []string
+	// partitions and replication_factor left to the broker's defaults match
+	// the existing topic's
+	if t.Partitions != 0 && other.Partitions != t.Partitions {
+		mismatches = append(mismatches, fmt.Sprintf("partitions is %d, not %d", other.Partitions, t.Partitions))
+	}
+	if t.ReplicationFactor != 0 && other.ReplicationFactor != t.ReplicationFactor {
+		mismatches = append(mismatches, fmt.Sprintf("replication_factor is %d, not %d", other.ReplicationFactor, t.ReplicationFactor))
+	}
+	if len(t.ReplicaAssignment) > 0 && !replicaAssignmentEq(t.ReplicaAssignment, other.ReplicaAssignment) {
+		mismatches = append(mismatches, fmt.Sprintf("replica_assignment is %v, not %v", other.ReplicaAssignment, t.ReplicaAssignment))
+	}
+
+	want, got := strPtrMapToStrMap(t.Config), strPtrMapToStrMap(other.Config)
+	for _, key := range sortedKeys(want, got) {
+		if v, ok := other.Config[key]; ok && v == nil {
+			// sensitive, so its value is unknown
+			continue
+		}
+		w, wok := want[key]
+		g, gok := got[key]
+		switch {
+		case !gok:
+			mismatches = append(mismatches, fmt.Sprintf("config %s is unset, not %q", key, w))
+		case !wok:
+			mismatches = append(mismatches, fmt.Sprintf("config %s is %q, not unset", key, g))
+		case w != g:
+			mismatches = append(mismatches, fmt.Sprintf("config %s is %q, not %q", key, g, w))
+		}
+	}
+	return mismatches
+}
+
+// sortedKeys returns the keys of all the maps, sorted
+func sortedKeys(maps ...map[string]string) []string {
+	seen := map[string]bool{}
+	for _, m := range maps {
+		for key := range m {
+			seen[key] = true
+		}
+	}
+	keys := make([]string, 0, len(seen))
+	for key := range seen {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func replicaAssignmentEq(a, b map[int32][]int32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for p, replicas := range a {
+		other, ok := b[p]
+		if !ok || !int32SliceEq(replicas, other) {
+			return false
+		}
+	}
+	return true
+}
+
 // ReplicaCount returns the replication_factor for a partition
 // Returns an error if it cannot determine the count, or if the number of
 // replicas is different across partitions
@@ -34,7 +104,7 @@ func ReplicaCount(c sarama.Client, topic string, partitions []int32) (int, error
 	for _, p := range partitions {
 		replicas, err := c.Replicas(topic, p)
 		if err != nil {
-			return -1, errors.New("could not get replicas for partition")
+			return -1, fmt.Errorf("could not get replicas for partition: %w", err)
 		}
 		if count == -1 {
 			count = len(replicas)
@@ -47,6 +117,144 @@ func ReplicaCount(c sarama.Client, topic string, partitions []int32) (int, error
 
 }
 
+// ReplicaAssignment returns the ordered list of replicas for each partition
+func ReplicaAssignment(c sarama.Client, topic string, partitions []int32) (map[int32][]int32, error) {
+	assignment := make(map[int32][]int32, len(partitions))
+	for _, p := range partitions {
+		replicas, err := c.Replicas(topic, p)
+		if err != nil {
+			return nil, fmt.Errorf("could not get replicas for partition: %w", err)
+		}
+		assignment[p] = replicas
+	}
+	return assignment, nil
+}
+
+// validateReplicaAssignment checks that assignment covers exactly the
+// partitions 0 to partitions-1, and that every partition has the same number
+// of distinct replicas
+func validateReplicaAssignment(assignment map[int32][]int32, partitions int32) error {
+	if int32(len(assignment)) != partitions {
+		return fmt.Errorf("replica_assignment has %d partitions, but partitions is %d", len(assignment), partitions)
+	}
+
+	rf := -1
+	for p := int32(0); p < partitions; p++ {
+		replicas, ok := assignment[p]
+		if !ok {
+			return fmt.Errorf("replica_assignment is missing partition %d", p)
+		}
+		if len(replicas) == 0 {
+			return fmt.Errorf("replica_assignment for partition %d has no replicas", p)
+		}
+		if rf == -1 {
+			rf = len(replicas)
+		}
+		if rf != len(replicas) {
+			return fmt.Errorf("replica_assignment must have the same number of replicas for every partition: partition %d has %d, expected %d", p, len(replicas), rf)
+		}
+
+		seen := make(map[int32]bool, len(replicas))
+		for _, r := range replicas {
+			if seen[r] {
+				return fmt.Errorf("replica_assignment for partition %d lists broker %d more than once", p, r)
+			}
+			seen[r] = true
+		}
+	}
+
+	return nil
+}
+
+// replicationFactorFromAssignment returns the number of replicas of the
+// first partition of assignment
+func replicationFactorFromAssignment(assignment map[int32][]int32) int16 {
+	return int16(len(assignment[0]))
+}
+
+func expandReplicaAssignment(raw []interface{}) map[int32][]int32 {
+	assignment := make(map[int32][]int32, len(raw))
+	for _, v := range raw {
+		m := v.(map[string]interface{})
+		partition := int32(m["partition"].(int))
+		rawReplicas := m["replicas"].([]interface{})
+		replicas := make([]int32, 0, len(rawReplicas))
+		for _, r := range rawReplicas {
+			replicas = append(replicas, int32(r.(int)))
+		}
+		assignment[partition] = replicas
+	}
+	return assignment
+}
+
+func flattenReplicaAssignment(assignment map[int32][]int32) []interface{} {
+	partitions := make([]int32, 0, len(assignment))
+	for p := range assignment {
+		partitions = append(partitions, p)
+	}
+	sort.Slice(partitions, func(i, j int) bool { return partitions[i] < partitions[j] })
+
+	res := make([]interface{}, 0, len(partitions))
+	for _, p := range partitions {
+		replicas := make([]interface{}, 0, len(assignment[p]))
+		for _, r := range assignment[p] {
+			replicas = append(replicas, int(r))
+		}
+		res = append(res, map[string]interface{}{
+			"partition": int(p),
+			"replicas":  replicas,
+		})
+	}
+	return res
+}
+
+const (
+	leaderThrottledReplicasConfig   = "leader.replication.throttled.replicas"
+	followerThrottledReplicasConfig = "follower.replication.throttled.replicas"
+)
+
+// throttledReplicasAttributes maps the kafka_topic attributes for replication
+// throttles to the topic config they are rendered into
+var throttledReplicasAttributes = map[string]string{
+	"leader_replication_throttled_replicas":   leaderThrottledReplicasConfig,
+	"follower_replication_throttled_replicas": followerThrottledReplicasConfig,
+}
+
+var throttledReplicaRegex = regexp.MustCompile(`^(\*|\d+:\d+)$`)
+
+// formatThrottledReplicas renders a list of "partition:broker" pairs into the
+// comma-separated value of a throttled replicas config
+func formatThrottledReplicas(replicas []string) string {
+	sorted := make([]string, len(replicas))
+	copy(sorted, replicas)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ",")
+}
+
+// parseThrottledReplicas is the inverse of formatThrottledReplicas
+func parseThrottledReplicas(value string) []string {
+	replicas := []string{}
+	for _, r := range strings.Split(value, ",") {
+		r = strings.TrimSpace(r)
+		if r != "" {
+			replicas = append(replicas, r)
+		}
+	}
+	return replicas
+}
+
+func validateThrottledReplicas(replicas []string) error {
+	for _, r := range replicas {
+		if !throttledReplicaRegex.MatchString(r) {
+			return fmt.Errorf("throttled replica %q must be a partition:broker pair or *", r)
+		}
+		if r == "*" && len(replicas) > 1 {
+			return errors.New("* throttles every replica and cannot be combined with partition:broker pairs")
+		}
+	}
+	return nil
+}
+
 func configToResources(topic Topic, c *Config) []*sarama.AlterConfigsResource {
 	if topic.Config["cleanup.policy"] != nil {
 		re := regexp.MustCompile(`(?i)kafka-serverless\.(.*)\.amazonaws\.com`)
@@ -66,35 +274,146 @@ func configToResources(topic Topic, c *Config) []*sarama.AlterConfigsResource {
 	}
 }
 
-func isDefault(tc *sarama.ConfigEntry, version int) bool {
+// isSetOnTopic reports whether the config entry was set on the topic itself.
+// Values inherited from the broker, whether a per-broker override, the dynamic
+// cluster default, server.properties or Kafka's defaults, are not. Version 0
+// responses have no source, only whether the value is a default.
+func isSetOnTopic(tc *sarama.ConfigEntry, version int) bool {
 	if version == 0 {
-		return tc.Default
+		return !tc.Default
 	}
-	return tc.Source == sarama.SourceDefault ||
-		tc.Source == sarama.SourceStaticBroker ||
-		tc.Source == sarama.SourceDynamicDefaultBroker
+	return tc.Source == sarama.SourceTopic
 }
 
-func metaToTopic(d *schema.ResourceData, meta interface{}) Topic {
+func metaToTopic(d resourceGetter, meta interface{}) Topic {
 	topicName := d.Get("name").(string)
 	partitions := d.Get("partitions").(int)
 	replicationFactor := d.Get("replication_factor").(int)
 	convertedPartitions := int32(partitions)
 	convertedRF := int16(replicationFactor)
-	config := d.Get("config").(map[string]interface{})
 
+	var defaults map[string]string
+	if c, ok := meta.(*LazyClient); ok && c.Config != nil {
+		defaults = c.Config.DefaultTopicConfig
+	}
+	m2 := topicConfigFromResource(d, defaults)
+	for key, value := range d.Get("sensitive_config").(map[string]interface{}) {
+		value := value.(string)
+		m2[key] = &value
+	}
+
+	var assignment map[int32][]int32
+	if raw := d.Get("replica_assignment").(*schema.Set).List(); len(raw) > 0 {
+		assignment = expandReplicaAssignment(raw)
+		convertedRF = replicationFactorFromAssignment(assignment)
+	}
+
+	return Topic{
+		Name:              topicName,
+		Partitions:        convertedPartitions,
+		ReplicationFactor: convertedRF,
+		Config:            m2,
+		ReplicaAssignment: assignment,
+	}
+}
+
+// maskedConfig returns the config with the entries Kafka reports as
+// sensitive in actual masked
+func maskedConfig(config, actual map[string]*string) map[string]string {
+	masked := strPtrMapToStrMap(config)
+	for key, value := range actual {
+		if _, ok := masked[key]; ok && value == nil {
+			masked[key] = "*****"
+		}
+	}
+	return masked
+}
+
+// resourceGetter is implemented by both schema.ResourceData and
+// schema.ResourceDiff
+type resourceGetter interface {
+	Get(key string) interface{}
+}
+
+// topicConfigInheritDefault is the config value that leaves the entry unset
+// on the topic, so that it inherits the broker's default even when
+// default_topic_config sets it
+const topicConfigInheritDefault = "inherit"
+
+// topicConfigFromResource returns the config to apply to a topic: the
+// provider's default_topic_config, overridden by the topic's config and the
+// throttled replica attributes
+func topicConfigFromResource(d resourceGetter, defaults map[string]string) map[string]*string {
 	m2 := make(map[string]*string)
+	for key, value := range defaults {
+		value := value
+		m2[key] = &value
+	}
+
+	config := d.Get("config").(map[string]interface{})
 	for key, value := range config {
 		switch value := value.(type) {
 		case string:
+			if value == topicConfigInheritDefault {
+				delete(m2, key)
+				continue
+			}
 			m2[key] = &value
 		}
 	}
 
-	return Topic{
-		Name:              topicName,
-		Partitions:        convertedPartitions,
-		ReplicationFactor: convertedRF,
-		Config:            m2,
+	for attr, key := range throttledReplicasAttributes {
+		replicas := setToStrings(d.Get(attr).(*schema.Set))
+		if len(replicas) > 0 {
+			value := formatThrottledReplicas(replicas)
+			m2[key] = &value
+		}
+	}
+
+	return m2
+}
+
+const compressionTypeConfig = "compression.type"
+
+// compressionTypeVersions maps each compression.type to the first Kafka
+// version supporting it
+var compressionTypeVersions = map[string]sarama.KafkaVersion{
+	"uncompressed": sarama.MinVersion,
+	"producer":     sarama.MinVersion,
+	"gzip":         sarama.MinVersion,
+	"snappy":       sarama.MinVersion,
+	"lz4":          sarama.MinVersion,
+	"zstd":         sarama.V2_1_0_0,
+}
+
+// validateCompressionType checks that the config's compression.type is
+// supported by the given Kafka version. Types not known here are left to the
+// brokers to validate.
+func validateCompressionType(config map[string]*string, version sarama.KafkaVersion) error {
+	value, ok := config[compressionTypeConfig]
+	if !ok || value == nil {
+		return nil
+	}
+	since, ok := compressionTypeVersions[*value]
+	if !ok || version.IsAtLeast(since) {
+		return nil
+	}
+	return fmt.Errorf("%s %s requires Kafka %s or later, but kafka_version is %s", compressionTypeConfig, *value, since, version)
+}
+
+const minInsyncReplicasConfig = "min.insync.replicas"
+
+// validateMinInsyncReplicas rejects a min.insync.replicas higher than the
+// replication factor, with which producers using acks=all can never write to
+// the topic. Values that are not numbers are left to the brokers to validate.
+func validateMinInsyncReplicas(config map[string]*string, replicationFactor int) error {
+	value, ok := config[minInsyncReplicasConfig]
+	if !ok || value == nil {
+		return nil
+	}
+	minInsync, err := strconv.Atoi(*value)
+	if err != nil || minInsync <= replicationFactor {
+		return nil
 	}
+	return fmt.Errorf("%s %d is higher than the replication_factor %d, so producers using acks=all could never write to the topic", minInsyncReplicasConfig, minInsync, replicationFactor)
 }
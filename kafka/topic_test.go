@@ -0,0 +1,252 @@
+package kafka
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func Test_validateReplicaAssignment(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		assignment map[int32][]int32
+		partitions int32
+		err        string
+	}{
+		{
+			name:       "valid",
+			assignment: map[int32][]int32{0: {1, 2}, 1: {2, 3}},
+			partitions: 2,
+		},
+		{
+			name:       "partition count mismatch",
+			assignment: map[int32][]int32{0: {1, 2}},
+			partitions: 2,
+			err:        "has 1 partitions, but partitions is 2",
+		},
+		{
+			name:       "missing partition",
+			assignment: map[int32][]int32{0: {1, 2}, 2: {2, 3}},
+			partitions: 2,
+			err:        "missing partition 1",
+		},
+		{
+			name:       "uneven replicas",
+			assignment: map[int32][]int32{0: {1, 2}, 1: {2}},
+			partitions: 2,
+			err:        "same number of replicas",
+		},
+		{
+			name:       "duplicate broker",
+			assignment: map[int32][]int32{0: {1, 1}},
+			partitions: 1,
+			err:        "lists broker 1 more than once",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateReplicaAssignment(tc.assignment, tc.partitions)
+			if tc.err == "" {
+				if err != nil {
+					t.Fatalf("expected no error, got %s", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Fatalf("expected error containing %q, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestTopic_EqualReplicaAssignment(t *testing.T) {
+	actual := Topic{
+		Name:              "foo",
+		Partitions:        1,
+		ReplicationFactor: 2,
+		ReplicaAssignment: map[int32][]int32{0: {1, 2}},
+	}
+
+	expected := Topic{Name: "foo", Partitions: 1, ReplicationFactor: 2}
+	if !expected.Equal(actual) {
+		t.Fatal("expected topics without an assignment to be equal")
+	}
+
+	expected.ReplicaAssignment = map[int32][]int32{0: {1, 2}}
+	if !expected.Equal(actual) {
+		t.Fatal("expected topics with the same assignment to be equal")
+	}
+
+	expected.ReplicaAssignment = map[int32][]int32{0: {2, 1}}
+	if expected.Equal(actual) {
+		t.Fatal("expected the order of replicas to matter")
+	}
+}
+
+func TestTopic_mismatches(t *testing.T) {
+	retention, segment, compact := "11111", "22222", "compact"
+	expected := Topic{
+		Name:              "foo",
+		Partitions:        2,
+		ReplicationFactor: 3,
+		Config:            map[string]*string{"retention.ms": &retention, "segment.ms": &segment, "custom.password": &compact},
+	}
+	actual := Topic{
+		Name:              "foo",
+		Partitions:        1,
+		ReplicationFactor: 3,
+		Config:            map[string]*string{"retention.ms": &segment, "cleanup.policy": &compact, "custom.password": nil},
+	}
+
+	if m := expected.mismatches(expected); len(m) != 0 {
+		t.Errorf("expected no mismatches, got %v", m)
+	}
+
+	want := []string{
+		"partitions is 1, not 2",
+		`config cleanup.policy is "compact", not unset`,
+		`config retention.ms is "22222", not "11111"`,
+		`config segment.ms is unset, not "22222"`,
+	}
+	if m := expected.mismatches(actual); !reflect.DeepEqual(want, m) {
+		t.Errorf("expected mismatches %v, got %v", want, m)
+	}
+}
+
+func Test_maskedConfig(t *testing.T) {
+	retention, password := "11111", "secret"
+	expected := map[string]*string{"retention.ms": &retention, "custom.password": &password}
+	actual := map[string]*string{"retention.ms": &retention, "custom.password": nil}
+
+	want := map[string]string{"retention.ms": "11111", "custom.password": "*****"}
+	if m := maskedConfig(expected, actual); !reflect.DeepEqual(want, m) {
+		t.Errorf("expected %v, got %v", want, m)
+	}
+}
+
+func Test_flattenReplicaAssignment(t *testing.T) {
+	assignment := map[int32][]int32{1: {2, 3}, 0: {1, 2}}
+	flat := flattenReplicaAssignment(assignment)
+	if len(flat) != 2 || flat[0].(map[string]interface{})["partition"] != 0 {
+		t.Fatalf("expected assignment sorted by partition, got %v", flat)
+	}
+
+	roundTrip := expandReplicaAssignment(flat)
+	if !replicaAssignmentEq(assignment, roundTrip) {
+		t.Fatalf("expected %v, got %v", assignment, roundTrip)
+	}
+}
+
+func Test_throttledReplicas(t *testing.T) {
+	value := formatThrottledReplicas([]string{"1:2", "0:1", "0:3"})
+	if value != "0:1,0:3,1:2" {
+		t.Fatalf("unexpected throttled replicas value %q", value)
+	}
+
+	parsed := parseThrottledReplicas(value)
+	if len(parsed) != 3 || parsed[0] != "0:1" || parsed[2] != "1:2" {
+		t.Fatalf("unexpected parsed throttled replicas %v", parsed)
+	}
+
+	if parsed := parseThrottledReplicas(""); len(parsed) != 0 {
+		t.Fatalf("expected no throttled replicas, got %v", parsed)
+	}
+
+	if err := validateThrottledReplicas([]string{"*"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := validateThrottledReplicas([]string{"*", "0:1"}); err == nil {
+		t.Fatal("expected * combined with pairs to be invalid")
+	}
+	if err := validateThrottledReplicas([]string{"0-1"}); err == nil {
+		t.Fatal("expected 0-1 to be invalid")
+	}
+}
+
+func Test_topicConfigFromResource(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, kafkaTopicResource().Schema, map[string]interface{}{
+		"name":               "foo",
+		"partitions":         1,
+		"replication_factor": 1,
+		"config": map[string]interface{}{
+			"cleanup.policy": "compact",
+			"retention.ms":   topicConfigInheritDefault,
+			"segment.ms":     topicConfigInheritDefault,
+			// keys the provider does not know are left to the brokers
+			"confluent.placement.constraints": `{"version":1}`,
+		},
+	})
+
+	defaults := map[string]string{
+		"cleanup.policy":      "delete",
+		"min.insync.replicas": "2",
+		"retention.ms":        "86400000",
+	}
+
+	// inherited entries are left out, even when default_topic_config sets them
+	config := strPtrMapToStrMap(topicConfigFromResource(d, defaults))
+	expected := map[string]string{
+		"cleanup.policy":                  "compact",
+		"confluent.placement.constraints": `{"version":1}`,
+		"min.insync.replicas":             "2",
+	}
+	if !reflect.DeepEqual(config, expected) {
+		t.Fatalf("expected %v, got %v", expected, config)
+	}
+}
+
+func Test_validateCompressionType(t *testing.T) {
+	zstd, lz4, other := "zstd", "lz4", "brotli"
+
+	for _, tc := range []struct {
+		config  map[string]*string
+		version sarama.KafkaVersion
+		err     string
+	}{
+		{config: map[string]*string{}, version: sarama.V0_10_2_0},
+		{config: map[string]*string{"compression.type": &lz4}, version: sarama.V0_10_2_0},
+		{config: map[string]*string{"compression.type": &zstd}, version: sarama.V2_1_0_0},
+		{config: map[string]*string{"compression.type": &other}, version: sarama.V2_1_0_0},
+		{
+			config:  map[string]*string{"compression.type": &zstd},
+			version: sarama.V2_0_0_0,
+			err:     "compression.type zstd requires Kafka 2.1.0 or later, but kafka_version is 2.0.0",
+		},
+	} {
+		err := validateCompressionType(tc.config, tc.version)
+		if tc.err == "" {
+			assertNil(t, err)
+		} else if err == nil || err.Error() != tc.err {
+			t.Errorf("expected error %q, got %v", tc.err, err)
+		}
+	}
+}
+
+func Test_validateMinInsyncReplicas(t *testing.T) {
+	two, three, other := "2", "3", "all"
+
+	for _, tc := range []struct {
+		config map[string]*string
+		rf     int
+		err    string
+	}{
+		{config: map[string]*string{}, rf: 1},
+		{config: map[string]*string{"min.insync.replicas": &two}, rf: 3},
+		{config: map[string]*string{"min.insync.replicas": &three}, rf: 3},
+		{config: map[string]*string{"min.insync.replicas": &other}, rf: 1},
+		{
+			config: map[string]*string{"min.insync.replicas": &three},
+			rf:     2,
+			err:    "min.insync.replicas 3 is higher than the replication_factor 2, so producers using acks=all could never write to the topic",
+		},
+	} {
+		err := validateMinInsyncReplicas(tc.config, tc.rf)
+		if tc.err == "" {
+			assertNil(t, err)
+		} else if err == nil || err.Error() != tc.err {
+			t.Errorf("expected error %q, got %v", tc.err, err)
+		}
+	}
+}
@@ -2,7 +2,9 @@ package kafka
 
 import (
 	"fmt"
+	"os"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
@@ -17,9 +19,14 @@ func MapEq(result, expected map[string]*string) error {
 
 	for expectedK, expectedV := range expected {
 		if resultV, ok := result[expectedK]; ok {
-			if resultV == nil && expectedV == nil {
+			// Kafka returns no value for sensitive entries, only that they
+			// are set
+			if resultV == nil {
 				continue
 			}
+			if expectedV == nil {
+				return fmt.Errorf("result[%s]: %s != expected[%s]: nil", expectedK, *resultV, expectedK)
+			}
 			if *resultV != *expectedV {
 				return fmt.Errorf("result[%s]: %s != expected[%s]: %s", expectedK, *resultV, expectedK, *expectedV)
 			}
@@ -44,11 +51,80 @@ func nonEmptyAndTrimmed(bootstrapServers []string) []string {
 	return wellFormed
 }
 
+// envListDefaultFunc is schema.EnvDefaultFunc for lists of strings: the
+// variable's value is split on commas. A plain string can't be the default
+// of a list.
+func envListDefaultFunc(k string) schema.SchemaDefaultFunc {
+	return func() (interface{}, error) {
+		v := nonEmptyAndTrimmed(strings.Split(os.Getenv(k), ","))
+		if len(v) == 0 {
+			return nil, nil
+		}
+		list := make([]interface{}, len(v))
+		for i, s := range v {
+			list[i] = s
+		}
+		return list, nil
+	}
+}
+
+// uniqueStrings returns the distinct values of in, keeping their order
+func uniqueStrings(in []string) []string {
+	seen := make(map[string]bool, len(in))
+	out := make([]string, 0, len(in))
+	for _, s := range in {
+		if !seen[s] {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// chunkStrings splits in into consecutive slices of at most size elements
+func chunkStrings(in []string, size int) [][]string {
+	chunks := make([][]string, 0, (len(in)+size-1)/size)
+	for size < len(in) {
+		in, chunks = in[size:], append(chunks, in[:size])
+	}
+	if len(in) > 0 {
+		chunks = append(chunks, in)
+	}
+	return chunks
+}
+
+// int32SliceEq reports whether a and b hold the same values in the same order
+func int32SliceEq(a, b []int32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// setToStrings returns the values of a set of strings
+func setToStrings(s *schema.Set) []string {
+	res := make([]string, 0, s.Len())
+	for _, v := range s.List() {
+		res = append(res, v.(string))
+	}
+	return res
+}
+
 // TODO: can I just get rid of this?
+
+// strPtrMapToStrMap leaves out nil values, e.g. of sensitive entries read
+// from Kafka
 func strPtrMapToStrMap(c map[string]*string) map[string]string {
 	foo := map[string]string{}
 	for k, v := range c {
-		foo[k] = *v
+		if v != nil {
+			foo[k] = *v
+		}
 	}
 	return foo
 }
@@ -72,3 +148,44 @@ func validateDiagFunc(validateFunc func(interface{}, string) ([]string, []error)
 		return diags
 	}
 }
+
+// defaultResourceTimeout is the default of each timeout of a resource's
+// timeouts block, the SDK's own default
+const defaultResourceTimeout = 20 * time.Minute
+
+// resourceTimeouts declares a timeouts block with create, update and delete
+// timeouts
+func resourceTimeouts() *schema.ResourceTimeout {
+	return &schema.ResourceTimeout{
+		Create: schema.DefaultTimeout(defaultResourceTimeout),
+		Update: schema.DefaultTimeout(defaultResourceTimeout),
+		Delete: schema.DefaultTimeout(defaultResourceTimeout),
+	}
+}
+
+// operationTimeout returns how long the operation waits for Kafka: the
+// timeout set in the resource's timeouts block, or fallback if the block does
+// not set it
+func operationTimeout(d *schema.ResourceData, key string, fallback time.Duration) time.Duration {
+	if timeoutConfigured(d, key) {
+		return d.Timeout(key)
+	}
+	return fallback
+}
+
+// timeoutConfigured reports whether the resource's timeouts block sets key.
+// There is no config on destroy, so the timeouts kept in the state are used.
+func timeoutConfigured(d *schema.ResourceData, key string) bool {
+	raw := d.GetRawConfig()
+	if raw.IsNull() {
+		raw = d.GetRawState()
+	}
+	if raw.IsNull() || !raw.IsKnown() || !raw.Type().IsObjectType() || !raw.Type().HasAttribute(schema.TimeoutsConfigKey) {
+		return false
+	}
+	timeouts := raw.GetAttr(schema.TimeoutsConfigKey)
+	if timeouts.IsNull() || !timeouts.IsKnown() || !timeouts.Type().HasAttribute(key) {
+		return false
+	}
+	return !timeouts.GetAttr(key).IsNull()
+}
@@ -3,6 +3,11 @@ package kafka
 import (
 	"reflect"
 	"testing"
+	"time"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
 func TestMapEq(t *testing.T) {
@@ -20,6 +25,14 @@ func TestMapEq(t *testing.T) {
 	if err != nil {
 		t.Fatalf("%s", err)
 	}
+
+	// sensitive values are not returned by Kafka
+	if err := MapEq(map[string]*string{"a": nil}, b); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if err := MapEq(a, map[string]*string{"a": nil}); err == nil {
+		t.Fatal("expected an error for a value that should be unset")
+	}
 }
 func TestNonEmptyAndTrimmed(t *testing.T) {
 	input := []string{"Hello ", "", " World"}
@@ -30,3 +43,60 @@ func TestNonEmptyAndTrimmed(t *testing.T) {
 		t.Errorf("%v != %v", output, expected)
 	}
 }
+
+func TestChunkStrings(t *testing.T) {
+	input := []string{"a", "b", "c", "d", "e"}
+	expected := [][]string{{"a", "b"}, {"c", "d"}, {"e"}}
+	output := chunkStrings(input, 2)
+
+	if !reflect.DeepEqual(output, expected) {
+		t.Errorf("%v != %v", output, expected)
+	}
+
+	if output := chunkStrings(nil, 2); len(output) != 0 {
+		t.Errorf("expected no chunks, got %v", output)
+	}
+}
+
+func TestUniqueStrings(t *testing.T) {
+	input := []string{"a", "b", "a", "c", "b"}
+	expected := []string{"a", "b", "c"}
+	output := uniqueStrings(input)
+
+	if !reflect.DeepEqual(output, expected) {
+		t.Errorf("%v != %v", output, expected)
+	}
+}
+
+func Test_operationTimeout(t *testing.T) {
+	resource := &schema.Resource{Timeouts: resourceTimeouts()}
+	timeoutsType := cty.Object(map[string]cty.Type{
+		schema.TimeoutCreate: cty.String,
+		schema.TimeoutUpdate: cty.String,
+		schema.TimeoutDelete: cty.String,
+	})
+	withTimeouts := func(timeouts cty.Value) cty.Value {
+		return cty.ObjectVal(map[string]cty.Value{schema.TimeoutsConfigKey: timeouts})
+	}
+
+	// without a timeouts block, even the SDK's default falls back
+	d := resource.Data(&terraform.InstanceState{RawConfig: withTimeouts(cty.NullVal(timeoutsType))})
+	assertEquals(t, time.Minute, operationTimeout(d, schema.TimeoutCreate, time.Minute))
+
+	// a timeout explicitly set to the SDK's default is honoured
+	d = resource.Data(&terraform.InstanceState{RawConfig: withTimeouts(cty.ObjectVal(map[string]cty.Value{
+		schema.TimeoutCreate: cty.StringVal("20m"),
+		schema.TimeoutUpdate: cty.NullVal(cty.String),
+		schema.TimeoutDelete: cty.NullVal(cty.String),
+	}))})
+	assertEquals(t, defaultResourceTimeout, operationTimeout(d, schema.TimeoutCreate, time.Minute))
+	assertEquals(t, time.Minute, operationTimeout(d, schema.TimeoutUpdate, time.Minute))
+
+	// on destroy, the timeouts kept in the state apply
+	d = resource.Data(&terraform.InstanceState{RawState: withTimeouts(cty.ObjectVal(map[string]cty.Value{
+		schema.TimeoutCreate: cty.NullVal(cty.String),
+		schema.TimeoutUpdate: cty.NullVal(cty.String),
+		schema.TimeoutDelete: cty.StringVal("20m"),
+	}))})
+	assertEquals(t, defaultResourceTimeout, operationTimeout(d, schema.TimeoutDelete, time.Minute))
+}
@@ -145,4 +145,29 @@ openssl x509 -req -CAcreateserial \
         -days '7300' \
         -passin "pass:$PASSWORD"
 
+openssl pkcs8 -topk8 -v2 'aes-256-cbc' \
+        -in 'client-no-password.key' \
+        -out 'client-pkcs8.key' \
+        -passout "pass:$PASSWORD"
+openssl pkcs8 -topk8 -v2 'des3' -v2prf 'hmacWithSHA1' \
+        -in 'client-no-password.key' \
+        -out 'client-pkcs8-des3.key' \
+        -passout "pass:$PASSWORD"
+# PBES1, which is not supported
+openssl pkcs8 -topk8 -v1 'PBE-SHA1-3DES' \
+        -in 'client-no-password.key' \
+        -out 'client-pkcs8-pbes1.key' \
+        -passout "pass:$PASSWORD"
+
+# legacy algorithms, as golang.org/x/crypto/pkcs12 does not support AES
+openssl pkcs12 -export \
+        -inkey 'client.key' \
+        -in 'client.pem' \
+        -out 'client.p12' \
+        -keypbe 'PBE-SHA1-3DES' \
+        -certpbe 'PBE-SHA1-3DES' \
+        -macalg 'sha1' \
+        -passin "pass:$PASSWORD" \
+        -passout "pass:$PASSWORD"
+
 rm 'client.csr' 'ca.srl'